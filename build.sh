@@ -11,4 +11,8 @@ mkdir -p $TARGET_DIR
 
 go build -o exchange-$VERSION -ldflags "-X main.CommitHash=$GIT_COMMIT -X main.Version=$VERSION" github.com/ankur22/ankur-curve-euro-exchange/cmd/exchange-server
 
-mv exchange-$VERSION $TARGET_DIR
+go build -o fake-fx-provider-$VERSION github.com/ankur22/ankur-curve-euro-exchange/cmd/fake-fx-provider
+
+go build -o exchange-loadtest-$VERSION github.com/ankur22/ankur-curve-euro-exchange/cmd/exchange-loadtest
+
+mv exchange-$VERSION fake-fx-provider-$VERSION exchange-loadtest-$VERSION $TARGET_DIR
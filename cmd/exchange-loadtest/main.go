@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/loadtest"
+	"github.com/gin-gonic/gin"
+)
+
+type paths []string
+
+func (p *paths) String() string {
+	return strings.Join(*p, " ")
+}
+
+func (p *paths) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+func main() {
+	target := flag.String("target", "http://localhost:8080", "URL of the exchange server to load")
+	inProcess := flag.Bool("in-process", false, "load an exchange server started in this process against a fake provider instead of -target")
+	pairs := flag.String("pairs", "EUR/GBP,EUR/USD,GBP/USD", "comma separated pairs to request from /v1/exchange, such as EUR/GBP*3 to request it three times as often")
+	var extra paths
+	flag.Var(&extra, "path", "a path to request as well as -pairs, such as /v2/exchange?from=EUR&to=USD,GBP*2, can be repeated")
+	workers := flag.Int("concurrency", 10, "concurrent clients, or the most requests in flight with -rate")
+	rate := flag.Float64("rate", 0, "requests per second of the open model, the closed model of -concurrency clients is used when 0")
+	duration := flag.Duration("duration", 10*time.Second, "how long to send requests for, unlimited when 0")
+	requests := flag.Int("requests", 0, "how many requests to send, unlimited when 0")
+	seed := flag.Int64("seed", 1, "seed of the choice of requests and of the fake provider")
+	providerLatency := flag.Duration("provider-latency", 0, "latency of the fake provider with -in-process")
+	providerErrorRate := flag.Float64("provider-error-rate", 0, "fraction of fake provider requests that fail with -in-process")
+	validFor := flag.Duration("valid-for", 5*time.Second, "how long rates are valid for, older rates are counted as stale. Also the validity of the -in-process server")
+	flag.Parse()
+
+	mix, err := loadtest.ParseMix(*pairs)
+	if err != nil {
+		log.Fatalf("Pairs: %s\n", err)
+	}
+	for _, p := range extra {
+		r, err := loadtest.ParseRequest(p)
+		if err != nil {
+			log.Fatalf("Path: %s\n", err)
+		}
+		mix = append(mix, r)
+	}
+
+	if *inProcess {
+		// Logging every request would slow the server down and
+		// bury the report
+		gin.DefaultWriter = ioutil.Discard
+		config := app.DefaultConfig()
+		config.DataValidFor = *validFor
+		server, err := loadtest.StartInProcess(config, fakeprovider.Config{Seed: *seed,
+			Latency:   *providerLatency,
+			ErrorRate: *providerErrorRate})
+		if err != nil {
+			log.Fatalf("In process server: %s\n", err)
+		}
+		defer server.Stop()
+		*target = server.URL
+	}
+
+	runner, err := loadtest.CreateNewRunner(loadtest.Config{Target: strings.TrimSuffix(*target, "/"),
+		Mix:      mix,
+		Workers:  *workers,
+		Rate:     *rate,
+		Duration: *duration,
+		Requests: *requests,
+		Seed:     *seed,
+		ValidFor: *validFor}, &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: *workers}})
+	if err != nil {
+		log.Fatalf("Load test: %s\n", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	log.Printf("Loading %s with %d requests in the mix\n", *target, len(mix))
+	runner.Run(ctx).Write(os.Stdout)
+}
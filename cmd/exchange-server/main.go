@@ -1,27 +1,74 @@
 package main
 
 import (
-	"net/http"
-	"time"
+	"flag"
+	"log"
+	"os"
+	"strings"
 
-	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
-	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
 )
 
 func main() {
-	timeout := time.Duration(5 * time.Second)
-	client := &http.Client{
-		Timeout: timeout,
+	providerURL := flag.String("provider-url", "https://api.exchangeratesapi.io", "URL of the exchange rate provider, such as a local fake-fx-provider")
+	apiKeys := flag.String("api-keys", "", "JSON file of API keys, requests are not authenticated when empty")
+	clientRate := flag.Float64("rate-limit", 20, "requests per second per client IP, unlimited when 0")
+	clientBurst := flag.Int("rate-burst", 40, "burst of requests per client IP")
+	globalRate := flag.Float64("global-rate-limit", 500, "requests per second across all clients, unlimited when 0")
+	allowlist := flag.String("rate-allowlist", "127.0.0.0/8,::1/128", "comma separated CIDRs, and unix or unix:uid=<uid> for unix socket clients, that are never rate limited")
+	trustForwardedFor := flag.Bool("trust-forwarded-for", false, "use X-Forwarded-For for the client IP, only when behind a proxy")
+	listen := flag.String("listen", service.DefaultListenSpec, "comma separated listen specs: tcp://host:port, unix:///path?mode=0660 or systemd://[name]")
+	tlsCert := flag.String("tls-cert", "", "PEM certificate file, HTTPS and gRPC over TLS are served when set")
+	tlsKey := flag.String("tls-key", "", "PEM key file of -tls-cert")
+	tlsClientCA := flag.String("tls-client-ca", "", "PEM CA bundle to verify client certificates with")
+	tlsRequireClientCert := flag.Bool("tls-require-client-cert", false, "reject clients without a certificate from -tls-client-ca")
+	tlsMinVersion := flag.String("tls-min-version", "1.2", "minimum TLS version, 1.2 or 1.3")
+	tlsCiphers := flag.String("tls-ciphers", "", "comma separated TLS 1.2 cipher suites, Go's defaults when empty")
+	chaos := flag.Bool("chaos", false, "serve /v1/admin/chaos to inject faults into calls of the provider with an admin key, needs -api-keys, never in production")
+	chaosSeed := flag.Int64("chaos-seed", 1, "seed of the faults injected by probability")
+	redisAddr := flag.String("redis-addr", "", "host:port of a Redis protocol server to share rates with the other replicas, only a local cache when empty")
+	refreshLock := flag.String("refresh-lock", "", "where replicas take the lease to refresh a pair, file:<dir>, redis (needs -redis-addr) or sql:<driver>:<dsn>, every replica refreshes when empty")
+	flag.Parse()
+
+	config := app.DefaultConfig()
+	config.ProviderURL = *providerURL
+	config.APIKeysFile = *apiKeys
+	config.ClientRate = *clientRate
+	config.ClientBurst = *clientBurst
+	config.GlobalRate = *globalRate
+	config.Allowlist = *allowlist
+	config.TrustForwardedFor = *trustForwardedFor
+	config.Listen = strings.Split(*listen, ",")
+	config.Chaos = *chaos
+	config.ChaosSeed = *chaosSeed
+	config.RedisAddr = *redisAddr
+	config.RefreshLock = *refreshLock
+	if *tlsCert != "" {
+		minVersion, err := service.ParseTLSVersion(*tlsMinVersion)
+		if err != nil {
+			log.Fatalf("TLS: %s\n", err)
+		}
+		ciphers, err := service.ParseCipherSuites(*tlsCiphers)
+		if err != nil {
+			log.Fatalf("TLS: %s\n", err)
+		}
+		config.TLS = service.TLSConfig{CertFile: *tlsCert,
+			KeyFile:           *tlsKey,
+			ClientCAFile:      *tlsClientCA,
+			RequireClientCert: *tlsRequireClientCert,
+			MinVersion:        minVersion,
+			CipherSuites:      ciphers}
+	}
+
+	a, err := app.CreateNewApp(config, util.CreateNewClock())
+	if err != nil {
+		log.Fatalf("%s\n", err)
+	}
+
+	if err := a.Start(); err != nil {
+		log.Printf("Server: %s\n", err)
+		os.Exit(1)
 	}
-	ferDao := dao.CreateNewFerAPI("https://api.exchangeratesapi.io", "latest", "2006-01-02", client)
-	dbDao := dao.CreateNewMemstore()
-	clock := util.CreateNewClock()
-	exchangeService := service.CreateNewExchangeRateService(ferDao, dbDao, time.Duration(time.Second*5), clock, time.Duration(time.Second*5))
-	validCurrencies := map[string]bool{"EUR": true, "USD": true, "GBP": true}
-	exchangeEndpoint := v1endpoint.CreateNewV1Exchange(exchangeService, validCurrencies)
-	server := service.CreateNewServer()
-	server.Register("GET /v1/exchange", exchangeEndpoint)
-	server.Start()
 }
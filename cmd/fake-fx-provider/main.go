@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func main() {
+	listen := flag.String("listen", ":8081", "address to serve the fake provider on")
+	seed := flag.Int64("seed", 1, "seed of the rates and of the errors, the same seed gives the same rates")
+	latency := flag.Duration("latency", 0, "delay of every response, such as 250ms")
+	errorRate := flag.Float64("error-rate", 0, "fraction of requests answered with a 500, between 0 and 1")
+	down := flag.Bool("down", false, "start in an outage, every request gets a 503")
+	flag.Parse()
+
+	provider := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: *seed,
+		Latency:   *latency,
+		ErrorRate: *errorRate,
+		Down:      *down}, util.CreateNewClock())
+	srv := &http.Server{Addr: *listen, Handler: provider}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+	go func() {
+		<-ctx.Done()
+		shutdown, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdown)
+	}()
+
+	log.Printf("Fake provider listening on %s, change it with PUT %s?latency=250ms&errorRate=0.1&down=true\n", *listen, fakeprovider.ControlPath)
+	if err := srv.ListenAndServe(); err != http.ErrServerClosed {
+		log.Printf("Fake provider: %s\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,164 @@
+package alert
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/pkg/errors"
+)
+
+// MinSecretLength - Shortest secret accepted to sign webhooks
+const MinSecretLength = 16
+
+// ErrNotFound - Returned when an alert does not exist
+var ErrNotFound = errors.New("alert not found")
+
+// Alert - A rule on the rate of a pair and the webhook to
+//		   notify when it is triggered
+type Alert struct {
+	ID         string
+	From       string
+	To         string
+	Condition  string
+	Threshold  float32
+	WebhookURL string
+	Secret     string
+	CreatedAt  time.Time
+}
+
+// Validate - Check that the alert can be evaluated and delivered
+func (a *Alert) Validate(validCurrencies map[string]bool) error {
+	if err := service.ValidatePair(validCurrencies, a.From, a.To); err != nil {
+		return err
+	}
+
+	switch a.Condition {
+	case api.AlertConditionAbove, api.AlertConditionBelow, api.AlertConditionChange:
+	default:
+		return &service.ValidationError{Reason: fmt.Sprintf("'%s' is not a valid condition", a.Condition)}
+	}
+
+	if a.Threshold <= 0 {
+		return &service.ValidationError{Reason: "threshold must be greater than zero"}
+	}
+
+	u, err := url.Parse(a.WebhookURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return &service.ValidationError{Reason: fmt.Sprintf("'%s' is not a valid webhook URL", a.WebhookURL)}
+	}
+
+	if len(a.Secret) < MinSecretLength {
+		return &service.ValidationError{Reason: fmt.Sprintf("secret must be at least %d characters", MinSecretLength)}
+	}
+
+	return nil
+}
+
+// Store - Interface to store and retrieve alerts
+type Store interface {
+	Create(a *Alert) *Alert
+	Get(id string) (*Alert, error)
+	List() []*Alert
+	Update(id string, a *Alert) (*Alert, error)
+	Delete(id string) error
+}
+
+type memstore struct {
+	mu     sync.RWMutex
+	alerts map[string]*Alert
+}
+
+// CreateNewMemstore - Store alerts in memory
+func CreateNewMemstore() *memstore {
+	return &memstore{alerts: make(map[string]*Alert)}
+}
+
+// Create - Store a new alert, giving it an ID
+func (m *memstore) Create(a *Alert) *Alert {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored := *a
+	stored.ID = newID()
+	m.alerts[stored.ID] = &stored
+
+	created := stored
+	return &created
+}
+
+// Get - Get a copy of the alert
+func (m *memstore) Get(id string) (*Alert, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	a, exists := m.alerts[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	found := *a
+	return &found, nil
+}
+
+// List - Get a copy of every alert, oldest first
+func (m *memstore) List() []*Alert {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	alerts := []*Alert{}
+	for _, a := range m.alerts {
+		found := *a
+		alerts = append(alerts, &found)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].CreatedAt.Equal(alerts[j].CreatedAt) {
+			return alerts[i].ID < alerts[j].ID
+		}
+		return alerts[i].CreatedAt.Before(alerts[j].CreatedAt)
+	})
+	return alerts
+}
+
+// Update - Replace the alert, keeping its ID and creation time
+func (m *memstore) Update(id string, a *Alert) (*Alert, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	existing, exists := m.alerts[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	stored := *a
+	stored.ID = id
+	stored.CreatedAt = existing.CreatedAt
+	m.alerts[id] = &stored
+
+	updated := stored
+	return &updated, nil
+}
+
+// Delete - Delete the alert
+func (m *memstore) Delete(id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, exists := m.alerts[id]; !exists {
+		return ErrNotFound
+	}
+	delete(m.alerts, id)
+	return nil
+}
+
+func newID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
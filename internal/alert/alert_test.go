@@ -0,0 +1,93 @@
+package alert_test
+
+import (
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestAlert(t *testing.T) {
+	t.Run("ensure valid alert passes validation", func(t *testing.T) {
+		// given
+		a := givenValidAlert("http://localhost/hook")
+
+		// when
+		err := a.Validate(givenValidCurrencies())
+
+		// then
+		util.AssertErrorNil(t, err)
+	})
+
+	t.Run("ensure invalid alerts fail validation", func(t *testing.T) {
+		// given
+		invalid := []*alert.Alert{}
+		for _, change := range []func(a *alert.Alert){
+			func(a *alert.Alert) { a.From = "FOO" },
+			func(a *alert.Alert) { a.To = a.From },
+			func(a *alert.Alert) { a.Condition = "sideways" },
+			func(a *alert.Alert) { a.Threshold = 0 },
+			func(a *alert.Alert) { a.WebhookURL = "ftp://localhost/hook" },
+			func(a *alert.Alert) { a.WebhookURL = "/hook" },
+			func(a *alert.Alert) { a.Secret = "short" },
+		} {
+			a := givenValidAlert("http://localhost/hook")
+			change(a)
+			invalid = append(invalid, a)
+		}
+
+		// when
+		for _, a := range invalid {
+			err := a.Validate(givenValidCurrencies())
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+}
+
+func TestMemstore(t *testing.T) {
+	t.Run("ensure alerts can be created, updated and deleted", func(t *testing.T) {
+		// given
+		s := alert.CreateNewMemstore()
+
+		// when
+		created := s.Create(givenValidAlert("http://localhost/hook"))
+		changed := givenValidAlert("http://localhost/other")
+		updated, errUpdate := s.Update(created.ID, changed)
+		found, errGet := s.Get(created.ID)
+		errDelete := s.Delete(created.ID)
+		_, errGone := s.Get(created.ID)
+
+		// then
+		util.AssertFalse(t, created.ID == "")
+		util.AssertErrorNil(t, errUpdate)
+		util.AssertTrue(t, updated.ID == created.ID)
+		util.AssertErrorNil(t, errGet)
+		util.AssertTrue(t, found.WebhookURL == "http://localhost/other")
+		util.AssertErrorNil(t, errDelete)
+		util.AssertTrue(t, errGone == alert.ErrNotFound)
+		util.AssertTrue(t, len(s.List()) == 0)
+	})
+
+	t.Run("ensure missing alerts are not found", func(t *testing.T) {
+		// given
+		s := alert.CreateNewMemstore()
+
+		// when
+		_, errUpdate := s.Update("missing", givenValidAlert("http://localhost/hook"))
+		errDelete := s.Delete("missing")
+
+		// then
+		util.AssertTrue(t, errUpdate == alert.ErrNotFound)
+		util.AssertTrue(t, errDelete == alert.ErrNotFound)
+	})
+}
+
+func givenValidAlert(webhookURL string) *alert.Alert {
+	return &alert.Alert{From: "GBP", To: "EUR", Condition: "above", Threshold: 1.15, WebhookURL: webhookURL, Secret: "0123456789abcdef"}
+}
+
+func givenValidCurrencies() map[string]bool {
+	return map[string]bool{"EUR": true, "USD": true, "GBP": true}
+}
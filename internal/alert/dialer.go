@@ -0,0 +1,84 @@
+package alert
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// blockedNets - Ranges that are not loopback, private or link-local
+//				 to the net package but are still internal
+var blockedNets = mustParseCIDRs(
+	"0.0.0.0/8",     // this network
+	"100.64.0.0/10", // carrier-grade NAT, often used inside clusters
+	"192.0.0.0/24",  // IETF protocol assignments
+	"198.18.0.0/15", // benchmarking
+	"64:ff9b::/96",  // NAT64 of IPv4 addresses
+)
+
+// CreateNewWebhookClient - HTTP client for webhooks that refuses to
+//							connect to loopback, private, link-local
+//							and other internal addresses, so that an
+//							alert cannot make the server call its own
+//							network. The address is checked as it is
+//							connected to, after DNS, so that a host
+//							resolving to a public address when it is
+//							validated and an internal one later is
+//							still refused. Redirects are checked the
+//							same way and no proxy is used.
+func CreateNewWebhookClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: checkWebhookAddress}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			Proxy:               nil,
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: timeout,
+			MaxIdleConnsPerHost: 2,
+		},
+	}
+}
+
+// checkWebhookAddress - Control of the dialer of webhooks, called
+//						 with the resolved address of every
+//						 connection
+func checkWebhookAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return errors.Wrap(err, "Invalid webhook address")
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || isInternal(ip) {
+		return errors.New(fmt.Sprintf("Webhooks cannot be delivered to the internal address %s", host))
+	}
+	return nil
+}
+
+func isInternal(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return true
+	}
+	for _, n := range blockedNets {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := []*net.IPNet{}
+	for _, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets = append(nets, n)
+	}
+	return nets
+}
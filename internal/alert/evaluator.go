@@ -0,0 +1,118 @@
+package alert
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+// changeWindow - The change condition compares with the rate
+//				  from this long ago
+const changeWindow = 24 * time.Hour
+
+type sample struct {
+	oneUnit float32
+	at      time.Time
+}
+
+// Trigger - An alert whose condition has just become true
+type Trigger struct {
+	Alert    *Alert
+	Update   pubsub.RateUpdate
+	Previous float32
+}
+
+// evaluator - Keeps the rate history of every pair and which
+//			   alerts are currently true, so that an alert is
+//			   only triggered when its condition becomes true
+//			   rather than on every stored rate
+type evaluator struct {
+	mu      sync.Mutex
+	history map[string][]sample
+	active  map[string]bool
+}
+
+func createNewEvaluator() *evaluator {
+	return &evaluator{history: make(map[string][]sample), active: make(map[string]bool)}
+}
+
+// evaluate - Record the update and return the alerts that it
+//			  triggers
+func (e *evaluator) evaluate(alerts []*Alert, u pubsub.RateUpdate) []*Trigger {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := u.From + u.To
+	previous, reference := e.record(key, u)
+
+	triggers := []*Trigger{}
+	for _, a := range alerts {
+		if a.From != u.From || a.To != u.To {
+			continue
+		}
+
+		isTrue := e.isTrue(a, u.OneUnit, reference)
+		wasTrue := e.active[a.ID]
+		e.active[a.ID] = isTrue
+
+		if isTrue && !wasTrue {
+			triggers = append(triggers, &Trigger{Alert: a, Update: u, Previous: previous})
+		}
+	}
+
+	return triggers
+}
+
+// forget - Drop the state of a deleted or changed alert
+func (e *evaluator) forget(id string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	delete(e.active, id)
+}
+
+// record - Add the update to the pair's history. Returns the
+//			previous rate and the rate from changeWindow ago,
+//			or the oldest known rate if there is no history
+//			that old yet. Both are zero for the first update.
+func (e *evaluator) record(key string, u pubsub.RateUpdate) (float32, float32) {
+	history := e.history[key]
+
+	var previous, reference float32
+	if len(history) > 0 {
+		previous = history[len(history)-1].oneUnit
+		reference = history[0].oneUnit
+	}
+
+	// Keep only the newest sample older than the window
+	// as the reference
+	cutoff := u.DataDateTime.Add(-changeWindow)
+	for len(history) > 1 && !history[1].at.After(cutoff) {
+		history = history[1:]
+	}
+	if len(history) > 0 && !history[0].at.After(cutoff) {
+		reference = history[0].oneUnit
+	}
+
+	e.history[key] = append(history, sample{oneUnit: u.OneUnit, at: u.DataDateTime})
+	return previous, reference
+}
+
+func (e *evaluator) isTrue(a *Alert, oneUnit, reference float32) bool {
+	switch a.Condition {
+	case api.AlertConditionAbove:
+		return oneUnit > a.Threshold
+	case api.AlertConditionBelow:
+		return oneUnit < a.Threshold
+	case api.AlertConditionChange:
+		if reference == 0 {
+			return false
+		}
+		change := math.Abs(float64(oneUnit-reference)) / float64(reference) * 100
+		return change > float64(a.Threshold)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+// AlertService - Manages alerts and triggers them as new rates
+//				  are stored
+type AlertService interface {
+	Create(a *Alert) (*Alert, error)
+	Get(id string) (*Alert, error)
+	List() []*Alert
+	Update(id string, a *Alert) (*Alert, error)
+	Delete(id string) error
+	Deliveries(id string) ([]*Delivery, error)
+}
+
+type localAlertService struct {
+	store           Store
+	deliverer       *webhookDeliverer
+	evaluator       *evaluator
+	validCurrencies map[string]bool
+	clock           util.Clock
+}
+
+// CreateNewAlertService - Use this to create the alerts service.
+//						   Run must be started for alerts to be
+//						   evaluated.
+func CreateNewAlertService(store Store, deliverer *webhookDeliverer, validCurrencies map[string]bool, clock util.Clock) *localAlertService {
+	return &localAlertService{store: store,
+		deliverer:       deliverer,
+		evaluator:       createNewEvaluator(),
+		validCurrencies: validCurrencies,
+		clock:           clock}
+}
+
+// Create - Validate and store a new alert
+func (l *localAlertService) Create(a *Alert) (*Alert, error) {
+	if err := a.Validate(l.validCurrencies); err != nil {
+		return nil, err
+	}
+	a.CreatedAt = l.clock.Now()
+	return l.store.Create(a), nil
+}
+
+// Get - Get an alert
+func (l *localAlertService) Get(id string) (*Alert, error) {
+	return l.store.Get(id)
+}
+
+// List - Get every alert
+func (l *localAlertService) List() []*Alert {
+	return l.store.List()
+}
+
+// Update - Validate and replace an alert. Its condition is
+//			evaluated afresh from the next stored rate.
+func (l *localAlertService) Update(id string, a *Alert) (*Alert, error) {
+	if err := a.Validate(l.validCurrencies); err != nil {
+		return nil, err
+	}
+
+	updated, err := l.store.Update(id, a)
+	if err != nil {
+		return nil, err
+	}
+	l.evaluator.forget(id)
+	return updated, nil
+}
+
+// Delete - Delete an alert and its delivery log
+func (l *localAlertService) Delete(id string) error {
+	if err := l.store.Delete(id); err != nil {
+		return err
+	}
+	l.evaluator.forget(id)
+	l.deliverer.Forget(id)
+	return nil
+}
+
+// Deliveries - Get the delivery log of an alert
+func (l *localAlertService) Deliveries(id string) ([]*Delivery, error) {
+	if _, err := l.store.Get(id); err != nil {
+		return nil, err
+	}
+	return l.deliverer.Deliveries(id), nil
+}
+
+// Run - Evaluate every alert against each update until the
+//		 channel is closed. Triggered alerts are delivered in
+//		 the background so a slow webhook does not hold up
+//		 evaluation.
+func (l *localAlertService) Run(updates <-chan pubsub.RateUpdate) {
+	for u := range updates {
+		for _, t := range l.Evaluate(u) {
+			go l.deliverer.Deliver(t.Alert, toEvent(t))
+		}
+	}
+}
+
+// Evaluate - Get the alerts triggered by an update
+func (l *localAlertService) Evaluate(u pubsub.RateUpdate) []*Trigger {
+	return l.evaluator.evaluate(l.store.List(), u)
+}
+
+func toEvent(t *Trigger) *api.AlertEvent {
+	return &api.AlertEvent{AlertID: t.Alert.ID,
+		From:         t.Alert.From,
+		To:           t.Alert.To,
+		Condition:    t.Alert.Condition,
+		Threshold:    t.Alert.Threshold,
+		SingleUnit:   t.Update.OneUnit,
+		PreviousUnit: t.Previous,
+		DataDateTime: t.Update.DataDateTime.Format(time.RFC3339Nano)}
+}
@@ -0,0 +1,144 @@
+package alert_test
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestAlertService(t *testing.T) {
+	t.Run("ensure above alert triggers only when the rate crosses the threshold", func(t *testing.T) {
+		// given
+		s := givenAlertService()
+		a, _ := s.Create(givenValidAlert("http://localhost/hook"))
+		now := time.Now()
+
+		// when
+		below := s.Evaluate(givenUpdate("GBP", "EUR", 1.14, now))
+		crossed := s.Evaluate(givenUpdate("GBP", "EUR", 1.16, now.Add(time.Minute)))
+		stillAbove := s.Evaluate(givenUpdate("GBP", "EUR", 1.17, now.Add(time.Minute*2)))
+		s.Evaluate(givenUpdate("GBP", "EUR", 1.10, now.Add(time.Minute*3)))
+		crossedAgain := s.Evaluate(givenUpdate("GBP", "EUR", 1.20, now.Add(time.Minute*4)))
+
+		// then
+		util.AssertTrue(t, len(below) == 0)
+		util.AssertTrue(t, len(crossed) == 1)
+		util.AssertTrue(t, crossed[0].Alert.ID == a.ID)
+		util.AssertEquals(t, 1.14, crossed[0].Previous)
+		util.AssertTrue(t, len(stillAbove) == 0)
+		util.AssertTrue(t, len(crossedAgain) == 1)
+	})
+
+	t.Run("ensure alerts only trigger for their pair", func(t *testing.T) {
+		// given
+		s := givenAlertService()
+		s.Create(givenValidAlert("http://localhost/hook"))
+
+		// when
+		triggers := s.Evaluate(givenUpdate("EUR", "GBP", 2, time.Now()))
+
+		// then
+		util.AssertTrue(t, len(triggers) == 0)
+	})
+
+	t.Run("ensure change alert compares with the rate a day before", func(t *testing.T) {
+		// given
+		s := givenAlertService()
+		a := givenValidAlert("http://localhost/hook")
+		a.Condition = "change"
+		a.Threshold = 1
+		s.Create(a)
+		now := time.Now()
+
+		// when
+		s.Evaluate(givenUpdate("GBP", "EUR", 1.000, now.Add(-time.Hour*30)))
+		s.Evaluate(givenUpdate("GBP", "EUR", 1.100, now.Add(-time.Hour*25)))
+		small := s.Evaluate(givenUpdate("GBP", "EUR", 1.105, now))
+		large := s.Evaluate(givenUpdate("GBP", "EUR", 1.120, now.Add(time.Minute)))
+
+		// then
+		util.AssertTrue(t, len(small) == 0)
+		util.AssertTrue(t, len(large) == 1)
+	})
+
+	t.Run("ensure triggered alerts are delivered from published rates", func(t *testing.T) {
+		// given
+		receiver := givenReceiver(0)
+		defer receiver.Close()
+		s := givenAlertService()
+		a, _ := s.Create(givenValidAlert(receiver.URL))
+		broker := pubsub.CreateNewBroker()
+		sub := broker.Subscribe(nil, 10)
+		go s.Run(sub.C)
+
+		// when
+		broker.Publish(givenUpdate("GBP", "EUR", 1.2, time.Now()))
+
+		// then
+		for i := 0; i < 100 && len(receiver.requests()) == 0; i++ {
+			time.Sleep(time.Millisecond * 10)
+		}
+		broker.Unsubscribe(sub)
+		util.AssertTrue(t, len(receiver.requests()) == 1)
+		deliveries, err := s.Deliveries(a.ID)
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(deliveries) == 1)
+	})
+
+	t.Run("ensure invalid alerts are rejected", func(t *testing.T) {
+		// given
+		s := givenAlertService()
+		a := givenValidAlert("http://localhost/hook")
+		a.Threshold = -1
+
+		// when
+		_, err := s.Create(a)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertTrue(t, len(s.List()) == 0)
+	})
+
+	t.Run("ensure alerts are created at the time of the clock", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		s := givenAlertServiceAt(clock)
+		clock.Advance(time.Hour)
+
+		// when
+		a, err := s.Create(givenValidAlert("http://localhost/hook"))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, a.CreatedAt.Equal(givenNow().Add(time.Hour)))
+	})
+}
+
+func givenAlertService() alertService {
+	return givenAlertServiceAt(util.CreateNewFakeClock(givenNow()))
+}
+
+// givenAlertServiceAt - givenAlertService that tells the time and
+//						 waits for it to pass with clock
+func givenAlertServiceAt(clock util.Clock) alertService {
+	deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 0, time.Millisecond, clock)
+	return alert.CreateNewAlertService(alert.CreateNewMemstore(), deliverer, givenValidCurrencies(), clock)
+}
+
+func givenNow() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
+
+type alertService interface {
+	alert.AlertService
+	Evaluate(u pubsub.RateUpdate) []*alert.Trigger
+	Run(updates <-chan pubsub.RateUpdate)
+}
+
+func givenUpdate(from, to string, oneUnit float32, at time.Time) pubsub.RateUpdate {
+	return pubsub.RateUpdate{From: from, To: to, OneUnit: oneUnit, DataDateTime: at}
+}
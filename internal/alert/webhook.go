@@ -0,0 +1,155 @@
+package alert
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+// Headers sent with every webhook
+const (
+	SignatureHeader = "X-Exchange-Signature"
+	TimestampHeader = "X-Exchange-Timestamp"
+	DeliveryHeader  = "X-Exchange-Delivery"
+)
+
+// maxDeliveriesPerAlert - Deliveries kept in the log of an alert
+const maxDeliveriesPerAlert = 100
+
+// Delivery - A single attempt to deliver an alert to its webhook
+type Delivery struct {
+	ID          string
+	AlertID     string
+	Attempt     int
+	StatusCode  int
+	Error       string
+	Success     bool
+	AttemptedAt time.Time
+}
+
+// Sign - HMAC-SHA256 signature of a webhook body. Receivers
+//		  should compute it with their secret and the value of
+//		  the timestamp header and compare it with the value of
+//		  the signature header.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+type webhookDeliverer struct {
+	client  dao.HTTPClient
+	retries int
+	backoff time.Duration
+	clock   util.Clock
+
+	mu         sync.RWMutex
+	deliveries map[string][]*Delivery
+}
+
+// CreateNewWebhookDeliverer - Delivers alerts to their webhook.
+//							   A failed delivery is retried up to
+//							   retries times, waiting backoff
+//							   before the first retry and double
+//							   that before each following one.
+func CreateNewWebhookDeliverer(client dao.HTTPClient, retries int, backoff time.Duration, clock util.Clock) *webhookDeliverer {
+	return &webhookDeliverer{client: client, retries: retries, backoff: backoff, clock: clock, deliveries: make(map[string][]*Delivery)}
+}
+
+// Deliver - POST the event to the alert's webhook, retrying until
+//			 it succeeds or the retries are exhausted. Every
+//			 attempt is added to the delivery log.
+func (w *webhookDeliverer) Deliver(a *Alert, event *api.AlertEvent) bool {
+	body, _ := json.Marshal(event)
+	id := newID()
+	wait := w.backoff
+
+	for attempt := 1; attempt <= w.retries+1; attempt++ {
+		if attempt > 1 {
+			<-w.clock.After(wait)
+			wait *= 2
+		}
+
+		d := w.attempt(a, id, attempt, body)
+		w.log(d)
+		if d.Success {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Deliveries - The logged deliveries of an alert, oldest first
+func (w *webhookDeliverer) Deliveries(alertID string) []*Delivery {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	deliveries := []*Delivery{}
+	for _, d := range w.deliveries[alertID] {
+		found := *d
+		deliveries = append(deliveries, &found)
+	}
+	return deliveries
+}
+
+// Forget - Drop the delivery log of a deleted alert
+func (w *webhookDeliverer) Forget(alertID string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	delete(w.deliveries, alertID)
+}
+
+func (w *webhookDeliverer) attempt(a *Alert, id string, attempt int, body []byte) *Delivery {
+	d := &Delivery{ID: id, AlertID: a.ID, Attempt: attempt, AttemptedAt: w.clock.Now()}
+
+	req, err := http.NewRequest("POST", a.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+
+	timestamp := strconv.FormatInt(d.AttemptedAt.Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Sign(a.Secret, timestamp, body))
+	req.Header.Set(DeliveryHeader, id)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		d.Error = err.Error()
+		return d
+	}
+	resp.Body.Close()
+
+	d.StatusCode = resp.StatusCode
+	d.Success = resp.StatusCode >= 200 && resp.StatusCode < 300
+	if !d.Success {
+		d.Error = fmt.Sprintf("Received %d from webhook", resp.StatusCode)
+	}
+	return d
+}
+
+func (w *webhookDeliverer) log(d *Delivery) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	deliveries := append(w.deliveries[d.AlertID], d)
+	if len(deliveries) > maxDeliveriesPerAlert {
+		deliveries = deliveries[len(deliveries)-maxDeliveriesPerAlert:]
+	}
+	w.deliveries[d.AlertID] = deliveries
+}
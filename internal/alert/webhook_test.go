@@ -0,0 +1,179 @@
+package alert_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+func TestWebhookDeliverer(t *testing.T) {
+	t.Run("ensure event is delivered with a valid signature", func(t *testing.T) {
+		// given
+		receiver := givenReceiver(0)
+		defer receiver.Close()
+		a := givenValidAlert(receiver.URL)
+		a.ID = "alert"
+		deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 0, time.Millisecond, util.CreateNewClock())
+
+		// when
+		delivered := deliverer.Deliver(a, &api.AlertEvent{AlertID: a.ID, SingleUnit: 1.2})
+
+		// then
+		util.AssertTrue(t, delivered)
+		req := receiver.requests()[0]
+		util.AssertTrue(t, req.signature == alert.Sign(a.Secret, req.timestamp, req.body))
+		event := api.AlertEvent{}
+		util.AssertErrorNil(t, json.Unmarshal(req.body, &event))
+		util.AssertEquals(t, 1.2, event.SingleUnit)
+	})
+
+	t.Run("ensure failed deliveries are retried and logged", func(t *testing.T) {
+		// given
+		receiver := givenReceiver(2)
+		defer receiver.Close()
+		a := givenValidAlert(receiver.URL)
+		a.ID = "alert"
+		deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 3, time.Millisecond, util.CreateNewClock())
+
+		// when
+		delivered := deliverer.Deliver(a, &api.AlertEvent{AlertID: a.ID})
+
+		// then
+		util.AssertTrue(t, delivered)
+		deliveries := deliverer.Deliveries(a.ID)
+		util.AssertTrue(t, len(deliveries) == 3)
+		util.AssertFalse(t, deliveries[0].Success)
+		util.AssertTrue(t, deliveries[0].StatusCode == 500)
+		util.AssertTrue(t, deliveries[2].Success)
+		util.AssertTrue(t, deliveries[2].Attempt == 3)
+		util.AssertTrue(t, deliveries[0].ID == deliveries[2].ID)
+	})
+
+	t.Run("ensure delivery gives up once retries are exhausted", func(t *testing.T) {
+		// given
+		receiver := givenReceiver(10)
+		defer receiver.Close()
+		a := givenValidAlert(receiver.URL)
+		a.ID = "alert"
+		deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 1, time.Millisecond, util.CreateNewClock())
+
+		// when
+		delivered := deliverer.Deliver(a, &api.AlertEvent{AlertID: a.ID})
+
+		// then
+		util.AssertFalse(t, delivered)
+		util.AssertTrue(t, len(receiver.requests()) == 2)
+		util.AssertTrue(t, len(deliverer.Deliveries(a.ID)) == 2)
+	})
+
+	t.Run("ensure retries back off on the clock", func(t *testing.T) {
+		// given
+		receiver := givenReceiver(10)
+		defer receiver.Close()
+		a := givenValidAlert(receiver.URL)
+		a.ID = "alert"
+		clock := util.CreateNewFakeClock(givenNow())
+		deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 2, time.Minute, clock)
+		delivered := make(chan bool, 1)
+
+		// when
+		go func() {
+			delivered <- deliverer.Deliver(a, &api.AlertEvent{AlertID: a.ID})
+		}()
+		clock.BlockUntil(1)
+		clock.Advance(time.Minute)
+		clock.BlockUntil(1)
+		clock.Advance(2 * time.Minute)
+
+		// then
+		util.AssertFalse(t, <-delivered)
+		deliveries := deliverer.Deliveries(a.ID)
+		util.AssertTrue(t, len(deliveries) == 3)
+		util.AssertTrue(t, deliveries[0].AttemptedAt.Equal(givenNow()))
+		util.AssertTrue(t, deliveries[1].AttemptedAt.Equal(givenNow().Add(time.Minute)))
+		util.AssertTrue(t, deliveries[2].AttemptedAt.Equal(givenNow().Add(3*time.Minute)))
+	})
+}
+
+func TestWebhookClient(t *testing.T) {
+	t.Run("ensure webhooks are not delivered to internal addresses", func(t *testing.T) {
+		receiver := givenReceiver(0)
+		defer receiver.Close()
+		_, port, _ := net.SplitHostPort(receiver.Listener.Addr().String())
+
+		for _, url := range []string{
+			receiver.URL,
+			"http://localhost:" + port,
+			"http://169.254.169.254/latest/meta-data",
+			"http://10.0.0.1/",
+			"http://192.168.1.1/",
+			"http://100.64.0.1/",
+			"http://[::1]:" + port,
+		} {
+			// given
+			a := givenValidAlert(url)
+			a.ID = "alert"
+			deliverer := alert.CreateNewWebhookDeliverer(alert.CreateNewWebhookClient(time.Second), 0, time.Millisecond, util.CreateNewClock())
+
+			// when
+			delivered := deliverer.Deliver(a, &api.AlertEvent{AlertID: a.ID})
+
+			// then
+			util.AssertFalse(t, delivered)
+			if !strings.Contains(deliverer.Deliveries(a.ID)[0].Error, "internal address") {
+				t.Fatalf("expected '%s' to be refused as internal but actual is '%s'", url, deliverer.Deliveries(a.ID)[0].Error)
+			}
+		}
+		util.AssertTrue(t, len(receiver.requests()) == 0)
+	})
+}
+
+type receivedRequest struct {
+	signature string
+	timestamp string
+	body      []byte
+}
+
+type receiver struct {
+	*httptest.Server
+	mu       sync.Mutex
+	failures int
+	received []receivedRequest
+}
+
+func (r *receiver) requests() []receivedRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]receivedRequest{}, r.received...)
+}
+
+// givenReceiver - A local webhook that fails the first failures
+//				   requests with a 500
+func givenReceiver(failures int) *receiver {
+	r := &receiver{failures: failures}
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.received = append(r.received, receivedRequest{
+			signature: req.Header.Get(alert.SignatureHeader),
+			timestamp: req.Header.Get(alert.TimestampHeader),
+			body:      body,
+		})
+		if len(r.received) <= r.failures {
+			w.WriteHeader(500)
+		}
+	}))
+	return r
+}
@@ -0,0 +1,370 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/grpcendpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/lease"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v2endpoint"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// Config - How the exchange server is composed, which is what
+//			`cmd/exchange-server` reads from its flags. TLS is
+//			served when TLS.CertFile is set and gRPC is not
+//			served when GRPCListen is empty. Chaos wraps the
+//			provider so that faults can be injected through
+//			`/v1/admin/chaos`, which is only served then and
+//			needs APIKeysFile for its admin scope.
+//			Rates are shared with every replica through the
+//			Redis protocol server at RedisAddr when it is set.
+//			RefreshLock is where replicas take the lease to
+//			refresh a pair, `file:<dir>`, `redis` or
+//			`sql:<driver>:<dsn>`, and every replica refreshes
+//			when it is empty.
+type Config struct {
+	ProviderURL       string
+	ProviderTimeout   time.Duration
+	DataValidFor      time.Duration
+	ValidCurrencies   map[string]bool
+	APIKeysFile       string
+	ClientRate        float64
+	ClientBurst       int
+	GlobalRate        float64
+	Allowlist         string
+	TrustForwardedFor bool
+	Listen            []string
+	GRPCListen        string
+	TLS               service.TLSConfig
+	Chaos             bool
+	ChaosSeed         int64
+	RedisAddr         string
+	RefreshLock       string
+}
+
+// webhookTimeout - How long a webhook is given to answer an alert
+const webhookTimeout = 5 * time.Second
+
+// redisTimeout - How long the shared cache is given for a command
+//				  before the local cache is used instead
+const redisTimeout = 250 * time.Millisecond
+
+// sharedRateWindows - How many times DataValidFor a rate is kept in
+//					   the shared cache. Expired rates are still
+//					   served while a replica refreshes them.
+const sharedRateWindows = 10
+
+// sqlTimeout - How long the database of SQL leases is given for a
+//				query
+const sqlTimeout = time.Second
+
+// DefaultConfig - The config of `cmd/exchange-server` without flags
+func DefaultConfig() Config {
+	return Config{ProviderURL: "https://api.exchangeratesapi.io",
+		ProviderTimeout: 5 * time.Second,
+		DataValidFor:    5 * time.Second,
+		ValidCurrencies: map[string]bool{"EUR": true, "USD": true, "GBP": true},
+		ClientRate:      20,
+		ClientBurst:     40,
+		GlobalRate:      500,
+		Allowlist:       "127.0.0.0/8,::1/128",
+		Listen:          []string{service.DefaultListenSpec},
+		GRPCListen:      ":9090"}
+}
+
+type server interface {
+	Run(ctx context.Context) error
+	Ready() <-chan struct{}
+	Addrs() []net.Addr
+}
+
+type alertRunner interface {
+	Run(updates <-chan pubsub.RateUpdate)
+}
+
+type stopper interface {
+	Stop()
+}
+
+type app struct {
+	config      Config
+	server      server
+	grpcServer  *grpc.Server
+	grpcStreams stopper
+	alerts      alertRunner
+	broker      *pubsub.Broker
+	alertEvents *pubsub.Subscription
+	sharedCache io.Closer
+	ready       chan struct{}
+	readyOnce   sync.Once
+}
+
+// CreateNewApp - Compose the network DAO, store, service, endpoints
+//				  and servers from the config
+func CreateNewApp(config Config, clock util.Clock) (*app, error) {
+	if config.Chaos && config.APIKeysFile == "" {
+		// Anyone could break the calls of the provider otherwise
+		return nil, errors.New("Chaos needs API keys so that only admin keys can inject faults")
+	}
+
+	client := &http.Client{
+		Timeout: config.ProviderTimeout,
+	}
+	var ferDao dao.NetworkDAO = dao.CreateNewFerAPI(config.ProviderURL, "latest", "2006-01-02", client)
+	var chaos dao.ChaosController
+	if config.Chaos {
+		log.Println("Chaos is on, faults can be injected into calls of the provider through /v1/admin/chaos")
+		chaosDao := dao.CreateNewChaosNetwork(ferDao, clock, config.ChaosSeed)
+		ferDao, chaos = chaosDao, chaosDao
+	}
+	broker := pubsub.CreateNewBroker()
+	var store dao.DatabaseDAO = dao.CreateNewMemstore()
+	var sharedCache io.Closer
+	if config.RedisAddr != "" {
+		tieredConfig := dao.DefaultTieredConfig
+		tieredConfig.RateTTL = sharedRateWindows * config.DataValidFor
+		tiered := dao.CreateNewTieredStore(store, resp.CreateNewClient(config.RedisAddr, redisTimeout), tieredConfig, clock)
+		store, sharedCache = tiered, tiered
+	}
+	dbDao := dao.CreateNewPublishingStore(store, broker)
+	exchangeService := service.CreateNewExchangeRateService(ferDao, dbDao, config.DataValidFor, clock, config.ProviderTimeout)
+	if config.RefreshLock != "" {
+		locker, err := createLocker(config.RefreshLock, config.RedisAddr)
+		if err != nil {
+			return nil, errors.Wrap(err, "Refresh lock")
+		}
+		exchangeService.UseLocker(locker, service.DefaultLeaseConfig)
+	}
+	validCurrencies := config.ValidCurrencies
+	exchangeEndpoint := v1endpoint.CreateNewV1Exchange(exchangeService, validCurrencies, clock)
+	exchangeBatchEndpoint := v1endpoint.CreateNewV1ExchangeBatch(exchangeService, validCurrencies)
+	ratesMatrixEndpoint := v1endpoint.CreateNewV1RatesMatrix(exchangeService, validCurrencies)
+	streamEndpoint := v1endpoint.CreateNewV1Stream(exchangeService, broker, validCurrencies, time.Duration(time.Second*15), 1000)
+	alertDeliverer := alert.CreateNewWebhookDeliverer(alert.CreateNewWebhookClient(webhookTimeout), 5, time.Duration(time.Second), clock)
+	alertService := alert.CreateNewAlertService(alert.CreateNewMemstore(), alertDeliverer, validCurrencies, clock)
+	alertsEndpoint := v1endpoint.CreateNewV1Alerts(alertService)
+	exchangeV2Endpoint := v2endpoint.CreateNewV2Exchange(exchangeService, validCurrencies, clock)
+	server := service.CreateNewServer()
+	server.ListenOn(config.Listen...)
+	allowed, allowedPeers, err := service.ParseAllowlist(config.Allowlist)
+	if err != nil {
+		return nil, errors.Wrap(err, "Rate limit")
+	}
+	limiter := server.LimitRate(service.RateLimitConfig{
+		Global:    service.Limit{Rate: config.GlobalRate, Burst: int(config.GlobalRate * 2)},
+		PerClient: service.Limit{Rate: config.ClientRate, Burst: config.ClientBurst},
+		Routes: []service.RouteLimit{
+			{Method: "POST", Prefix: "/v1/exchange/batch", Limit: service.Limit{Rate: config.ClientRate / 5, Burst: config.ClientBurst / 5}},
+			{Method: "GET", Prefix: "/v1/rates/matrix", Limit: service.Limit{Rate: config.ClientRate / 5, Burst: config.ClientBurst / 5}},
+			{Method: "GET", Prefix: "/v2/exchange", Limit: service.Limit{Rate: config.ClientRate / 5, Burst: config.ClientBurst / 5}},
+		},
+		Allowlist:         allowed,
+		UnixAllowlist:     allowedPeers,
+		TrustForwardedFor: config.TrustForwardedFor,
+	}, clock)
+	server.Register(exchangeEndpoint)
+	server.Register(exchangeBatchEndpoint)
+	server.Register(ratesMatrixEndpoint)
+	server.Register(streamEndpoint)
+	server.Register(alertsEndpoint)
+	server.Register(exchangeV2Endpoint)
+	if chaos != nil {
+		server.Register(v1endpoint.CreateNewV1Chaos(chaos))
+	}
+
+	var authorizer grpcendpoint.Authorizer
+	if config.APIKeysFile != "" {
+		keys, err := auth.LoadKeys(config.APIKeysFile)
+		if err != nil {
+			return nil, errors.Wrap(err, "API keys")
+		}
+		rules := []auth.Rule{
+			{Prefix: "/openapi.json", Scope: ""},
+			{Prefix: "/v1/alerts", Scope: auth.ScopeManageAlerts},
+			{Prefix: "/v1/admin", Scope: auth.ScopeAdmin},
+		}
+		authenticator := auth.CreateNewAuthenticator(auth.CreateNewMemKeyStore(keys), rules, auth.ScopeReadRates, clock)
+		server.Use(authenticator.Middleware())
+		server.Register(v1endpoint.CreateNewV1Usage(authenticator))
+		authorizer = authenticator
+	}
+
+	grpcOptions := grpcendpoint.CreateNewGuard(limiter, authorizer).Options()
+	if config.TLS.CertFile != "" {
+		tlsConfig, err := config.TLS.ServerConfig()
+		if err != nil {
+			return nil, errors.Wrap(err, "TLS")
+		}
+		server.UseTLS(tlsConfig)
+		grpcOptions = append(grpcOptions, grpc.Creds(credentials.NewTLS(tlsConfig)))
+	}
+
+	grpcServer := grpc.NewServer(grpcOptions...)
+	grpcExchange := grpcendpoint.CreateNewGrpcExchange(exchangeService, broker, validCurrencies, clock, time.Duration(time.Second))
+	grpcExchange.Register(grpcServer)
+
+	return &app{config: config,
+		server:      server,
+		grpcServer:  grpcServer,
+		grpcStreams: grpcExchange,
+		alerts:      alertService,
+		broker:      broker,
+		alertEvents: broker.SubscribeBlocking(nil, 1000),
+		sharedCache: sharedCache,
+		ready:       make(chan struct{})}, nil
+}
+
+// Start - Run until SIGINT or SIGTERM is received
+func (a *app) Start() error {
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return a.Run(ctx)
+}
+
+// Run - Serve HTTP, and gRPC when configured, until ctx is done.
+//		 Returns an error if either cannot listen or HTTP stops
+//		 serving.
+func (a *app) Run(ctx context.Context) error {
+	// Nothing waiting on Ready is left waiting when Run cannot
+	// listen
+	defer a.markReady()
+
+	if a.config.GRPCListen != "" {
+		lis, err := net.Listen("tcp", a.config.GRPCListen)
+		if err != nil {
+			return errors.Wrap(err, "gRPC listen")
+		}
+		log.Printf("gRPC listening on %s\n", lis.Addr())
+		go a.grpcServer.Serve(lis)
+	}
+	defer a.stopGRPC(service.ShutdownTimeout)
+	if a.sharedCache != nil {
+		defer a.sharedCache.Close()
+	}
+
+	// Alerts must see every update so their subscription blocks
+	// rather than drops, and ends when HTTP stops serving
+	defer a.broker.Unsubscribe(a.alertEvents)
+	go a.alerts.Run(a.alertEvents.C)
+
+	// Run of the server closes its Ready whatever happens
+	go func() {
+		<-a.server.Ready()
+		a.markReady()
+	}()
+	return a.server.Run(ctx)
+}
+
+// stopGRPC - End the open streams, which would otherwise never
+//			  finish, then stop gRPC gracefully. Calls still
+//			  open after timeout are cut off.
+func (a *app) stopGRPC(timeout time.Duration) {
+	a.grpcStreams.Stop()
+
+	stopped := make(chan struct{})
+	go func() {
+		a.grpcServer.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-time.After(timeout):
+		a.grpcServer.Stop()
+	}
+}
+
+// Ready - Closed once HTTP is listening, or once Run returns. Addrs
+//		   is empty when it could not listen.
+func (a *app) Ready() <-chan struct{} {
+	return a.ready
+}
+
+func (a *app) markReady() {
+	a.readyOnce.Do(func() { close(a.ready) })
+}
+
+// Addrs - The addresses HTTP is listening on
+func (a *app) Addrs() []net.Addr {
+	return a.server.Addrs()
+}
+
+// createLocker - The locker of a RefreshLock
+func createLocker(spec string, redisAddr string) (lease.Locker, error) {
+	switch {
+	case strings.HasPrefix(spec, "file:"):
+		return lease.CreateNewFileLocker(strings.TrimPrefix(spec, "file:"))
+	case spec == "redis":
+		if redisAddr == "" {
+			return nil, errors.New("redis needs the address of a Redis protocol server")
+		}
+		return lease.CreateNewRedisLocker(resp.CreateNewClient(redisAddr, redisTimeout)), nil
+	case strings.HasPrefix(spec, "sql:"):
+		return createSQLLocker(strings.TrimPrefix(spec, "sql:"))
+	default:
+		return nil, errors.New(fmt.Sprintf("'%s' is not file:<dir>, redis or sql:<driver>:<dsn>", spec))
+	}
+}
+
+// sqlDialects - The dialect of the leases of each database driver
+var sqlDialects = map[string]lease.SQLDialect{
+	"postgres": lease.PostgresAdvisoryLocks,
+	"pgx":      lease.PostgresAdvisoryLocks,
+	"mysql":    lease.MySQLNamedLocks,
+}
+
+// createSQLLocker - The locker of `<driver>:<dsn>`. The driver has
+//					 to be registered with database/sql by a build
+//					 that imports it, as none is part of this module.
+func createSQLLocker(spec string) (lease.Locker, error) {
+	parts := strings.SplitN(spec, ":", 2)
+	if len(parts) != 2 || parts[1] == "" {
+		return nil, errors.New(fmt.Sprintf("'sql:%s' is not sql:<driver>:<dsn>", spec))
+	}
+	driverName, dsn := parts[0], parts[1]
+
+	dialect, exists := sqlDialects[driverName]
+	if !exists {
+		return nil, errors.New(fmt.Sprintf("'%s' is not a driver of PostgreSQL or MySQL, postgres, pgx or mysql", driverName))
+	}
+	if !registered(driverName) {
+		return nil, errors.New(fmt.Sprintf("The '%s' driver is not registered, build the server with it imported", driverName))
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot open the database of the leases")
+	}
+	return lease.CreateNewSQLLocker(db, dialect, sqlTimeout), nil
+}
+
+func registered(driverName string) bool {
+	for _, d := range sql.Drivers() {
+		if d == driverName {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,277 @@
+package app_test
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api/exchangepb"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+func TestCreateNewApp(t *testing.T) {
+	t.Run("ensure the default config can be composed", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNil(t, err)
+	})
+
+	t.Run("ensure an error for an invalid allowlist", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.Allowlist = "not-a-cidr"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure an error for a missing API keys file", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.APIKeysFile = "testdata/missing.json"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure an error for TLS without a key", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.TLS.CertFile = "testdata/missing.pem"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+	t.Run("ensure an error for a redis refresh lock without a Redis address", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.RefreshLock = "redis"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure a sql refresh lock is composed with a registered driver", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.RefreshLock = "sql:postgres:postgres://localhost/exchange"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNil(t, err)
+	})
+
+	t.Run("ensure an error for a sql refresh lock without a registered driver", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.RefreshLock = "sql:mysql:root@/exchange"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure an error for a sql refresh lock of an unknown database", func(t *testing.T) {
+		for _, spec := range []string{"sql:sqlite3:exchange.db", "sql:postgres", "sql:postgres:"} {
+			// given
+			config := app.DefaultConfig()
+			config.RefreshLock = spec
+
+			// when
+			_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+
+	t.Run("ensure an error for an unknown refresh lock", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.RefreshLock = "zookeeper"
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure an error for chaos without API keys", func(t *testing.T) {
+		// given
+		config := app.DefaultConfig()
+		config.Chaos = true
+
+		// when
+		_, err := app.CreateNewApp(config, util.CreateNewClock())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+func TestAppRun(t *testing.T) {
+	t.Run("ensure Ready is closed without addresses when gRPC cannot listen", func(t *testing.T) {
+		// given
+		taken, err := net.Listen("tcp", "127.0.0.1:0")
+		util.AssertErrorNil(t, err)
+		defer taken.Close()
+		config := app.DefaultConfig()
+		config.Listen = []string{"tcp://127.0.0.1:0"}
+		config.GRPCListen = taken.Addr().String()
+		a, err := app.CreateNewApp(config, util.CreateNewClock())
+		util.AssertErrorNil(t, err)
+
+		// when
+		err = a.Run(context.Background())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		select {
+		case <-a.Ready():
+		case <-time.After(time.Second):
+			t.Fatal("Ready was not closed")
+		}
+		util.AssertTrue(t, len(a.Addrs()) == 0)
+	})
+
+	t.Run("ensure Run returns promptly when ctx is done with a gRPC stream open", func(t *testing.T) {
+		// given
+		grpcAddr := givenFreeAddr(t)
+		_, cancel, stopped := givenRunningApp(t, func(config *app.Config) { config.GRPCListen = grpcAddr })
+		conn, err := grpc.NewClient(grpcAddr, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		util.AssertErrorNil(t, err)
+		defer conn.Close()
+		stream, err := exchangepb.NewExchangeServiceClient(conn).StreamRates(context.Background(), &exchangepb.StreamRatesRequest{Pairs: []*exchangepb.Pair{{From: "EUR", To: "GBP"}}})
+		util.AssertErrorNil(t, err)
+		_, err = stream.Recv()
+		util.AssertErrorNil(t, err)
+
+		// when
+		cancel()
+
+		// then
+		select {
+		case err := <-stopped:
+			util.AssertErrorNil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return")
+		}
+	})
+
+	t.Run("ensure Run returns without an error when ctx is done with an event stream open", func(t *testing.T) {
+		// given
+		addr, cancel, stopped := givenRunningApp(t, func(config *app.Config) {})
+		resp, err := http.Get("http://" + addr + "/v1/stream?pairs=EUR/GBP")
+		util.AssertErrorNil(t, err)
+		defer resp.Body.Close()
+		util.AssertTrue(t, resp.StatusCode == 200)
+		_, err = bufio.NewReader(resp.Body).ReadString('\n')
+		util.AssertErrorNil(t, err)
+
+		// when
+		cancel()
+
+		// then
+		select {
+		case err := <-stopped:
+			util.AssertErrorNil(t, err)
+		case <-time.After(time.Second):
+			t.Fatal("Run did not return")
+		}
+	})
+}
+
+// givenRunningApp - Run the app against a fake provider until the
+//					 returned cancel is called, after which Run's
+//					 error is sent to the returned channel. The
+//					 address HTTP listens on is returned first.
+func givenRunningApp(t *testing.T, configure func(*app.Config)) (string, context.CancelFunc, <-chan error) {
+	t.Helper()
+
+	clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+	upstream := httptest.NewServer(fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, clock))
+	t.Cleanup(upstream.Close)
+
+	config := app.DefaultConfig()
+	config.ProviderURL = upstream.URL
+	config.Listen = []string{"tcp://127.0.0.1:0"}
+	config.GRPCListen = ""
+	configure(&config)
+	a, err := app.CreateNewApp(config, clock)
+	util.AssertErrorNil(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- a.Run(ctx)
+	}()
+
+	select {
+	case <-a.Ready():
+	case <-time.After(time.Second):
+		t.Fatal("Ready was not closed")
+	}
+	if len(a.Addrs()) == 0 {
+		t.Fatalf("app did not start: %s", <-stopped)
+	}
+
+	return a.Addrs()[0].String(), cancel, stopped
+}
+
+// givenFreeAddr - A local address nothing is listening on
+func givenFreeAddr(t *testing.T) string {
+	t.Helper()
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	util.AssertErrorNil(t, err)
+	defer l.Close()
+
+	return l.Addr().String()
+}
+
+// unconnectedDriver - Stands in for a PostgreSQL driver, which is
+//					   not part of this module. Opening a database
+//					   does not connect so it is never used.
+type unconnectedDriver struct{}
+
+func (unconnectedDriver) Open(name string) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+
+func init() {
+	sql.Register("postgres", unconnectedDriver{})
+}
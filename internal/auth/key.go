@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// Scope - What an API key is allowed to do
+type Scope string
+
+// Scopes that can be given to an API key
+const (
+	ScopeReadRates    Scope = "rates:read"
+	ScopeManageAlerts Scope = "alerts:manage"
+	ScopeAdmin        Scope = "admin"
+)
+
+// ErrUnknownKey - Returned when an API key is not in the store
+var ErrUnknownKey = errors.New("unknown API key")
+
+// Key - An API key and its limits. A zero RateLimit or
+//		 MonthlyQuota means unlimited.
+type Key struct {
+	ID           string  `json:"id"`
+	Name         string  `json:"name"`
+	Key          string  `json:"key"`
+	Scopes       []Scope `json:"scopes"`
+	RateLimit    float64 `json:"rateLimit"`
+	Burst        int     `json:"burst"`
+	MonthlyQuota int64   `json:"monthlyQuota"`
+}
+
+// HasScope - Whether the key has the scope. Admin keys have
+//			  every scope.
+func (k *Key) HasScope(s Scope) bool {
+	for _, scope := range k.Scopes {
+		if scope == s || scope == ScopeAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// KeyStore - Interface to look up API keys
+type KeyStore interface {
+	Lookup(key string) (*Key, error)
+	List() []*Key
+}
+
+type memKeyStore struct {
+	mu   sync.RWMutex
+	keys map[string]*Key
+}
+
+// CreateNewMemKeyStore - Store API keys in memory. Only a hash
+//						  of each key is kept.
+func CreateNewMemKeyStore(keys []*Key) *memKeyStore {
+	m := &memKeyStore{keys: make(map[string]*Key)}
+	for _, k := range keys {
+		stored := *k
+		stored.Key = ""
+		m.keys[hashKey(k.Key)] = &stored
+	}
+	return m
+}
+
+// LoadKeys - Read API keys from a JSON file containing a list
+//			  of keys
+func LoadKeys(path string) ([]*Key, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot read API keys from '%s'", path))
+	}
+
+	keys := []*Key{}
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot unmarshall API keys from '%s'", path))
+	}
+
+	for _, k := range keys {
+		if k.ID == "" || k.Key == "" {
+			return nil, errors.New(fmt.Sprintf("Every API key in '%s' needs an id and a key", path))
+		}
+	}
+
+	return keys, nil
+}
+
+// Lookup - Find the API key
+func (m *memKeyStore) Lookup(key string) (*Key, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	k, exists := m.keys[hashKey(key)]
+	if !exists {
+		return nil, ErrUnknownKey
+	}
+	return k, nil
+}
+
+// List - Every API key, without the key itself, ordered by ID
+func (m *memKeyStore) List() []*Key {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	keys := []*Key{}
+	for _, k := range m.keys {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+	return keys
+}
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
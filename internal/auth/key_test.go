@@ -0,0 +1,114 @@
+package auth_test
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestKeyStore(t *testing.T) {
+	t.Run("ensure a key can be looked up", func(t *testing.T) {
+		// given
+		store := auth.CreateNewMemKeyStore(givenKeys())
+
+		// when
+		k, err := store.Lookup("reader-secret")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, k.ID == "reader")
+		util.AssertTrue(t, k.Key == "")
+	})
+
+	t.Run("ensure an unknown key is not found", func(t *testing.T) {
+		// given
+		store := auth.CreateNewMemKeyStore(givenKeys())
+
+		// when
+		_, err := store.Lookup("reader")
+
+		// then
+		util.AssertTrue(t, err == auth.ErrUnknownKey)
+	})
+
+	t.Run("ensure keys are listed by ID", func(t *testing.T) {
+		// given
+		store := auth.CreateNewMemKeyStore(givenKeys())
+
+		// when
+		keys := store.List()
+
+		// then
+		util.AssertTrue(t, len(keys) == 3)
+		util.AssertTrue(t, keys[0].ID == "admin")
+		util.AssertTrue(t, keys[2].ID == "reader")
+	})
+
+	t.Run("ensure admin keys have every scope", func(t *testing.T) {
+		// given
+		keys := givenKeys()
+
+		// when
+		adminCanManage := keys[2].HasScope(auth.ScopeManageAlerts)
+		readerCanManage := keys[0].HasScope(auth.ScopeManageAlerts)
+
+		// then
+		util.AssertTrue(t, adminCanManage)
+		util.AssertFalse(t, readerCanManage)
+	})
+}
+
+func TestLoadKeys(t *testing.T) {
+	t.Run("ensure keys are loaded from a file", func(t *testing.T) {
+		// given
+		path := givenKeysFile(t, `[{"id":"reader","key":"reader-secret","scopes":["rates:read"],"rateLimit":5,"monthlyQuota":1000}]`)
+
+		// when
+		keys, err := auth.LoadKeys(path)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(keys) == 1)
+		util.AssertTrue(t, keys[0].MonthlyQuota == 1000)
+		util.AssertTrue(t, keys[0].HasScope(auth.ScopeReadRates))
+	})
+
+	t.Run("ensure keys without a key are rejected", func(t *testing.T) {
+		// given
+		path := givenKeysFile(t, `[{"id":"reader"}]`)
+
+		// when
+		_, err := auth.LoadKeys(path)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure a missing file is an error", func(t *testing.T) {
+		// when
+		_, err := auth.LoadKeys(filepath.Join(os.TempDir(), "missing-keys.json"))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+func givenKeys() []*auth.Key {
+	return []*auth.Key{
+		{ID: "reader", Name: "Reader", Key: "reader-secret", Scopes: []auth.Scope{auth.ScopeReadRates}},
+		{ID: "limited", Name: "Limited", Key: "limited-secret", Scopes: []auth.Scope{auth.ScopeReadRates}, RateLimit: 0.001, Burst: 2, MonthlyQuota: 3},
+		{ID: "admin", Name: "Admin", Key: "admin-secret", Scopes: []auth.Scope{auth.ScopeAdmin}},
+	}
+}
+
+func givenKeysFile(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatalf("cannot write keys file: %s", err)
+	}
+	return path
+}
@@ -0,0 +1,266 @@
+package auth
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/ratelimit"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+// Where the API key can be passed
+const (
+	KeyHeader     = "X-API-Key"
+	KeyQueryParam = "api_key"
+)
+
+// keyContextKey - The authenticated Key is set on the gin
+//				   context under this name
+const keyContextKey = "apiKey"
+
+// Rule - The scope needed for every path starting with Prefix.
+//		  An empty Scope makes the paths public.
+type Rule struct {
+	Prefix string
+	Scope  Scope
+}
+
+// Usage - Requests made with a key in a month
+type Usage struct {
+	Key         *Key
+	Month       string
+	Requests    int64
+	RateLimited int64
+}
+
+// UsageReporter - Reports the usage of every API key
+type UsageReporter interface {
+	Usage() []*Usage
+}
+
+type usageCounter struct {
+	month       string
+	requests    int64
+	rateLimited int64
+}
+
+type authenticator struct {
+	store        KeyStore
+	rules        []Rule
+	defaultScope Scope
+	clock        util.Clock
+
+	mu      sync.Mutex
+	buckets map[string]*ratelimit.TokenBucket
+	usage   map[string]*usageCounter
+}
+
+// CreateNewAuthenticator - Authenticates requests with the keys
+//							in store. The longest matching rule
+//							decides the scope of a path, and
+//							defaultScope is used when none match.
+func CreateNewAuthenticator(store KeyStore, rules []Rule, defaultScope Scope, clock util.Clock) *authenticator {
+	sorted := append([]Rule{}, rules...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i].Prefix) > len(sorted[j].Prefix) })
+	return &authenticator{store: store,
+		rules:        sorted,
+		defaultScope: defaultScope,
+		clock:        clock,
+		buckets:      make(map[string]*ratelimit.TokenBucket),
+		usage:        make(map[string]*usageCounter)}
+}
+
+// Decision - Whether a request is allowed, see Authorize. Key is
+//			  nil for public paths. Denied requests have the HTTP
+//			  Status, error Code and Message to answer with, and
+//			  RetryAfter when they are over a limit. QuotaLimit
+//			  and QuotaRemaining are set for keys with a monthly
+//			  quota.
+type Decision struct {
+	Key            *Key
+	Status         int
+	Code           string
+	Message        string
+	RetryAfter     time.Duration
+	QuotaLimit     int64
+	QuotaRemaining int64
+}
+
+// Allowed - Whether the request can go ahead
+func (d *Decision) Allowed() bool {
+	return d.Status == 0
+}
+
+// Middleware - Rejects requests without a valid key with the
+//				scope of the path, or that are over the key's
+//				rate limit or monthly quota
+func (a *authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		d := a.Authorize(c.Request.URL.Path, getKey(c))
+		if d.QuotaLimit > 0 {
+			c.Header("X-Quota-Limit", strconv.FormatInt(d.QuotaLimit, 10))
+			c.Header("X-Quota-Remaining", strconv.FormatInt(d.QuotaRemaining, 10))
+		}
+		if !d.Allowed() {
+			if d.RetryAfter > 0 {
+				c.Header("Retry-After", strconv.Itoa(int(math.Ceil(d.RetryAfter.Seconds()))))
+			}
+			abort(c, d.Status, d.Code, d.Message)
+			return
+		}
+
+		if d.Key != nil {
+			c.Set(keyContextKey, d.Key)
+		}
+		c.Next()
+	}
+}
+
+// Authorize - Decide whether a request for path with the API key
+//			   is allowed, counting it against the key's limits.
+//			   This is what Middleware uses, so that other
+//			   transports such as gRPC are held to the same keys.
+func (a *authenticator) Authorize(path, apiKey string) *Decision {
+	scope := a.scopeFor(path)
+	if scope == "" {
+		return &Decision{}
+	}
+
+	key, err := a.store.Lookup(apiKey)
+	if err != nil {
+		return &Decision{Status: 401, Code: api.ErrorCodeUnauthorized, Message: "a valid API key is required in the " + KeyHeader + " header"}
+	}
+
+	if !key.HasScope(scope) {
+		return &Decision{Key: key, Status: 403, Code: api.ErrorCodeForbidden, Message: "the API key does not have the '" + string(scope) + "' scope"}
+	}
+
+	return a.allow(key)
+}
+
+// Usage - Usage of every key this month
+func (a *authenticator) Usage() []*Usage {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	month := a.month()
+	usage := []*Usage{}
+	for _, k := range a.store.List() {
+		u := &Usage{Key: k, Month: month}
+		if counter, exists := a.usage[k.ID]; exists && counter.month == month {
+			u.Requests = counter.requests
+			u.RateLimited = counter.rateLimited
+		}
+		usage = append(usage, u)
+	}
+	return usage
+}
+
+// GetKey - The API key that authenticated the request, nil if
+//			the path is public
+func GetKey(c *gin.Context) *Key {
+	if k, exists := c.Get(keyContextKey); exists {
+		return k.(*Key)
+	}
+	return nil
+}
+
+// allow - Counts the request against the key's limits. Requests
+//		   over the rate limit wait for the bucket, and requests
+//		   over the quota until it resets at the start of the
+//		   next month.
+func (a *authenticator) allow(key *Key) *Decision {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	month := a.month()
+	counter, exists := a.usage[key.ID]
+	if !exists || counter.month != month {
+		counter = &usageCounter{month: month}
+		a.usage[key.ID] = counter
+	}
+
+	d := &Decision{Key: key}
+	if key.MonthlyQuota > 0 {
+		d.QuotaLimit = key.MonthlyQuota
+		d.QuotaRemaining = max64(0, key.MonthlyQuota-counter.requests-1)
+		if counter.requests >= key.MonthlyQuota {
+			counter.rateLimited++
+			d.Status, d.Code, d.Message = 429, api.ErrorCodeQuotaExceed, "the monthly quota of the API key has been used"
+			d.RetryAfter = a.untilNextMonth()
+			return d
+		}
+	}
+
+	if key.RateLimit > 0 {
+		bucket, exists := a.buckets[key.ID]
+		if !exists {
+			burst := key.Burst
+			if burst < 1 {
+				burst = int(math.Ceil(key.RateLimit))
+			}
+			bucket = ratelimit.CreateNewTokenBucket(key.RateLimit, burst, a.clock)
+			a.buckets[key.ID] = bucket
+		}
+
+		ok, _, retryAfter := bucket.Take()
+		if !ok {
+			counter.rateLimited++
+			d.Status, d.Code, d.Message = 429, api.ErrorCodeRateLimited, "too many requests for the API key, slow down"
+			d.RetryAfter = retryAfter
+			return d
+		}
+	}
+
+	counter.requests++
+	return d
+}
+
+func (a *authenticator) scopeFor(path string) Scope {
+	for _, r := range a.rules {
+		if strings.HasPrefix(path, r.Prefix) {
+			return r.Scope
+		}
+	}
+	return a.defaultScope
+}
+
+func (a *authenticator) month() string {
+	return a.clock.Now().UTC().Format("2006-01")
+}
+
+// untilNextMonth - How long until the quotas reset
+func (a *authenticator) untilNextMonth() time.Duration {
+	now := a.clock.Now().UTC()
+	return time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
+
+func getKey(c *gin.Context) string {
+	if key := c.GetHeader(KeyHeader); key != "" {
+		return key
+	}
+	return c.Query(KeyQueryParam)
+}
+
+func abort(c *gin.Context, status int, code, message string) {
+	c.AbortWithStatusJSON(status, api.ExchangeErrorResponse{
+		Code:      code,
+		Message:   message,
+		RequestID: service.GetRequestID(c),
+		Reason:    message,
+	})
+}
+
+func max64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,160 @@
+package auth_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMiddleware(t *testing.T) {
+	tests := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+		code   string
+	}{
+		{"ensure public paths need no key", "/openapi.json", "", 200, ""},
+		{"ensure a missing key is unauthorized", "/v1/exchange", "", 401, api.ErrorCodeUnauthorized},
+		{"ensure an unknown key is unauthorized", "/v1/exchange", "unknown", 401, api.ErrorCodeUnauthorized},
+		{"ensure a key with the scope is allowed", "/v1/exchange", "reader-secret", 200, ""},
+		{"ensure a key without the scope is forbidden", "/v1/alerts", "reader-secret", 403, api.ErrorCodeForbidden},
+		{"ensure the longest rule wins", "/v1/admin/usage", "reader-secret", 403, api.ErrorCodeForbidden},
+		{"ensure admin keys have every scope", "/v1/alerts", "admin-secret", 200, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			router, _ := givenAuthRouter()
+
+			// when
+			rec := givenRequest(router, tt.path, tt.key)
+
+			// then
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d but actual is %d", tt.status, rec.Code)
+			}
+			if tt.code != "" {
+				assertErrorCode(t, rec, tt.code)
+			}
+		})
+	}
+
+	t.Run("ensure the key can be given as a query parameter", func(t *testing.T) {
+		// given
+		router, _ := givenAuthRouter()
+
+		// when
+		rec := givenRequest(router, "/v1/exchange?api_key=reader-secret", "")
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+	})
+
+	t.Run("ensure requests over the rate limit are rejected", func(t *testing.T) {
+		// given
+		router, _ := givenAuthRouter()
+		givenRequest(router, "/v1/exchange", "limited-secret")
+		givenRequest(router, "/v1/exchange", "limited-secret")
+
+		// when
+		rec := givenRequest(router, "/v1/exchange", "limited-secret")
+
+		// then
+		util.AssertTrue(t, rec.Code == 429)
+		util.AssertTrue(t, rec.Header().Get("Retry-After") != "")
+		assertErrorCode(t, rec, api.ErrorCodeRateLimited)
+	})
+
+	t.Run("ensure requests over the monthly quota are rejected", func(t *testing.T) {
+		// given
+		keys := []*auth.Key{{ID: "quota", Key: "quota-secret", Scopes: []auth.Scope{auth.ScopeReadRates}, MonthlyQuota: 2}}
+		router, a := givenAuthRouterWithKeys(keys)
+		givenRequest(router, "/v1/exchange", "quota-secret")
+		first := givenRequest(router, "/v1/exchange", "quota-secret")
+
+		// when
+		rec := givenRequest(router, "/v1/exchange", "quota-secret")
+
+		// then
+		util.AssertTrue(t, first.Header().Get("X-Quota-Remaining") == "0")
+		util.AssertTrue(t, rec.Code == 429)
+		assertErrorCode(t, rec, api.ErrorCodeQuotaExceed)
+		retryAfter, err := strconv.Atoi(rec.Header().Get("Retry-After"))
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, retryAfter > 0)
+		usage := a.Usage()
+		util.AssertTrue(t, usage[0].Requests == 2)
+		util.AssertTrue(t, usage[0].RateLimited == 1)
+	})
+
+	t.Run("ensure usage is counted per key", func(t *testing.T) {
+		// given
+		router, a := givenAuthRouter()
+		givenRequest(router, "/v1/exchange", "reader-secret")
+		givenRequest(router, "/v1/exchange", "reader-secret")
+		givenRequest(router, "/v1/alerts", "reader-secret")
+
+		// when
+		usage := a.Usage()
+
+		// then
+		util.AssertTrue(t, len(usage) == 3)
+		util.AssertTrue(t, usage[0].Key.ID == "admin")
+		util.AssertTrue(t, usage[0].Requests == 0)
+		util.AssertTrue(t, usage[2].Key.ID == "reader")
+		util.AssertTrue(t, usage[2].Requests == 2)
+	})
+}
+
+func givenAuthRouter() (*gin.Engine, auth.UsageReporter) {
+	return givenAuthRouterWithKeys(givenKeys())
+}
+
+func givenAuthRouterWithKeys(keys []*auth.Key) (*gin.Engine, auth.UsageReporter) {
+	gin.SetMode(gin.ReleaseMode)
+	rules := []auth.Rule{
+		{Prefix: "/openapi.json", Scope: ""},
+		{Prefix: "/v1/alerts", Scope: auth.ScopeManageAlerts},
+		{Prefix: "/v1/admin", Scope: auth.ScopeAdmin},
+	}
+	a := auth.CreateNewAuthenticator(auth.CreateNewMemKeyStore(keys), rules, auth.ScopeReadRates, util.CreateNewClock())
+	router := gin.New()
+	router.Use(service.RequestID(), a.Middleware())
+	ok := func(c *gin.Context) { c.Status(200) }
+	router.GET("/openapi.json", ok)
+	router.GET("/v1/exchange", ok)
+	router.GET("/v1/alerts", ok)
+	router.GET("/v1/admin/usage", ok)
+	return router, a
+}
+
+func givenRequest(router *gin.Engine, path, key string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if key != "" {
+		req.Header.Set(auth.KeyHeader, key)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func assertErrorCode(t *testing.T, rec *httptest.ResponseRecorder, code string) {
+	t.Helper()
+
+	body := api.ExchangeErrorResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("cannot unmarshall error response: %s", err)
+	}
+	if body.Code != code {
+		t.Fatalf("expected code %s but actual is %s", code, body.Code)
+	}
+}
@@ -0,0 +1,245 @@
+package dao
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// Kinds of fault that a Fault injects
+const (
+	FaultLatency     = "latency"
+	FaultTimeout     = "timeout"
+	FaultStatus      = "status"
+	FaultMalformed   = "malformed"
+	FaultMissingRate = "missingRate"
+)
+
+// Calls that a Fault applies to, every call when empty
+const (
+	CallLatest = "latest"
+	CallPast   = "past"
+)
+
+// DefaultFaultTimeout - How long a timeout fault hangs for when
+//						 it has no Duration, longer than any
+//						 timeout of the service
+const DefaultFaultTimeout = time.Minute
+
+// Fault - A fault to inject into calls of the provider.
+//
+//		   latency delays the call by Duration, timeout hangs for
+//		   Duration and then fails, status fails like a response
+//		   with Status, malformed fails like a response that is
+//		   not JSON and missingRate removes the requested
+//		   currencies from the rates of the response.
+//
+//		   A fault is active between Start and End, either of
+//		   which can be zero, on every Every-th call of Call when
+//		   Every is set, and then with Probability when it is set.
+type Fault struct {
+	Kind        string
+	Call        string
+	Probability float64
+	Every       int
+	Start       time.Time
+	End         time.Time
+	Duration    time.Duration
+	Status      int
+}
+
+// ChaosController - Changes the faults of a chaos NetworkDAO
+//					 while it is running
+type ChaosController interface {
+	Faults() []Fault
+	SetFaults(faults []Fault) error
+	Injected() map[string]int
+}
+
+type chaosNetwork struct {
+	network  NetworkDAO
+	clock    util.Clock
+	mu       sync.Mutex
+	random   *rand.Rand
+	faults   []Fault
+	calls    []int
+	injected map[string]int
+}
+
+// CreateNewChaosNetwork - Wraps a NetworkDAO so that the faults set
+//						   with SetFaults are injected into its calls.
+//						   Nothing is injected until then. The same
+//						   seed injects the same faults into the same
+//						   calls.
+func CreateNewChaosNetwork(network NetworkDAO, clock util.Clock, seed int64) *chaosNetwork {
+	return &chaosNetwork{network: network,
+		clock:    clock,
+		random:   rand.New(rand.NewSource(seed)),
+		injected: make(map[string]int)}
+}
+
+// GetExchangeRateForNow - See NetworkDAO
+func (c *chaosNetwork) GetExchangeRateForNow(from string, to ...string) (*ExchangeRateResponse, error) {
+	return c.call(CallLatest, from, to, func() (*ExchangeRateResponse, error) {
+		return c.network.GetExchangeRateForNow(from, to...)
+	})
+}
+
+// GetExchangeRateFromPast - See NetworkDAO
+func (c *chaosNetwork) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*ExchangeRateResponse, error) {
+	return c.call(CallPast, from, to, func() (*ExchangeRateResponse, error) {
+		return c.network.GetExchangeRateFromPast(from, date, to...)
+	})
+}
+
+// Faults - The faults being injected
+func (c *chaosNetwork) Faults() []Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return append([]Fault{}, c.faults...)
+}
+
+// SetFaults - Replace the faults being injected, none when empty
+func (c *chaosNetwork) SetFaults(faults []Fault) error {
+	for i, f := range faults {
+		if err := validateFault(f); err != nil {
+			return errors.Wrap(err, fmt.Sprintf("Fault %d is invalid", i))
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.faults = append([]Fault{}, faults...)
+	c.calls = make([]int, len(faults))
+	return nil
+}
+
+// Injected - How many faults of each kind have been injected
+func (c *chaosNetwork) Injected() map[string]int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	injected := make(map[string]int)
+	for k, v := range c.injected {
+		injected[k] = v
+	}
+	return injected
+}
+
+// call - Injects the active faults in order. Latency is added up,
+//		  the first failure is returned without calling the
+//		  provider and missing rates are removed from its response.
+func (c *chaosNetwork) call(call, from string, to []string, request func() (*ExchangeRateResponse, error)) (*ExchangeRateResponse, error) {
+	active := c.activeFaults(call)
+	symbols := strings.Join(to, ",")
+
+	missingRate := false
+	for _, f := range active {
+		switch f.Kind {
+		case FaultLatency:
+			<-c.clock.After(f.Duration)
+		case FaultTimeout:
+			duration := f.Duration
+			if duration == 0 {
+				duration = DefaultFaultTimeout
+			}
+			<-c.clock.After(duration)
+			return nil, errors.New(fmt.Sprintf("Timed out getting exchange rate from '%s' to '%s' (injected)", from, symbols))
+		case FaultStatus:
+			return nil, errors.New(fmt.Sprintf("Received %d when getting exchange rate from '%s' to '%s' (injected)", f.Status, from, symbols))
+		case FaultMalformed:
+			// The same error as a body like "<>" from the provider
+			err := json.Unmarshal([]byte("<>"), &ExchangeRateResponse{})
+			return nil, errors.Wrap(err, fmt.Sprintf("Cannot unmarshall body for exchange rate request from '%s' to '%s' (injected)", from, symbols))
+		case FaultMissingRate:
+			missingRate = true
+		}
+	}
+
+	resp, err := request()
+	if err != nil || !missingRate {
+		return resp, err
+	}
+
+	// Copied so that the response of the provider is not changed
+	rates := make(map[string]float32)
+	for k, v := range resp.Rates {
+		rates[k] = v
+	}
+	for _, t := range to {
+		delete(rates, t)
+	}
+	return &ExchangeRateResponse{Base: resp.Base, Date: resp.Date, Rates: rates}, nil
+}
+
+// activeFaults - The faults to inject into this call, counting it
+//				  and the faults
+func (c *chaosNetwork) activeFaults(call string) []Fault {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	active := []Fault{}
+	for i, f := range c.faults {
+		if f.Call != "" && f.Call != call {
+			continue
+		}
+		if !f.Start.IsZero() && now.Before(f.Start) {
+			continue
+		}
+		if !f.End.IsZero() && !now.Before(f.End) {
+			continue
+		}
+		c.calls[i]++
+		if f.Every > 0 && c.calls[i]%f.Every != 0 {
+			continue
+		}
+		if f.Probability > 0 && c.random.Float64() >= f.Probability {
+			continue
+		}
+		c.injected[f.Kind]++
+		active = append(active, f)
+	}
+	return active
+}
+
+func validateFault(f Fault) error {
+	switch f.Kind {
+	case FaultLatency:
+		if f.Duration <= 0 {
+			return errors.New("A latency fault needs a duration")
+		}
+	case FaultTimeout, FaultMalformed, FaultMissingRate:
+	case FaultStatus:
+		if f.Status < 100 || f.Status > 599 || f.Status == 200 {
+			return errors.New(fmt.Sprintf("Status %d is not an error status", f.Status))
+		}
+	default:
+		return errors.New(fmt.Sprintf("Kind '%s' is not one of latency, timeout, status, malformed or missingRate", f.Kind))
+	}
+
+	if f.Call != "" && f.Call != CallLatest && f.Call != CallPast {
+		return errors.New(fmt.Sprintf("Call '%s' is not one of latest or past", f.Call))
+	}
+	if f.Probability < 0 || f.Probability > 1 {
+		return errors.New("Probability must be between 0 and 1")
+	}
+	if f.Every < 0 {
+		return errors.New("Every cannot be negative")
+	}
+	if f.Duration < 0 {
+		return errors.New("Duration cannot be negative")
+	}
+	if !f.Start.IsZero() && !f.End.IsZero() && !f.Start.Before(f.End) {
+		return errors.New("Start must be before End")
+	}
+	return nil
+}
@@ -0,0 +1,223 @@
+package dao_test
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestChaosNetwork(t *testing.T) {
+	t.Run("ensure calls are passed through without faults", func(t *testing.T) {
+		// given
+		network := &stubNetworkDAO{}
+		chaos := dao.CreateNewChaosNetwork(network, util.CreateNewFakeClock(givenChaosNow()), 1)
+
+		// when
+		resp, err := chaos.GetExchangeRateForNow("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, resp.Rates["GBP"])
+		util.AssertTrue(t, network.calls == 1)
+	})
+
+	t.Run("ensure status, malformed and timeout faults fail without calling the provider", func(t *testing.T) {
+		tests := []struct {
+			fault    dao.Fault
+			contains string
+		}{
+			{dao.Fault{Kind: dao.FaultStatus, Status: 503}, "Received 503"},
+			{dao.Fault{Kind: dao.FaultMalformed}, "Cannot unmarshall body"},
+			{dao.Fault{Kind: dao.FaultTimeout, Duration: time.Millisecond}, "Timed out"},
+		}
+
+		for _, tt := range tests {
+			// given
+			network := &stubNetworkDAO{}
+			clock := util.CreateNewFakeClock(givenChaosNow())
+			chaos := dao.CreateNewChaosNetwork(network, clock, 1)
+			util.AssertErrorNil(t, chaos.SetFaults([]dao.Fault{tt.fault}))
+			go func() {
+				clock.BlockUntil(1)
+				clock.Advance(time.Millisecond)
+			}()
+
+			// when
+			_, err := chaos.GetExchangeRateForNow("EUR", "GBP")
+
+			// then
+			util.AssertErrorNotNil(t, err)
+			util.AssertTrue(t, strings.Contains(err.Error(), tt.contains))
+			util.AssertTrue(t, network.calls == 0)
+			util.AssertTrue(t, chaos.Injected()[tt.fault.Kind] == 1)
+		}
+	})
+
+	t.Run("ensure latency waits on the clock before calling the provider", func(t *testing.T) {
+		// given
+		network := &stubNetworkDAO{}
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		chaos := dao.CreateNewChaosNetwork(network, clock, 1)
+		chaos.SetFaults([]dao.Fault{{Kind: dao.FaultLatency, Duration: time.Second}})
+		errs := make(chan error, 1)
+		go func() {
+			_, err := chaos.GetExchangeRateForNow("EUR", "GBP")
+			errs <- err
+		}()
+		clock.BlockUntil(1)
+
+		// when
+		callsBefore := network.getCalls()
+		clock.Advance(time.Second)
+
+		// then
+		util.AssertErrorNil(t, <-errs)
+		util.AssertTrue(t, callsBefore == 0)
+		util.AssertTrue(t, network.getCalls() == 1)
+	})
+
+	t.Run("ensure missing rates are removed without changing the provider response", func(t *testing.T) {
+		// given
+		network := &stubNetworkDAO{}
+		chaos := dao.CreateNewChaosNetwork(network, util.CreateNewFakeClock(givenChaosNow()), 1)
+		chaos.SetFaults([]dao.Fault{{Kind: dao.FaultMissingRate, Call: dao.CallPast}})
+
+		// when
+		latest, _ := chaos.GetExchangeRateForNow("EUR", "GBP")
+		past, err := chaos.GetExchangeRateFromPast("EUR", givenChaosNow(), "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		_, latestHasRate := latest.Rates["GBP"]
+		_, pastHasRate := past.Rates["GBP"]
+		util.AssertTrue(t, latestHasRate)
+		util.AssertFalse(t, pastHasRate)
+		util.AssertTrue(t, len(network.resp.Rates) == 2)
+	})
+
+	t.Run("ensure a fault is injected on every Every-th call", func(t *testing.T) {
+		// given
+		chaos := dao.CreateNewChaosNetwork(&stubNetworkDAO{}, util.CreateNewFakeClock(givenChaosNow()), 1)
+		chaos.SetFaults([]dao.Fault{{Kind: dao.FaultStatus, Status: 500, Every: 3}})
+
+		// when
+		failed := []bool{}
+		for i := 0; i < 6; i++ {
+			_, err := chaos.GetExchangeRateForNow("EUR", "GBP")
+			failed = append(failed, err != nil)
+		}
+
+		// then
+		util.AssertTrue(t, !failed[0] && !failed[1] && failed[2])
+		util.AssertTrue(t, !failed[3] && !failed[4] && failed[5])
+	})
+
+	t.Run("ensure a fault is only injected between Start and End", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		chaos := dao.CreateNewChaosNetwork(&stubNetworkDAO{}, clock, 1)
+		chaos.SetFaults([]dao.Fault{{Kind: dao.FaultStatus, Status: 500,
+			Start: givenChaosNow().Add(time.Minute),
+			End:   givenChaosNow().Add(time.Minute * 2)}})
+
+		// when
+		_, before := chaos.GetExchangeRateForNow("EUR", "GBP")
+		clock.Advance(time.Minute)
+		_, during := chaos.GetExchangeRateForNow("EUR", "GBP")
+		clock.Advance(time.Minute)
+		_, after := chaos.GetExchangeRateForNow("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, before)
+		util.AssertErrorNotNil(t, during)
+		util.AssertErrorNil(t, after)
+	})
+
+	t.Run("ensure the same seed injects faults into the same calls", func(t *testing.T) {
+		// given
+		faults := []dao.Fault{{Kind: dao.FaultStatus, Status: 500, Probability: 0.5}}
+		chaos1 := dao.CreateNewChaosNetwork(&stubNetworkDAO{}, util.CreateNewFakeClock(givenChaosNow()), 7)
+		chaos2 := dao.CreateNewChaosNetwork(&stubNetworkDAO{}, util.CreateNewFakeClock(givenChaosNow()), 7)
+		chaos1.SetFaults(faults)
+		chaos2.SetFaults(faults)
+
+		// when
+		failures := 0
+		for i := 0; i < 100; i++ {
+			_, err1 := chaos1.GetExchangeRateForNow("EUR", "GBP")
+			_, err2 := chaos2.GetExchangeRateForNow("EUR", "GBP")
+			util.AssertTrue(t, (err1 == nil) == (err2 == nil))
+			if err1 != nil {
+				failures++
+			}
+		}
+
+		// then
+		util.AssertTrue(t, failures > 25 && failures < 75)
+		util.AssertTrue(t, chaos1.Injected()[dao.FaultStatus] == failures)
+	})
+
+	t.Run("ensure invalid faults are rejected and the old ones are kept", func(t *testing.T) {
+		invalid := []dao.Fault{
+			{Kind: "explode"},
+			{Kind: dao.FaultLatency},
+			{Kind: dao.FaultStatus, Status: 200},
+			{Kind: dao.FaultMalformed, Probability: 1.5},
+			{Kind: dao.FaultMalformed, Call: "tomorrow"},
+			{Kind: dao.FaultMalformed, Start: givenChaosNow(), End: givenChaosNow()},
+		}
+
+		for _, f := range invalid {
+			// given
+			chaos := dao.CreateNewChaosNetwork(&stubNetworkDAO{}, util.CreateNewFakeClock(givenChaosNow()), 1)
+			chaos.SetFaults([]dao.Fault{{Kind: dao.FaultMalformed}})
+
+			// when
+			err := chaos.SetFaults([]dao.Fault{f})
+
+			// then
+			util.AssertErrorNotNil(t, err)
+			util.AssertTrue(t, chaos.Faults()[0].Kind == dao.FaultMalformed)
+		}
+	})
+}
+
+type stubNetworkDAO struct {
+	mu    sync.Mutex
+	calls int
+	resp  *dao.ExchangeRateResponse
+}
+
+func (s *stubNetworkDAO) GetExchangeRateForNow(from string, to ...string) (*dao.ExchangeRateResponse, error) {
+	return s.response(), nil
+}
+
+func (s *stubNetworkDAO) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*dao.ExchangeRateResponse, error) {
+	return s.response(), nil
+}
+
+func (s *stubNetworkDAO) response() *dao.ExchangeRateResponse {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.calls++
+	if s.resp == nil {
+		s.resp = &dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: map[string]float32{"GBP": 0.8, "USD": 1.1}}
+	}
+	return s.resp
+}
+
+func (s *stubNetworkDAO) getCalls() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.calls
+}
+
+func givenChaosNow() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
@@ -1,6 +1,7 @@
 package dao
 
 import (
+	"sync"
 	"time"
 )
 
@@ -8,17 +9,29 @@ import (
 type DatabaseDAO interface {
 	Store(from string, to string, oneUnit float32, shouldExchange bool, now time.Time)
 	Get(from string, to string) (float32, bool, time.Time)
+	StoreHistorical(from string, to string, date time.Time, oneUnit float32, shouldExchange bool)
+	GetHistorical(from string, to string, date time.Time) (float32, bool, bool)
+}
+
+type historicalRate struct {
+	oneUnit        float32
+	shouldExchange bool
 }
 
 type memstore struct {
+	mu             sync.RWMutex
 	oneUnit        map[string]float32
 	shouldExchange map[string]bool
 	dt             map[string]time.Time
+	historical     map[string]historicalRate
 }
 
 // CreateNewMemstore - Cache in memory exchange data from the internet
 func CreateNewMemstore() *memstore {
-	m := memstore{oneUnit: make(map[string]float32), shouldExchange: make(map[string]bool), dt: make(map[string]time.Time)}
+	m := memstore{oneUnit: make(map[string]float32),
+		shouldExchange: make(map[string]bool),
+		dt:             make(map[string]time.Time),
+		historical:     make(map[string]historicalRate)}
 	return &m
 }
 
@@ -26,6 +39,9 @@ func CreateNewMemstore() *memstore {
 // 				  to another currency (e.g. GBP) and whether it's a
 // 				  good time to buy
 func (m *memstore) Store(from string, to string, oneUnit float32, shouldExchange bool, now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	key := from + to
 	m.oneUnit[key] = oneUnit
 	m.shouldExchange[key] = shouldExchange
@@ -36,6 +52,33 @@ func (m *memstore) Store(from string, to string, oneUnit float32, shouldExchange
 // 			    to another currency (e.g. GBP) and whether it's a good
 //				time to buy (time when stored)
 func (m *memstore) Get(from string, to string) (float32, bool, time.Time) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	key := from + to
 	return m.oneUnit[key], m.shouldExchange[key], m.dt[key]
 }
+
+// StoreHistorical - Store the exchange rate of a past day. Past
+//					 rates never change so they are kept forever.
+func (m *memstore) StoreHistorical(from string, to string, date time.Time, oneUnit float32, shouldExchange bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.historical[historicalKey(from, to, date)] = historicalRate{oneUnit: oneUnit, shouldExchange: shouldExchange}
+}
+
+// GetHistorical - Get the stored exchange rate of a past day. The
+//				   last value is false when it has not been stored.
+func (m *memstore) GetHistorical(from string, to string, date time.Time) (float32, bool, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	r, exists := m.historical[historicalKey(from, to, date)]
+	return r.oneUnit, r.shouldExchange, exists
+}
+
+// historicalKey - Only the day of date is part of the key
+func historicalKey(from, to string, date time.Time) string {
+	return from + to + date.UTC().Format("2006-01-02")
+}
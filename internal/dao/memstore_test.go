@@ -23,3 +23,33 @@ func TestMemstore(t *testing.T) {
 		util.AssertNotNil(t, dt)
 	})
 }
+
+func TestMemstoreHistorical(t *testing.T) {
+	t.Run("historical exchange data is stored by day", func(t *testing.T) {
+		// given
+		d := dao.CreateNewMemstore()
+
+		// when
+		d.StoreHistorical("EUR", "GBP", time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), 0.8, true)
+		oneUnit, shouldExchange, exists := d.GetHistorical("EUR", "GBP", time.Date(2019, 10, 1, 15, 30, 0, 0, time.UTC))
+
+		// then
+		util.AssertTrue(t, exists)
+		util.AssertEquals(t, 0.8, oneUnit)
+		util.AssertTrue(t, shouldExchange)
+	})
+
+	t.Run("historical exchange data of another day is missing", func(t *testing.T) {
+		// given
+		d := dao.CreateNewMemstore()
+		d.StoreHistorical("EUR", "GBP", time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC), 0.8, true)
+
+		// when
+		_, _, otherDay := d.GetHistorical("EUR", "GBP", time.Date(2019, 10, 2, 0, 0, 0, 0, time.UTC))
+		_, _, otherPair := d.GetHistorical("EUR", "USD", time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC))
+
+		// then
+		util.AssertFalse(t, otherDay)
+		util.AssertFalse(t, otherPair)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
@@ -20,8 +21,8 @@ type ExchangeRateResponse struct {
 // NetworkDAO - interface to get exchange data from over the
 //				network (e.g. HTTP, FTP etc.)
 type NetworkDAO interface {
-	GetExchangeRateForNow(from, to string) (*ExchangeRateResponse, error)
-	GetExchangeRateFromPast(from, to string, date time.Time) (*ExchangeRateResponse, error)
+	GetExchangeRateForNow(from string, to ...string) (*ExchangeRateResponse, error)
+	GetExchangeRateFromPast(from string, date time.Time, to ...string) (*ExchangeRateResponse, error)
 }
 
 // HTTPClient - Http client interface
@@ -43,24 +44,30 @@ func CreateNewFerAPI(url, latest, layoutISO string, client HTTPClient) *ferAPI {
 	return &ferAPI{url: url, client: client, layoutISO: layoutISO, latest: latest}
 }
 
-// GetExchangeRateForNow - Get the exchange rate for {from} to {to}
-func (f *ferAPI) GetExchangeRateForNow(from, to string) (*ExchangeRateResponse, error) {
+// GetExchangeRateForNow - Get the exchange rate for {from} to {to}.
+//							Several {to} currencies can be requested in
+//							a single call.
+func (f *ferAPI) GetExchangeRateForNow(from string, to ...string) (*ExchangeRateResponse, error) {
 	return f.getRequest(from, to, f.latest)
 }
 
-// GetExchangeRateFromPast - Get the exchange rate for {from} to {to} from n days back
-func (f *ferAPI) GetExchangeRateFromPast(from, to string, date time.Time) (*ExchangeRateResponse, error) {
+// GetExchangeRateFromPast - Get the exchange rate for {from} to {to} on the
+//							 given date. Several {to} currencies can be
+//							 requested in a single call.
+func (f *ferAPI) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*ExchangeRateResponse, error) {
 	test := date.Format(f.layoutISO)
 	return f.getRequest(from, to, test)
 }
 
-func (f *ferAPI) getRequest(from, to, endpoint string) (*ExchangeRateResponse, error) {
-	resp, err := f.performGetRequest(from, to, endpoint)
+func (f *ferAPI) getRequest(from string, to []string, endpoint string) (*ExchangeRateResponse, error) {
+	symbols := strings.Join(to, ",")
+
+	resp, err := f.performGetRequest(from, symbols, endpoint)
 	if err != nil {
 		return nil, err
 	}
 
-	data, err := f.unmarshallResponseBody(resp, from, to)
+	data, err := f.unmarshallResponseBody(resp, from, symbols)
 	if err != nil {
 		return nil, err
 	}
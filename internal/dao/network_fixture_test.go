@@ -0,0 +1,174 @@
+package dao_test
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/httpfixture"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+var record = flag.Bool("record", false, "record the fixtures in testdata from a local stand-in provider")
+
+// TestFerAPIFixtures - Runs the provider adapter against fixtures
+//						recorded from a stand-in provider. Run with
+//						-record to record them again.
+func TestFerAPIFixtures(t *testing.T) {
+	fixtures := givenFerAPIFixtures(t)
+
+	t.Run("ensure the latest rates are parsed", func(t *testing.T) {
+		// given
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), fixtures...)
+		d := givenFixtureNetworkDao(replayer)
+
+		// when
+		body, err := d.GetExchangeRateForNow("EUR", "GBP", "USD")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, body.Base == "EUR")
+		util.AssertTrue(t, body.Date == "2019-10-14")
+		util.AssertEquals(t, 0.87518, body.Rates["GBP"])
+		util.AssertEquals(t, 1.1043, body.Rates["USD"])
+	})
+
+	t.Run("ensure the rates of a past date are requested from its path", func(t *testing.T) {
+		// given
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), fixtures...)
+		d := givenFixtureNetworkDao(replayer)
+
+		// when
+		body, err := d.GetExchangeRateFromPast("EUR", time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC), "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, body.Date == "2019-10-07")
+		util.AssertEquals(t, 0.8985, body.Rates["GBP"])
+		util.AssertTrue(t, replayer.Served("GET", "/2019-10-07", "base=EUR&symbols=GBP") == 1)
+	})
+
+	t.Run("ensure an error when the provider has no rates for the date", func(t *testing.T) {
+		// given
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), fixtures...)
+		d := givenFixtureNetworkDao(replayer)
+
+		// when
+		body, err := d.GetExchangeRateFromPast("EUR", time.Date(1998, 12, 31, 0, 0, 0, 0, time.UTC), "GBP")
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertTrue(t, body == nil)
+	})
+
+	t.Run("ensure every call of a multi call flow reads its own body", func(t *testing.T) {
+		// given
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), fixtures...)
+		d := givenFixtureNetworkDao(replayer)
+
+		// when
+		first, err1 := d.GetExchangeRateForNow("EUR", "GBP", "USD")
+		second, err2 := d.GetExchangeRateForNow("EUR", "GBP", "USD")
+
+		// then
+		util.AssertErrorNil(t, err1)
+		util.AssertErrorNil(t, err2)
+		util.AssertTrue(t, first.Rates["GBP"] == second.Rates["GBP"])
+		util.AssertTrue(t, replayer.Served("GET", "/latest", "base=EUR&symbols=GBP,USD") == 2)
+	})
+
+	t.Run("ensure a transport error after a provider outage recovers", func(t *testing.T) {
+		// given
+		outage := &httpfixture.Fixture{Method: "GET", Path: "/latest", Query: "base=EUR&symbols=GBP,USD", Error: "connection refused"}
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), append([]*httpfixture.Fixture{outage}, fixtures...)...)
+		d := givenFixtureNetworkDao(replayer)
+
+		// when
+		_, err1 := d.GetExchangeRateForNow("EUR", "GBP", "USD")
+		body, err2 := d.GetExchangeRateForNow("EUR", "GBP", "USD")
+
+		// then
+		util.AssertErrorNotNil(t, err1)
+		util.AssertErrorNil(t, err2)
+		util.AssertEquals(t, 0.87518, body.Rates["GBP"])
+	})
+
+	t.Run("ensure a slow provider times out the request", func(t *testing.T) {
+		// given
+		slow := &httpfixture.Fixture{Method: "GET", Path: "/latest", Query: "base=EUR&symbols=GBP", Latency: "10s", Body: "{}"}
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewFakeClock(time.Now()), slow)
+		client := &http.Client{Transport: transportFunc(replayer.Do), Timeout: time.Millisecond * 10}
+		d := givenFixtureNetworkDao(client)
+
+		// when
+		_, err := d.GetExchangeRateForNow("EUR", "GBP")
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+// transportFunc - Lets a dao.HTTPClient be the transport of an
+//				   http.Client, so that the client's timeout applies
+type transportFunc func(*http.Request) (*http.Response, error)
+
+func (f transportFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func givenFixtureNetworkDao(client dao.HTTPClient) dao.NetworkDAO {
+	return dao.CreateNewFerAPI("https://api.exchangeratesapi.io", "latest", "2006-01-02", client)
+}
+
+func givenFerAPIFixtures(t *testing.T) []*httpfixture.Fixture {
+	t.Helper()
+
+	path := filepath.Join("testdata", "fer_api.json")
+	if *record {
+		recordFerAPIFixtures(t, path)
+	}
+
+	fixtures, err := httpfixture.LoadFixtures(path)
+	if err != nil {
+		t.Fatalf("cannot load fixtures: %s", err)
+	}
+	return fixtures
+}
+
+// recordFerAPIFixtures - Records every request the tests make
+//						  from a stand-in for exchangeratesapi.io
+func recordFerAPIFixtures(t *testing.T, path string) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rates := map[string]string{
+			"/latest":     `{"rates":{"GBP":0.87518,"USD":1.1043},"base":"EUR","date":"2019-10-14"}`,
+			"/2019-10-07": `{"rates":{"GBP":0.8985},"base":"EUR","date":"2019-10-07"}`,
+		}
+		body, exists := rates[r.URL.Path]
+		w.Header().Set("Content-Type", "application/json")
+		if !exists {
+			w.WriteHeader(400)
+			fmt.Fprint(w, `{"error":"There is no data for dates older then 1999-01-04."}`)
+			return
+		}
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+
+	recorder := httpfixture.CreateNewRecorder(server.Client())
+	d := dao.CreateNewFerAPI(server.URL, "latest", "2006-01-02", recorder)
+	d.GetExchangeRateForNow("EUR", "GBP", "USD")
+	d.GetExchangeRateForNow("EUR", "GBP")
+	d.GetExchangeRateFromPast("EUR", time.Date(2019, 10, 7, 0, 0, 0, 0, time.UTC), "GBP")
+	d.GetExchangeRateFromPast("EUR", time.Date(1998, 12, 31, 0, 0, 0, 0, time.UTC), "GBP")
+
+	if err := recorder.Save(path); err != nil {
+		t.Fatalf("cannot record fixtures: %s", err)
+	}
+}
@@ -87,12 +87,31 @@ func TestNetworkDao(t *testing.T) {
 		dao := GetValidTestNetworkDao(client)
 
 		// when
-		body, err := dao.GetExchangeRateFromPast("EUR", "GBP", time.Now())
+		body, err := dao.GetExchangeRateFromPast("EUR", time.Now(), "GBP")
 
 		// then
 		util.AssertErrorNil(t, err)
 		util.AssertNotEquals(t, 0, body.Rates["GBP"])
 	})
+
+	t.Run("perform a single grouped exchange rate request from EUR to USD and GBP", func(t *testing.T) {
+		// given
+		timeout := time.Duration(5 * time.Second)
+		client := &util.ClientMock{
+			Timeout: timeout,
+		}
+		client.Response = util.GetValidResponseForExchangeRate()
+		dao := GetValidTestNetworkDao(client)
+
+		// when
+		body, err := dao.GetExchangeRateForNow("EUR", "USD", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, client.Request.URL.Query().Get("symbols") == "USD,GBP")
+		util.AssertNotEquals(t, 0, body.Rates["USD"])
+		util.AssertNotEquals(t, 0, body.Rates["GBP"])
+	})
 }
 
 func GetValidTestNetworkDao(client *util.ClientMock) dao.NetworkDAO {
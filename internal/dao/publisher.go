@@ -0,0 +1,25 @@
+package dao
+
+import (
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+)
+
+type publishingStore struct {
+	DatabaseDAO
+	broker *pubsub.Broker
+}
+
+// CreateNewPublishingStore - Wraps a DatabaseDAO so that every
+//							  stored rate is published on the
+//							  broker after it has been stored
+func CreateNewPublishingStore(db DatabaseDAO, broker *pubsub.Broker) *publishingStore {
+	return &publishingStore{DatabaseDAO: db, broker: broker}
+}
+
+// Store - Store the exchange data and publish it
+func (p *publishingStore) Store(from string, to string, oneUnit float32, shouldExchange bool, now time.Time) {
+	p.DatabaseDAO.Store(from, to, oneUnit, shouldExchange, now)
+	p.broker.Publish(pubsub.RateUpdate{From: from, To: to, OneUnit: oneUnit, ShouldExchange: shouldExchange, DataDateTime: now})
+}
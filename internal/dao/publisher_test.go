@@ -0,0 +1,30 @@
+package dao_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestPublishingStore(t *testing.T) {
+	t.Run("stored exchange data is published", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		s := broker.Subscribe(nil, 1)
+		d := dao.CreateNewPublishingStore(dao.CreateNewMemstore(), broker)
+
+		// when
+		d.Store("EUR", "GBP", 0.8, true, time.Now())
+
+		// then
+		oneUnit, _, _ := d.Get("EUR", "GBP")
+		util.AssertEquals(t, 0.8, oneUnit)
+		u := <-s.C
+		util.AssertTrue(t, u.From == "EUR" && u.To == "GBP")
+		util.AssertEquals(t, 0.8, u.OneUnit)
+		util.AssertTrue(t, u.ShouldExchange)
+	})
+}
@@ -0,0 +1,324 @@
+package dao
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// Keys and channel of the shared cache
+const (
+	TieredRatePrefix          = "exchange:rate:"
+	TieredHistoricalPrefix    = "exchange:historical:"
+	TieredInvalidationChannel = "exchange:invalidate"
+)
+
+// TieredConfig - L1TTL is how long a local rate is served before it
+//				  is checked against the shared cache again, in case
+//				  an invalidation was missed. The shared cache is
+//				  not used for RetryAfter once it has failed.
+//				  RateTTL and HistoricalTTL are how long the shared
+//				  cache keeps a rate and a rate of a past day, those
+//				  of DefaultTieredConfig when they are not above 0.
+type TieredConfig struct {
+	L1TTL         time.Duration
+	RetryAfter    time.Duration
+	RateTTL       time.Duration
+	HistoricalTTL time.Duration
+}
+
+// DefaultTieredConfig - The config of `cmd/exchange-server`, which
+//						 derives RateTTL from how long a rate is
+//						 valid for
+var DefaultTieredConfig = TieredConfig{L1TTL: time.Second,
+	RetryAfter:    5 * time.Second,
+	RateTTL:       time.Minute,
+	HistoricalTTL: 30 * 24 * time.Hour}
+
+type tieredStore struct {
+	l1            DatabaseDAO
+	l2            resp.Client
+	config        TieredConfig
+	clock         util.Clock
+	replica       string
+	mu            sync.Mutex
+	checked       map[string]time.Time
+	invalidations int
+	downUntil     time.Time
+	stop          chan struct{}
+	done          chan struct{}
+}
+
+// CreateNewTieredStore - A DatabaseDAO that keeps rates in l1, in
+//						  front of l2, a Redis protocol cache shared
+//						  by every replica. Stored rates are written
+//						  through to l2, and the other replicas are
+//						  told to read them from l2 rather than
+//						  their own l1. Only l1 is used while l2 is
+//						  unavailable. Close stops listening for
+//						  the other replicas.
+func CreateNewTieredStore(l1 DatabaseDAO, l2 resp.Client, config TieredConfig, clock util.Clock) *tieredStore {
+	// The shared cache would grow with every pair and day otherwise
+	if config.RateTTL <= 0 {
+		config.RateTTL = DefaultTieredConfig.RateTTL
+	}
+	if config.HistoricalTTL <= 0 {
+		config.HistoricalTTL = DefaultTieredConfig.HistoricalTTL
+	}
+
+	t := &tieredStore{l1: l1,
+		l2:      l2,
+		config:  config,
+		clock:   clock,
+		replica: createReplicaID(),
+		checked: make(map[string]time.Time),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{})}
+	go t.subscribe()
+	return t
+}
+
+// Store - Store the rate in l1 and l2, and tell the other replicas
+func (t *tieredStore) Store(from string, to string, oneUnit float32, shouldExchange bool, now time.Time) {
+	key := from + to
+	version := t.version()
+	t.l1.Store(from, to, oneUnit, shouldExchange, now)
+	t.setChecked(key, version)
+
+	if !t.isAvailable() {
+		return
+	}
+	if err := t.l2.Set(TieredRatePrefix+key, encodeRate(oneUnit, shouldExchange, now), t.config.RateTTL); err != nil {
+		t.setUnavailable(err)
+		return
+	}
+	if _, err := t.l2.Publish(TieredInvalidationChannel, t.replica+" "+key); err != nil {
+		t.setUnavailable(err)
+	}
+}
+
+// Get - Get the rate from l1, unless another replica may have
+//		 stored a newer one in l2
+func (t *tieredStore) Get(from string, to string) (float32, bool, time.Time) {
+	key := from + to
+	oneUnit, shouldExchange, dataDateTime := t.l1.Get(from, to)
+	if t.isChecked(key) || !t.isAvailable() {
+		return oneUnit, shouldExchange, dataDateTime
+	}
+
+	version := t.version()
+	value, exists, err := t.l2.Get(TieredRatePrefix + key)
+	if err != nil {
+		t.setUnavailable(err)
+		return oneUnit, shouldExchange, dataDateTime
+	}
+	if exists {
+		l2OneUnit, l2ShouldExchange, l2DataDateTime, err := decodeRate(value)
+		if err != nil {
+			log.Printf("Shared cache has an invalid rate for '%s': %s\n", key, err)
+		} else if l2DataDateTime.After(dataDateTime) {
+			oneUnit, shouldExchange, dataDateTime = l2OneUnit, l2ShouldExchange, l2DataDateTime
+			t.l1.Store(from, to, oneUnit, shouldExchange, dataDateTime)
+		}
+	}
+	t.setChecked(key, version)
+
+	return oneUnit, shouldExchange, dataDateTime
+}
+
+// StoreHistorical - Store the rate of a past day in l1 and l2. Past
+//					 rates never change so no replica is told.
+func (t *tieredStore) StoreHistorical(from string, to string, date time.Time, oneUnit float32, shouldExchange bool) {
+	t.l1.StoreHistorical(from, to, date, oneUnit, shouldExchange)
+
+	if !t.isAvailable() {
+		return
+	}
+	if err := t.l2.Set(TieredHistoricalPrefix+historicalKey(from, to, date), encodeRate(oneUnit, shouldExchange, date), t.config.HistoricalTTL); err != nil {
+		t.setUnavailable(err)
+	}
+}
+
+// GetHistorical - Get the rate of a past day from l1, or from l2
+//				   when another replica has stored it
+func (t *tieredStore) GetHistorical(from string, to string, date time.Time) (float32, bool, bool) {
+	if oneUnit, shouldExchange, exists := t.l1.GetHistorical(from, to, date); exists || !t.isAvailable() {
+		return oneUnit, shouldExchange, exists
+	}
+
+	value, exists, err := t.l2.Get(TieredHistoricalPrefix + historicalKey(from, to, date))
+	if err != nil {
+		t.setUnavailable(err)
+		return 0, false, false
+	}
+	if !exists {
+		return 0, false, false
+	}
+	oneUnit, shouldExchange, _, err := decodeRate(value)
+	if err != nil {
+		log.Printf("Shared cache has an invalid historical rate for '%s%s': %s\n", from, to, err)
+		return 0, false, false
+	}
+
+	t.l1.StoreHistorical(from, to, date, oneUnit, shouldExchange)
+	return oneUnit, shouldExchange, true
+}
+
+// Close - Stop listening for the other replicas
+func (t *tieredStore) Close() error {
+	close(t.stop)
+	<-t.done
+	return nil
+}
+
+// subscribe - Listens for the rates stored by other replicas until
+//			   Close, subscribing again after RetryAfter whenever
+//			   the subscription is lost
+func (t *tieredStore) subscribe() {
+	defer close(t.done)
+
+	for {
+		sub, err := t.l2.Subscribe(TieredInvalidationChannel)
+		if err == nil {
+			// Rates may have been stored while not subscribed
+			t.invalidateAll()
+			if t.receive(sub) {
+				return
+			}
+			err = sub.Err()
+		}
+		t.setUnavailable(err)
+
+		retry := t.clock.NewTimer(t.config.RetryAfter)
+		select {
+		case <-t.stop:
+			retry.Stop()
+			return
+		case <-retry.C():
+		}
+	}
+}
+
+// receive - Invalidate the rates stored by other replicas until
+//			 the subscription is lost, or Close when true is
+//			 returned
+func (t *tieredStore) receive(sub *resp.Subscription) bool {
+	for {
+		select {
+		case m, open := <-sub.C:
+			if !open {
+				return false
+			}
+			fields := strings.Fields(m)
+			if len(fields) == 2 && fields[0] != t.replica {
+				t.invalidate(fields[1])
+			}
+		case <-t.stop:
+			sub.Close()
+			for range sub.C {
+			}
+			return true
+		}
+	}
+}
+
+// version - Changes on every invalidation
+func (t *tieredStore) version() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.invalidations
+}
+
+// setChecked - l1 is up to date with l2 for key, unless anything
+//				has been invalidated since version
+func (t *tieredStore) setChecked(key string, version int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.invalidations == version {
+		t.checked[key] = t.clock.Now()
+	}
+}
+
+func (t *tieredStore) isChecked(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	checked, exists := t.checked[key]
+	return exists && t.clock.Now().Sub(checked) < t.config.L1TTL
+}
+
+func (t *tieredStore) invalidate(key string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.checked, key)
+	t.invalidations++
+}
+
+func (t *tieredStore) invalidateAll() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.checked = make(map[string]time.Time)
+	t.invalidations++
+}
+
+func (t *tieredStore) isAvailable() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return !t.clock.Now().Before(t.downUntil)
+}
+
+// setUnavailable - Stop using l2 for RetryAfter
+func (t *tieredStore) setUnavailable(err error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.clock.Now()
+	if !now.Before(t.downUntil) {
+		log.Printf("Shared cache is unavailable, using the local cache for %s: %s\n", t.config.RetryAfter, err)
+	}
+	t.downUntil = now.Add(t.config.RetryAfter)
+}
+
+func encodeRate(oneUnit float32, shouldExchange bool, dataDateTime time.Time) string {
+	return fmt.Sprintf("%s %t %s", strconv.FormatFloat(float64(oneUnit), 'g', -1, 32), shouldExchange, dataDateTime.UTC().Format(time.RFC3339Nano))
+}
+
+func decodeRate(value string) (float32, bool, time.Time, error) {
+	fields := strings.Fields(value)
+	if len(fields) != 3 {
+		return 0, false, time.Time{}, errors.New(fmt.Sprintf("'%s' is not a rate", value))
+	}
+	oneUnit, err := strconv.ParseFloat(fields[0], 32)
+	if err != nil {
+		return 0, false, time.Time{}, errors.Wrap(err, "Invalid rate")
+	}
+	shouldExchange, err := strconv.ParseBool(fields[1])
+	if err != nil {
+		return 0, false, time.Time{}, errors.Wrap(err, "Invalid should exchange")
+	}
+	dataDateTime, err := time.Parse(time.RFC3339Nano, fields[2])
+	if err != nil {
+		return 0, false, time.Time{}, errors.Wrap(err, "Invalid data date time")
+	}
+	return float32(oneUnit), shouldExchange, dataDateTime, nil
+}
+
+func createReplicaID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,246 @@
+package dao_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestTieredStore(t *testing.T) {
+	t.Run("ensure stored rates are written through to the shared cache", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+
+		// when
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+
+		// then
+		value, exists, err := client.Get(dao.TieredRatePrefix + "EURGBP")
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, exists)
+		util.AssertTrue(t, value == "0.8 true 2019-10-14T19:21:48Z")
+	})
+
+	t.Run("ensure the shared cache expires rates after their TTL", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+		replica.StoreHistorical("EUR", "GBP", clock.Now().AddDate(0, 0, -1), 0.8, true)
+
+		// when
+		clock.Advance(dao.DefaultTieredConfig.RateTTL)
+		_, rateExists, rateErr := client.Get(dao.TieredRatePrefix + "EURGBP")
+		_, historicalKept, _ := client.Get(dao.TieredHistoricalPrefix + "EURGBP2019-10-13")
+		clock.Advance(dao.DefaultTieredConfig.HistoricalTTL)
+		_, historicalExists, historicalErr := client.Get(dao.TieredHistoricalPrefix + "EURGBP2019-10-13")
+
+		// then
+		util.AssertErrorNil(t, rateErr)
+		util.AssertErrorNil(t, historicalErr)
+		util.AssertFalse(t, rateExists)
+		util.AssertTrue(t, historicalKept)
+		util.AssertFalse(t, historicalExists)
+	})
+
+	t.Run("ensure rates are kept before their TTL and without one configured", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		store := dao.CreateNewTieredStore(dao.CreateNewMemstore(), client, dao.TieredConfig{L1TTL: time.Second, RetryAfter: time.Second}, clock)
+		defer store.Close()
+		store.Store("EUR", "GBP", 0.8, true, clock.Now())
+
+		// when
+		clock.Advance(dao.DefaultTieredConfig.RateTTL - time.Millisecond)
+		_, exists, err := client.Get(dao.TieredRatePrefix + "EURGBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, exists)
+	})
+
+	t.Run("ensure a replica reads a rate stored by another one", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica1 := givenReplica(t, client, clock)
+		replica2 := givenReplica(t, client, clock)
+		replica1.Store("EUR", "GBP", 0.8, true, clock.Now())
+
+		// when
+		oneUnit, shouldExchange, dataDateTime := replica2.Get("EUR", "GBP")
+
+		// then
+		util.AssertEquals(t, 0.8, oneUnit)
+		util.AssertTrue(t, shouldExchange)
+		util.AssertTrue(t, dataDateTime.Equal(clock.Now()))
+	})
+
+	t.Run("ensure a newer rate of another replica invalidates the local one", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica1 := givenReplica(t, client, clock)
+		replica2 := givenReplica(t, client, clock)
+		givenSubscribers(t, client, 2)
+		replica1.Store("EUR", "GBP", 0.8, true, clock.Now())
+		replica2.Get("EUR", "GBP")
+
+		// when
+		replica1.Store("EUR", "GBP", 0.9, false, clock.Now().Add(time.Millisecond))
+
+		// then
+		eventually(t, func() bool {
+			oneUnit, _, _ := replica2.Get("EUR", "GBP")
+			return oneUnit == 0.9
+		})
+	})
+
+	t.Run("ensure the shared cache is checked again after L1TTL", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+		givenSubscribers(t, client, 1)
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+		// Stored by a replica whose invalidation was missed
+		client.Set(dao.TieredRatePrefix+"EURGBP", "0.9 false 2019-10-14T19:21:49Z", 0)
+
+		// when
+		before, _, _ := replica.Get("EUR", "GBP")
+		clock.Advance(time.Second)
+		after, _, _ := replica.Get("EUR", "GBP")
+
+		// then
+		util.AssertEquals(t, 0.8, before)
+		util.AssertEquals(t, 0.9, after)
+	})
+
+	t.Run("ensure an older rate in the shared cache does not replace a newer local one", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+		client.Set(dao.TieredRatePrefix+"EURGBP", "0.7 false 2019-10-14T19:00:00Z", 0)
+		clock.Advance(time.Second)
+
+		// when
+		oneUnit, _, _ := replica.Get("EUR", "GBP")
+
+		// then
+		util.AssertEquals(t, 0.8, oneUnit)
+	})
+
+	t.Run("ensure the local cache is used while the shared cache is down", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		server, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+		server.SetDown(true)
+
+		// when
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+		clock.Advance(time.Second * 2)
+		oneUnit, _, _ := replica.Get("EUR", "GBP")
+		commands := server.Commands()
+		replica.Get("EUR", "GBP")
+
+		// then
+		util.AssertEquals(t, 0.8, oneUnit)
+		util.AssertTrue(t, server.Commands() == commands)
+	})
+
+	t.Run("ensure the shared cache is used again after RetryAfter", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		server, client := givenSharedCache(t, clock)
+		replica := givenReplica(t, client, clock)
+		server.SetDown(true)
+		replica.Store("EUR", "GBP", 0.8, true, clock.Now())
+		server.SetDown(false)
+
+		// when
+		clock.Advance(dao.DefaultTieredConfig.RetryAfter)
+		replica.Store("EUR", "USD", 1.1, true, clock.Now())
+
+		// then
+		_, storedWhileDown, _ := client.Get(dao.TieredRatePrefix + "EURGBP")
+		_, storedAfter, _ := client.Get(dao.TieredRatePrefix + "EURUSD")
+		util.AssertFalse(t, storedWhileDown)
+		util.AssertTrue(t, storedAfter)
+	})
+
+	t.Run("ensure a replica reads a historical rate stored by another one", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenChaosNow())
+		_, client := givenSharedCache(t, clock)
+		replica1 := givenReplica(t, client, clock)
+		replica2 := givenReplica(t, client, clock)
+		date := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+		replica1.StoreHistorical("EUR", "GBP", date, 0.89, false)
+
+		// when
+		oneUnit, shouldExchange, exists := replica2.GetHistorical("EUR", "GBP", date)
+		_, _, otherDayExists := replica2.GetHistorical("EUR", "GBP", date.AddDate(0, 0, 1))
+
+		// then
+		util.AssertTrue(t, exists)
+		util.AssertEquals(t, 0.89, oneUnit)
+		util.AssertFalse(t, shouldExchange)
+		util.AssertFalse(t, otherDayExists)
+	})
+}
+
+type sharedCache interface {
+	SetDown(down bool)
+	Commands() int
+}
+
+func givenSharedCache(t *testing.T, clock util.Clock) (sharedCache, resp.Client) {
+	server, err := resp.StartServer("127.0.0.1:0", clock)
+	if err != nil {
+		t.Fatalf("cannot start the shared cache: %s", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	client := resp.CreateNewClient(server.Addr(), time.Second)
+	t.Cleanup(func() { client.Close() })
+	return server, client
+}
+
+func givenReplica(t *testing.T, client resp.Client, clock util.Clock) dao.DatabaseDAO {
+	store := dao.CreateNewTieredStore(dao.CreateNewMemstore(), client, dao.DefaultTieredConfig, clock)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+// givenSubscribers - Waits for n replicas to be listening for each
+//					  other
+func givenSubscribers(t *testing.T, client resp.Client, n int64) {
+	eventually(t, func() bool {
+		received, _ := client.Publish(dao.TieredInvalidationChannel, "probe probe")
+		return received >= n
+	})
+}
+
+// eventually - Fails the test unless condition becomes true within
+//				a second, for what happens on another connection
+func eventually(t *testing.T, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition did not become true within a second")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
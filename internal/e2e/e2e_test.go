@@ -0,0 +1,295 @@
+package e2e_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/e2e"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+// TestServer - Runs the whole server against the fake provider
+//				over HTTP
+func TestServer(t *testing.T) {
+	t.Run("ensure /v1/exchange is served from the provider and then the store", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+
+		// when
+		resp1 := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		resp2 := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+
+		// then
+		util.AssertTrue(t, resp1.Status == 200)
+		util.AssertTrue(t, resp2.Status == 200)
+		util.AssertTrue(t, h.UpstreamRequests() == 2)
+		util.AssertTrue(t, decodeExchange(t, resp1).DataDateTime == decodeExchange(t, resp2).DataDateTime)
+		util.AssertTrue(t, decodeExchange(t, resp1).SingleUnit > 0)
+	})
+
+	t.Run("ensure /v2/exchange converts to many targets", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+
+		// when
+		resp := h.Get(t, "/v2/exchange?from=EUR&to=GBP,USD&amount=10")
+
+		// then
+		util.AssertTrue(t, resp.Status == 200)
+		v2 := api.ExchangeV2Response{}
+		util.AssertErrorNil(t, json.Unmarshal(resp.Body, &v2))
+		util.AssertTrue(t, len(v2.Quotes) == 2)
+		for _, q := range v2.Quotes {
+			util.AssertTrue(t, q.Converted > 0)
+		}
+	})
+
+	t.Run("ensure rates are refreshed once the clock passes their expiry", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		stored := decodeExchange(t, h.Get(t, "/v1/exchange?from=EUR&to=GBP"))
+
+		// when
+		h.Advance(time.Second * 6)
+		refreshed := decodeExchange(t, h.Get(t, "/v1/exchange?from=EUR&to=GBP"))
+
+		// then
+		util.AssertTrue(t, h.UpstreamRequests() == 4)
+		util.AssertTrue(t, stored.DataDateTime != refreshed.DataDateTime)
+	})
+
+	t.Run("ensure an error during an outage and recovery afterwards", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		h.SetUpstreamDown(true)
+		h.Advance(time.Second * 6)
+
+		// when
+		down := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		h.SetUpstreamDown(false)
+		up := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+
+		// then
+		util.AssertTrue(t, down.Status == 500)
+		util.AssertTrue(t, up.Status == 200)
+	})
+
+	t.Run("ensure failed pairs of a batch are reported while the upstream errors", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		h.SetUpstreamErrorRate(1)
+
+		// when
+		resp := h.Get(t, "/v2/exchange?from=EUR&to=GBP,USD")
+
+		// then
+		util.AssertTrue(t, resp.Status == 200)
+		v2 := api.ExchangeV2Response{}
+		util.AssertErrorNil(t, json.Unmarshal(resp.Body, &v2))
+		util.AssertTrue(t, len(v2.Quotes) == 2)
+		util.AssertTrue(t, v2.Quotes[0].To == "GBP" && v2.Quotes[1].To == "USD")
+		for _, q := range v2.Quotes {
+			util.AssertTrue(t, q.Code == api.ErrorCodeInternal)
+			util.AssertTrue(t, q.Reason != "")
+			util.AssertTrue(t, q.SingleUnit == 0)
+			util.AssertTrue(t, q.Converted == 0)
+			util.AssertTrue(t, q.ShouldExchange == nil)
+			util.AssertTrue(t, q.DataDateTime == "")
+		}
+		util.AssertFalse(t, strings.Contains(string(resp.Body), `"converted"`))
+	})
+
+	t.Run("ensure an error when the upstream is slower than the timeout", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		h.SetUpstreamLatency(time.Minute)
+		responses := make(chan *e2e.Response, 1)
+		go func() {
+			responses <- h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		}()
+		// The request waits on its timeout and on the latency of
+		// both provider requests
+		h.Clock.BlockUntil(3)
+
+		// when
+		h.Advance(time.Second * 5)
+
+		// then
+		util.AssertTrue(t, (<-responses).Status == 500)
+	})
+}
+
+// TestServerChaos - Faults injected through `/v1/admin/chaos`
+func TestServerChaos(t *testing.T) {
+	t.Run("ensure injected faults fail refreshes until they are removed", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t, givenChaos(t))
+		h.APIKey = "admin-secret"
+		h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		set := h.Do(t, "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"missingRate","call":"past"}]}`)
+		h.Advance(time.Second * 6)
+
+		// when
+		failed := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		removed := h.Do(t, "DELETE", "/v1/admin/chaos", "")
+		recovered := h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+
+		// then
+		util.AssertTrue(t, set.Status == 200)
+		util.AssertTrue(t, failed.Status == 500)
+		util.AssertTrue(t, strings.Contains(string(failed.Body), "Week old response doesn't contain conversion value to 'GBP'"))
+		util.AssertTrue(t, removed.Status == 204)
+		util.AssertTrue(t, recovered.Status == 200)
+	})
+
+	t.Run("ensure faults cannot be injected without an admin key", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t, givenChaos(t))
+
+		// when
+		missing := h.Do(t, "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"malformed"}]}`)
+		h.APIKey = "reader-secret"
+		reader := h.Do(t, "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"malformed"}]}`)
+
+		// then
+		util.AssertTrue(t, missing.Status == 401)
+		util.AssertTrue(t, reader.Status == 403)
+	})
+
+	t.Run("ensure faults cannot be injected unless chaos is on", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+
+		// when
+		resp := h.Do(t, "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"malformed"}]}`)
+
+		// then
+		util.AssertTrue(t, resp.Status == 404)
+	})
+}
+
+// TestServerConcurrency - Many clients hitting the same pair at
+//						   once while the upstream is slow
+func TestServerConcurrency(t *testing.T) {
+	const clients = 50
+
+	t.Run("ensure one refresh of an expired pair while everyone else gets the stale rate", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		stored := decodeExchange(t, h.Get(t, "/v1/exchange?from=EUR&to=GBP"))
+		h.Advance(time.Second * 6)
+		h.SetUpstreamLatency(time.Second)
+		timers := h.Clock.Timers()
+		requests := h.UpstreamRequests()
+
+		// when
+		responses := make(chan *e2e.Response, clients)
+		for i := 0; i < clients; i++ {
+			go func() {
+				responses <- h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+			}()
+		}
+		// Only the refresh waits on the upstream, so everyone
+		// else is answered without the clock moving
+		stale := []*e2e.Response{}
+		for i := 0; i < clients-1; i++ {
+			stale = append(stale, <-responses)
+		}
+		h.Clock.BlockUntil(timers + 3)
+		h.Advance(time.Second)
+		fresh := <-responses
+
+		// then
+		util.AssertTrue(t, h.UpstreamRequests() == requests+2)
+		for _, resp := range stale {
+			util.AssertTrue(t, resp.Status == 200)
+			util.AssertTrue(t, decodeExchange(t, resp).DataDateTime == stored.DataDateTime)
+			util.AssertTrue(t, resp.Header.Get("Cache-Control") == "max-age=0")
+		}
+		util.AssertTrue(t, fresh.Status == 200)
+		util.AssertTrue(t, decodeExchange(t, fresh).DataDateTime != stored.DataDateTime)
+	})
+
+	t.Run("ensure one fetch of a missing pair while everyone else gets an error", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		h.SetUpstreamLatency(time.Second)
+
+		// when
+		responses := make(chan *e2e.Response, clients)
+		for i := 0; i < clients; i++ {
+			go func() {
+				responses <- h.Get(t, "/v1/exchange?from=EUR&to=USD")
+			}()
+		}
+		failed := []*e2e.Response{}
+		for i := 0; i < clients-1; i++ {
+			failed = append(failed, <-responses)
+		}
+		h.Clock.BlockUntil(3)
+		h.Advance(time.Second)
+		fetched := <-responses
+
+		// then
+		util.AssertTrue(t, h.UpstreamRequests() == 2)
+		for _, resp := range failed {
+			util.AssertTrue(t, resp.Status == 500)
+		}
+		util.AssertTrue(t, fetched.Status == 200)
+	})
+
+	t.Run("ensure fresh rates are served to everyone without the upstream", func(t *testing.T) {
+		// given
+		h := e2e.CreateNewHarness(t)
+		h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+		h.SetUpstreamDown(true)
+
+		// when
+		responses := make(chan *e2e.Response, clients)
+		for i := 0; i < clients; i++ {
+			go func() {
+				responses <- h.Get(t, "/v1/exchange?from=EUR&to=GBP")
+			}()
+		}
+
+		// then
+		for i := 0; i < clients; i++ {
+			util.AssertTrue(t, (<-responses).Status == 200)
+		}
+		util.AssertTrue(t, h.UpstreamRequests() == 2)
+	})
+}
+
+func decodeExchange(t *testing.T, resp *e2e.Response) *api.ExchangeResponse {
+	t.Helper()
+
+	r := &api.ExchangeResponse{}
+	if err := json.Unmarshal(resp.Body, r); err != nil {
+		t.Fatalf("cannot decode %s: %s", resp.Body, err)
+	}
+	return r
+}
+
+// givenChaos - Turn chaos on with an admin and a reader key
+func givenChaos(t *testing.T) func(*app.Config) {
+	keys := filepath.Join(t.TempDir(), "keys.json")
+	err := ioutil.WriteFile(keys, []byte(`[
+		{"id": "admin", "key": "admin-secret", "scopes": ["admin"]},
+		{"id": "reader", "key": "reader-secret", "scopes": ["rates:read"]}
+	]`), 0600)
+	if err != nil {
+		t.Fatalf("cannot write the API keys: %s", err)
+	}
+
+	return func(c *app.Config) {
+		c.Chaos = true
+		c.APIKeysFile = keys
+	}
+}
@@ -0,0 +1,181 @@
+package e2e
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+// StartTime - The time the clock of a harness starts at, a Monday
+var StartTime = time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+
+// readyTimeout - How long the server is given to start listening
+const readyTimeout = 5 * time.Second
+
+type fakeProvider interface {
+	http.Handler
+	SetLatency(latency time.Duration)
+	SetErrorRate(errorRate float64)
+	SetDown(down bool)
+	Requests() int
+}
+
+// Harness - The exchange server composed exactly like
+//			 `cmd/exchange-server`, listening on an ephemeral
+//			 port against a local fake provider. The server
+//			 and the provider share Clock, so rates only
+//			 expire and upstream latency only passes when
+//			 the test advances it.
+//			 APIKey is sent with every request when it is set.
+type Harness struct {
+	URL      string
+	Clock    *util.FakeClock
+	Client   *http.Client
+	APIKey   string
+	provider fakeProvider
+}
+
+// Response - The status, headers and body of a response
+type Response struct {
+	Status int
+	Header http.Header
+	Body   []byte
+}
+
+// CreateNewHarness - Start a fake provider and the server, which are
+//					  both stopped when the test ends. Rate limits are
+//					  off unless configure turns them back on.
+func CreateNewHarness(t *testing.T, configure ...func(*app.Config)) *Harness {
+	t.Helper()
+
+	clock := util.CreateNewFakeClock(StartTime)
+	provider := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, clock)
+	upstream := httptest.NewServer(provider)
+
+	config := app.DefaultConfig()
+	config.ProviderURL = upstream.URL
+	config.ClientRate = 0
+	config.GlobalRate = 0
+	config.Listen = []string{"tcp://127.0.0.1:0"}
+	config.GRPCListen = ""
+	for _, c := range configure {
+		c(&config)
+	}
+
+	a, err := app.CreateNewApp(config, clock)
+	if err != nil {
+		upstream.Close()
+		t.Fatalf("cannot create the server: %s", err)
+	}
+
+	client := &http.Client{Timeout: readyTimeout}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan error, 1)
+	go func() {
+		stopped <- a.Run(ctx)
+	}()
+
+	t.Cleanup(func() {
+		// Requests still waiting on the provider fail rather
+		// than hold up the shutdown
+		upstream.CloseClientConnections()
+		// Connections dialled but never used are not idle to the
+		// server, which would wait for them until it times out
+		client.CloseIdleConnections()
+		cancel()
+		if err := <-stopped; err != nil {
+			t.Errorf("server did not stop cleanly: %s", err)
+		}
+		upstream.Close()
+	})
+
+	select {
+	case <-a.Ready():
+	case <-time.After(readyTimeout):
+		t.Fatalf("server did not start within %s", readyTimeout)
+	}
+	if len(a.Addrs()) == 0 {
+		t.Fatalf("server did not start: %s", <-stopped)
+	}
+
+	return &Harness{URL: "http://" + a.Addrs()[0].String(),
+		Clock:    clock,
+		Client:   client,
+		provider: provider}
+}
+
+// Get - GET path from the server, failing the test if the request
+//		 cannot be made. Safe to call from many goroutines.
+func (h *Harness) Get(t *testing.T, path string) *Response {
+	t.Helper()
+
+	return h.Do(t, "GET", path, "")
+}
+
+// Do - Send a request with a JSON body to the server, failing the
+//		test if the request cannot be made
+func (h *Harness) Do(t *testing.T, method, path, body string) *Response {
+	t.Helper()
+
+	req, err := http.NewRequest(method, h.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Errorf("%s %s: %s", method, path, err)
+		return &Response{}
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if h.APIKey != "" {
+		req.Header.Set(auth.KeyHeader, h.APIKey)
+	}
+
+	resp, err := h.Client.Do(req)
+	if err != nil {
+		t.Errorf("%s %s: %s", method, path, err)
+		return &Response{}
+	}
+	defer resp.Body.Close()
+
+	respBody, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Errorf("%s %s: %s", method, path, err)
+	}
+	return &Response{Status: resp.StatusCode, Header: resp.Header, Body: respBody}
+}
+
+// Advance - Move the shared clock forward, expiring rates and
+//			 releasing slow upstream responses
+func (h *Harness) Advance(d time.Duration) {
+	h.Clock.Advance(d)
+}
+
+// SetUpstreamDown - Answer every upstream request with a 503
+func (h *Harness) SetUpstreamDown(down bool) {
+	h.provider.SetDown(down)
+}
+
+// SetUpstreamLatency - Delay every upstream response until the
+//						clock has been advanced by latency
+func (h *Harness) SetUpstreamLatency(latency time.Duration) {
+	h.provider.SetLatency(latency)
+}
+
+// SetUpstreamErrorRate - Answer a fraction of upstream requests
+//						  with a 500
+func (h *Harness) SetUpstreamErrorRate(errorRate float64) {
+	h.provider.SetErrorRate(errorRate)
+}
+
+// UpstreamRequests - How many requests the provider has received
+func (h *Harness) UpstreamRequests() int {
+	return h.provider.Requests()
+}
@@ -0,0 +1,52 @@
+package endpoint
+
+import (
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+// DataDateTimeLayout - Layout used for every dataDateTime
+//						in a response body
+const DataDateTimeLayout = time.RFC3339Nano
+
+// CreateErrorResponse - Writes the error envelope that is shared
+//						 by every version of the API. Errors are
+//						 always JSON whatever the requested format.
+func CreateErrorResponse(c *gin.Context, status int, code, message string, details ...string) {
+	c.JSON(status, api.ExchangeErrorResponse{
+		Code:      code,
+		Message:   message,
+		Details:   details,
+		RequestID: service.GetRequestID(c),
+		Reason:    message,
+	})
+}
+
+// DocumentErrorResponse - Documents the error envelope written
+//						   by CreateErrorResponse
+func DocumentErrorResponse(d *openapi.Document, description string) *openapi.Response {
+	return &openapi.Response{Description: description, Content: d.JSONContent(api.ExchangeErrorResponse{})}
+}
+
+// Currencies - Sorted list of the valid currencies
+func Currencies(validCurrencies map[string]bool) []string {
+	list := []string{}
+	for currency := range validCurrencies {
+		list = append(list, currency)
+	}
+	sort.Strings(list)
+	return list
+}
+
+// ToFloat64 - Widens a rate using its shortest decimal form so
+//			   that 0.8 stays 0.8 rather than 0.800000011920929
+func ToFloat64(f float32) float64 {
+	v, _ := strconv.ParseFloat(strconv.FormatFloat(float64(f), 'f', -1, 32), 64)
+	return v
+}
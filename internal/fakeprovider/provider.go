@@ -0,0 +1,259 @@
+package fakeprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// ControlPath - Path of the endpoint that changes the behaviour
+//				 of a running provider, see Control
+const ControlPath = "/_control"
+
+// Config - How the fake provider behaves. Rates are the same for
+//			the same Seed. Latency delays every response, a
+//			fraction ErrorRate of requests get a 500 and every
+//			request gets a 503 while Down.
+type Config struct {
+	Seed      int64
+	Rates     map[string]float64
+	Latency   time.Duration
+	ErrorRate float64
+	Down      bool
+}
+
+// Control - The behaviour that can be changed while running
+type Control struct {
+	Latency   string  `json:"latency"`
+	ErrorRate float64 `json:"errorRate"`
+	Down      bool    `json:"down"`
+	Requests  int     `json:"requests"`
+}
+
+type ratesResponse struct {
+	Rates map[string]float64 `json:"rates"`
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+}
+
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+type provider struct {
+	clock    util.Clock
+	mu       sync.Mutex
+	config   Config
+	random   *rand.Rand
+	requests int
+}
+
+// CreateNewProvider - Create a fake of the exchangeratesapi.io
+//					   `/latest` and `/{date}` API. DefaultRates
+//					   are used when config has no Rates.
+func CreateNewProvider(config Config, clock util.Clock) *provider {
+	if config.Rates == nil {
+		config.Rates = DefaultRates
+	}
+	return &provider{clock: clock, config: config, random: rand.New(rand.NewSource(config.Seed))}
+}
+
+// SetLatency - Delay every response by latency
+func (p *provider) SetLatency(latency time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config.Latency = latency
+}
+
+// SetErrorRate - Answer a fraction of requests with a 500
+func (p *provider) SetErrorRate(errorRate float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config.ErrorRate = errorRate
+}
+
+// SetDown - Start or end an outage
+func (p *provider) SetDown(down bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.config.Down = down
+}
+
+// Requests - How many rate requests have been made
+func (p *provider) Requests() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.requests
+}
+
+// ServeHTTP - Serve rates, or change the behaviour on ControlPath
+func (p *provider) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == ControlPath {
+		p.serveControl(w, r)
+		return
+	}
+	if r.Method != "GET" {
+		writeJSON(w, 405, errorResponse{Error: fmt.Sprintf("Method %s is not allowed.", r.Method)})
+		return
+	}
+
+	latency, status := p.nextRequest()
+	if latency > 0 {
+		timer := p.clock.NewTimer(latency)
+		select {
+		case <-timer.C():
+		case <-r.Context().Done():
+			timer.Stop()
+			return
+		}
+	}
+	if status != 200 {
+		writeJSON(w, status, errorResponse{Error: http.StatusText(status)})
+		return
+	}
+
+	resp, err := p.rates(strings.TrimPrefix(r.URL.Path, "/"), r.URL.Query().Get("base"), r.URL.Query().Get("symbols"))
+	if err != nil {
+		writeJSON(w, 400, errorResponse{Error: err.Error()})
+		return
+	}
+	writeJSON(w, 200, resp)
+}
+
+// nextRequest - The latency and the status of the next request.
+//				 Errors come from the seeded random source so the
+//				 same requests fail for the same seed.
+func (p *provider) nextRequest() (time.Duration, int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.requests++
+	if p.config.Down {
+		return p.config.Latency, 503
+	}
+	if p.config.ErrorRate > 0 && p.random.Float64() < p.config.ErrorRate {
+		return p.config.Latency, 500
+	}
+	return p.config.Latency, 200
+}
+
+func (p *provider) rates(path, base, symbols string) (*ratesResponse, error) {
+	day, err := p.day(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if base == "" {
+		base = "EUR"
+	}
+	if _, exists := p.config.Rates[base]; !exists {
+		return nil, errors.New(fmt.Sprintf("Base '%s' is not supported.", base))
+	}
+
+	requested := currencies(p.config.Rates)
+	if symbols != "" {
+		requested = strings.Split(symbols, ",")
+	}
+
+	baseRate := rateOn(p.config.Seed, p.config.Rates, base, day)
+	rates := make(map[string]float64)
+	for _, currency := range requested {
+		if _, exists := p.config.Rates[currency]; !exists {
+			return nil, errors.New(fmt.Sprintf("Symbols '%s' are invalid for date %s.", symbols, day.Format(dateLayout)))
+		}
+		if currency != base || symbols != "" {
+			rates[currency] = round(rateOn(p.config.Seed, p.config.Rates, currency, day)/baseRate, 5)
+		}
+	}
+
+	return &ratesResponse{Rates: rates, Base: base, Date: day.Format(dateLayout)}, nil
+}
+
+// day - The published day of `latest` or of a date. A date after
+//		 today is answered with the latest rates.
+func (p *provider) day(path string) (time.Time, error) {
+	today := publishedDay(p.clock.Now().UTC())
+	if path == "latest" {
+		return today, nil
+	}
+
+	date, err := time.Parse(dateLayout, path)
+	if err != nil {
+		return time.Time{}, errors.New(fmt.Sprintf("time data '%s' does not match format '%%Y-%%m-%%d'", path))
+	}
+	if date.Before(FirstDate) {
+		return time.Time{}, errors.New(fmt.Sprintf("There is no data for dates older then %s.", FirstDate.Format(dateLayout)))
+	}
+	if date.After(today) {
+		return today, nil
+	}
+	return publishedDay(date), nil
+}
+
+// serveControl - GET returns the behaviour, PUT changes whichever
+//				  of `latency`, `errorRate` and `down` are in the
+//				  query
+func (p *provider) serveControl(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case "GET":
+	case "PUT":
+		if err := p.control(r); err != nil {
+			writeJSON(w, 400, errorResponse{Error: err.Error()})
+			return
+		}
+	default:
+		writeJSON(w, 405, errorResponse{Error: fmt.Sprintf("Method %s is not allowed.", r.Method)})
+		return
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	writeJSON(w, 200, Control{Latency: p.config.Latency.String(),
+		ErrorRate: p.config.ErrorRate,
+		Down:      p.config.Down,
+		Requests:  p.requests})
+}
+
+func (p *provider) control(r *http.Request) error {
+	q := r.URL.Query()
+	if latency := q.Get("latency"); latency != "" {
+		d, err := time.ParseDuration(latency)
+		if err != nil || d < 0 {
+			return errors.New(fmt.Sprintf("latency '%s' is not a duration such as 250ms", latency))
+		}
+		p.SetLatency(d)
+	}
+	if errorRate := q.Get("errorRate"); errorRate != "" {
+		f, err := strconv.ParseFloat(errorRate, 64)
+		if err != nil || f < 0 || f > 1 {
+			return errors.New(fmt.Sprintf("errorRate '%s' is not between 0 and 1", errorRate))
+		}
+		p.SetErrorRate(f)
+	}
+	if down := q.Get("down"); down != "" {
+		b, err := strconv.ParseBool(down)
+		if err != nil {
+			return errors.New(fmt.Sprintf("down '%s' is not true or false", down))
+		}
+		p.SetDown(b)
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,189 @@
+package fakeprovider_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+type ratesResponse struct {
+	Rates map[string]float64 `json:"rates"`
+	Base  string             `json:"base"`
+	Date  string             `json:"date"`
+	Error string             `json:"error"`
+}
+
+func TestProvider(t *testing.T) {
+	t.Run("ensure the latest rates are the same for the same seed", func(t *testing.T) {
+		// given
+		p1 := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+		p2 := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+		p3 := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 2}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		_, r1 := performRequest(t, p1, "GET", "/latest?base=EUR&symbols=GBP,USD")
+		_, r2 := performRequest(t, p2, "GET", "/latest?base=EUR&symbols=GBP,USD")
+		_, r3 := performRequest(t, p3, "GET", "/latest?base=EUR&symbols=GBP,USD")
+
+		// then
+		util.AssertTrue(t, r1.Base == "EUR")
+		util.AssertTrue(t, r1.Date == "2019-10-14")
+		util.AssertTrue(t, len(r1.Rates) == 2)
+		util.AssertTrue(t, r1.Rates["GBP"] == r2.Rates["GBP"])
+		util.AssertTrue(t, r1.Rates["GBP"] != r3.Rates["GBP"])
+		util.AssertTrue(t, r1.Rates["GBP"] > 0.83 && r1.Rates["GBP"] < 0.92)
+	})
+
+	t.Run("ensure rates change from day to day", func(t *testing.T) {
+		// given
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		_, monday := performRequest(t, p, "GET", "/2019-10-14?symbols=GBP")
+		_, friday := performRequest(t, p, "GET", "/2019-10-11?symbols=GBP")
+
+		// then
+		util.AssertTrue(t, monday.Rates["GBP"] != friday.Rates["GBP"])
+	})
+
+	t.Run("ensure cross rates are derived from the base", func(t *testing.T) {
+		// given
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		_, eur := performRequest(t, p, "GET", "/latest?base=EUR&symbols=GBP,USD")
+		_, gbp := performRequest(t, p, "GET", "/latest?base=GBP&symbols=USD")
+
+		// then
+		util.AssertEquals(t, float32(eur.Rates["USD"]/eur.Rates["GBP"]), float32(gbp.Rates["USD"]))
+	})
+
+	t.Run("ensure a weekend is answered with the Friday before", func(t *testing.T) {
+		// given
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		_, sunday := performRequest(t, p, "GET", "/2019-10-13?symbols=GBP")
+		_, friday := performRequest(t, p, "GET", "/2019-10-11?symbols=GBP")
+
+		// then
+		util.AssertTrue(t, sunday.Date == "2019-10-11")
+		util.AssertTrue(t, sunday.Rates["GBP"] == friday.Rates["GBP"])
+	})
+
+	t.Run("ensure 400 response for requests the real API rejects", func(t *testing.T) {
+		for _, path := range []string{"/1999-01-01", "/14-10-2019", "/latest?base=FOO", "/latest?symbols=GBP,FOO"} {
+			// given
+			p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+
+			// when
+			status, r := performRequest(t, p, "GET", path)
+
+			// then
+			if status != 400 || r.Error == "" {
+				t.Fatalf("expected 400 with an error for '%s' but actual is %d '%s'", path, status, r.Error)
+			}
+		}
+	})
+
+	t.Run("ensure 503 response during an outage", func(t *testing.T) {
+		// given
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+		p.SetDown(true)
+
+		// when
+		down, _ := performRequest(t, p, "GET", "/latest")
+		p.SetDown(false)
+		up, _ := performRequest(t, p, "GET", "/latest")
+
+		// then
+		util.AssertTrue(t, down == 503)
+		util.AssertTrue(t, up == 200)
+		util.AssertTrue(t, p.Requests() == 2)
+	})
+
+	t.Run("ensure the error rate is seeded", func(t *testing.T) {
+		// given
+		p1 := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1, ErrorRate: 0.5}, util.CreateNewFakeClock(givenMonday()))
+		p2 := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1, ErrorRate: 0.5}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		failures := 0
+		for i := 0; i < 100; i++ {
+			s1, _ := performRequest(t, p1, "GET", "/latest")
+			s2, _ := performRequest(t, p2, "GET", "/latest")
+			if s1 != s2 {
+				t.Fatalf("expected the same status for request %d but actual is %d and %d", i, s1, s2)
+			}
+			if s1 == 500 {
+				failures++
+			}
+		}
+
+		// then
+		util.AssertTrue(t, failures > 25 && failures < 75)
+	})
+
+	t.Run("ensure the response waits for the latency on the clock", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenMonday())
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1, Latency: time.Second}, clock)
+		done := make(chan int, 1)
+		go func() {
+			status, _ := performRequest(t, p, "GET", "/latest")
+			done <- status
+		}()
+
+		// when
+		clock.BlockUntil(1)
+		waiting := len(done) == 0
+		clock.Advance(time.Second)
+
+		// then
+		util.AssertTrue(t, waiting)
+		util.AssertTrue(t, <-done == 200)
+	})
+
+	t.Run("ensure the behaviour can be changed while running", func(t *testing.T) {
+		// given
+		p := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, util.CreateNewFakeClock(givenMonday()))
+
+		// when
+		rec := httptest.NewRecorder()
+		p.ServeHTTP(rec, httptest.NewRequest("PUT", fakeprovider.ControlPath+"?latency=250ms&errorRate=0.1&down=true", nil))
+		control := fakeprovider.Control{}
+		err := json.Unmarshal(rec.Body.Bytes(), &control)
+		invalid := httptest.NewRecorder()
+		p.ServeHTTP(invalid, httptest.NewRequest("PUT", fakeprovider.ControlPath+"?errorRate=2", nil))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, control.Latency == "250ms")
+		util.AssertTrue(t, control.ErrorRate == 0.1)
+		util.AssertTrue(t, control.Down)
+		util.AssertTrue(t, invalid.Code == 400)
+	})
+}
+
+func givenMonday() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
+
+func performRequest(t *testing.T, h http.Handler, method, path string) (int, ratesResponse) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+
+	r := ratesResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &r); err != nil {
+		t.Fatalf("cannot unmarshal '%s': %s", rec.Body.String(), err)
+	}
+	return rec.Code, r
+}
@@ -0,0 +1,74 @@
+package fakeprovider
+
+import (
+	"encoding/binary"
+	"hash/fnv"
+	"math"
+	"sort"
+	"time"
+)
+
+// dateLayout - Layout of the dates in paths and responses
+const dateLayout = "2006-01-02"
+
+// FirstDate - The first day that rates were published, earlier
+//			   dates are rejected like the real API does
+var FirstDate = time.Date(1999, 1, 4, 0, 0, 0, 0, time.UTC)
+
+// DefaultRates - Rates against EUR that the seeded rates vary
+//				  around
+var DefaultRates = map[string]float64{
+	"EUR": 1,
+	"USD": 1.1043,
+	"GBP": 0.87518,
+	"JPY": 119.25,
+	"CHF": 1.0993,
+	"CAD": 1.4604,
+	"AUD": 1.6345,
+}
+
+// maxDailyChange - How far a seeded rate can be from its default
+const maxDailyChange = 0.05
+
+// rateOn - The rate against EUR of currency on the day, the same
+//			for the same seed, currency and day. EUR is always 1.
+func rateOn(seed int64, rates map[string]float64, currency string, day time.Time) float64 {
+	if currency == "EUR" {
+		return 1
+	}
+
+	h := fnv.New64a()
+	binary.Write(h, binary.LittleEndian, seed)
+	h.Write([]byte(currency + day.Format(dateLayout)))
+	// A fraction in [-1, 1)
+	fraction := float64(h.Sum64()%2000000)/1000000 - 1
+
+	return round(rates[currency]*(1+fraction*maxDailyChange), 5)
+}
+
+// publishedDay - Rates are only published on week days, a weekend
+//				  is answered with the Friday before
+func publishedDay(day time.Time) time.Time {
+	day = time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, time.UTC)
+	switch day.Weekday() {
+	case time.Saturday:
+		return day.AddDate(0, 0, -1)
+	case time.Sunday:
+		return day.AddDate(0, 0, -2)
+	}
+	return day
+}
+
+func currencies(rates map[string]float64) []string {
+	list := []string{}
+	for currency := range rates {
+		list = append(list, currency)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func round(f float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(f*p) / p
+}
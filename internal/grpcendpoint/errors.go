@@ -0,0 +1,47 @@
+package grpcendpoint
+
+import (
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// errorDomain - Domain of the ErrorInfo detail of every error
+const errorDomain = "exchange"
+
+// toStatus - Maps an error from validation or the service to a
+//			  gRPC status, using the same error codes as the
+//			  HTTP error envelope
+func toStatus(err error) error {
+	if _, ok := err.(*service.ValidationError); ok {
+		return newStatus(codes.InvalidArgument, api.ErrorCodeInvalidQuery, err.Error())
+	}
+	return newStatus(codes.Internal, api.ErrorCodeInternal, err.Error())
+}
+
+func newStatus(c codes.Code, code, message string) error {
+	s, err := status.New(c, message).WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: errorDomain})
+	if err != nil {
+		return status.Error(c, message)
+	}
+	return s.Err()
+}
+
+// newRetryStatus - newStatus with a RetryInfo detail when the call
+//					can be retried after retryAfter
+func newRetryStatus(c codes.Code, code, message string, retryAfter time.Duration) error {
+	if retryAfter <= 0 {
+		return newStatus(c, code, message)
+	}
+	s, err := status.New(c, message).WithDetails(&errdetails.ErrorInfo{Reason: code, Domain: errorDomain},
+		&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	if err != nil {
+		return status.Error(c, message)
+	}
+	return s.Err()
+}
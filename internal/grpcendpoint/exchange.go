@@ -0,0 +1,237 @@
+package grpcendpoint
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api/exchangepb"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// MaxHistoryDays - The most days that can be requested from
+//					GetHistory
+const MaxHistoryDays = 31
+
+// MaxStreamPairs - The most pairs that can be streamed on a
+//					single call to StreamRates
+const MaxStreamPairs = 50
+
+// streamBuffer - Updates held for a slow stream before the oldest
+//				  are dropped
+const streamBuffer = 16
+
+type grpcExchange struct {
+	exchangepb.UnimplementedExchangeServiceServer
+	exchangeService service.ExchangeRateService
+	broker          *pubsub.Broker
+	validCurrencies map[string]bool
+	clock           util.Clock
+	retryInterval   time.Duration
+	stopped         chan struct{}
+	stopOnce        sync.Once
+}
+
+// CreateNewGrpcExchange - Create the gRPC ExchangeService backed
+//						   by the same service as the HTTP
+//						   endpoints. StreamRates sends the rates
+//						   stored through broker, and asks for the
+//						   pairs it has no rate of yet every
+//						   retryInterval.
+func CreateNewGrpcExchange(exchangeService service.ExchangeRateService, broker *pubsub.Broker, validCurrencies map[string]bool, clock util.Clock, retryInterval time.Duration) *grpcExchange {
+	return &grpcExchange{exchangeService: exchangeService,
+		broker:          broker,
+		validCurrencies: validCurrencies,
+		clock:           clock,
+		retryInterval:   retryInterval,
+		stopped:         make(chan struct{})}
+}
+
+// Stop - End every open StreamRates, and any opened later, with
+//		  Unavailable. Streams only end when their client goes
+//		  away otherwise, which a graceful stop of the server
+//		  would wait for.
+func (g *grpcExchange) Stop() {
+	g.stopOnce.Do(func() { close(g.stopped) })
+}
+
+// Register - Register the ExchangeService on a gRPC server
+func (g *grpcExchange) Register(s *grpc.Server) {
+	exchangepb.RegisterExchangeServiceServer(s, g)
+}
+
+// GetRate - Get the exchange rate between from and to
+func (g *grpcExchange) GetRate(ctx context.Context, req *exchangepb.GetRateRequest) (*exchangepb.Rate, error) {
+	return g.getRate(req.GetFrom(), req.GetTo())
+}
+
+// Convert - Convert an amount from one currency to another
+func (g *grpcExchange) Convert(ctx context.Context, req *exchangepb.ConvertRequest) (*exchangepb.ConvertResponse, error) {
+	if req.GetAmount() < 0 {
+		return nil, newStatus(codes.InvalidArgument, api.ErrorCodeInvalidQuery, "amount must not be negative")
+	}
+
+	rate, err := g.getRate(req.GetFrom(), req.GetTo())
+	if err != nil {
+		return nil, err
+	}
+
+	return &exchangepb.ConvertResponse{Rate: rate,
+		Amount:    req.GetAmount(),
+		Converted: req.GetAmount() * float64(rate.GetSingleUnit())}, nil
+}
+
+// GetHistory - Get the exchange rate for each of the previous
+//				days, most recent first
+func (g *grpcExchange) GetHistory(ctx context.Context, req *exchangepb.GetHistoryRequest) (*exchangepb.GetHistoryResponse, error) {
+	if err := service.ValidatePair(g.validCurrencies, req.GetFrom(), req.GetTo()); err != nil {
+		return nil, toStatus(err)
+	}
+
+	if req.GetDays() < 1 || req.GetDays() > MaxHistoryDays {
+		return nil, newStatus(codes.InvalidArgument, api.ErrorCodeInvalidQuery, fmt.Sprintf("days must be between 1 and %d", MaxHistoryDays))
+	}
+
+	now := g.clock.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	rates := []*exchangepb.Rate{}
+	for i := 1; i <= int(req.GetDays()); i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, toStatus(err)
+		}
+
+		resp, err := g.exchangeService.PerformHistoricalRequest(req.GetFrom(), req.GetTo(), today.AddDate(0, 0, -i))
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		rates = append(rates, toRate(req.GetFrom(), req.GetTo(), resp))
+	}
+
+	return &exchangepb.GetHistoryResponse{Rates: rates}, nil
+}
+
+// StreamRates - Send the current rate of every pair, then send a
+//				 pair's rate again whenever a new one is stored.
+//				 Streams do not refresh rates themselves, so an
+//				 idle stream never calls the provider.
+func (g *grpcExchange) StreamRates(req *exchangepb.StreamRatesRequest, stream exchangepb.ExchangeService_StreamRatesServer) error {
+	pairs := req.GetPairs()
+	if len(pairs) == 0 || len(pairs) > MaxStreamPairs {
+		return newStatus(codes.InvalidArgument, api.ErrorCodeInvalidQuery, fmt.Sprintf("between 1 and %d pairs are required", MaxStreamPairs))
+	}
+
+	keys := []string{}
+	for _, p := range pairs {
+		if err := service.ValidatePair(g.validCurrencies, p.GetFrom(), p.GetTo()); err != nil {
+			return toStatus(err)
+		}
+		keys = append(keys, p.GetFrom()+p.GetTo())
+	}
+
+	sub := g.broker.Subscribe(keys, streamBuffer)
+	defer g.broker.Unsubscribe(sub)
+
+	sent := make(map[string]time.Time)
+	pending, err := g.sendCurrent(stream, pairs, sent)
+	if err != nil {
+		return err
+	}
+
+	// The retry is stopped when the stream ends so that it is not
+	// left waiting on the clock
+	var retry util.Timer
+	defer func() {
+		if retry != nil {
+			retry.Stop()
+		}
+	}()
+	for {
+		var retryC <-chan time.Time
+		if retry == nil && len(pending) > 0 {
+			retry = g.clock.NewTimer(g.retryInterval)
+		}
+		if retry != nil {
+			retryC = retry.C()
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-g.stopped:
+			return newStatus(codes.Unavailable, api.ErrorCodeUnavailable, "server is shutting down")
+		case u, open := <-sub.C:
+			if !open {
+				return nil
+			}
+			key := u.From + u.To
+			if !u.DataDateTime.After(sent[key]) {
+				continue
+			}
+			resp := &service.ExchangeRateServiceResponse{OneUnit: u.OneUnit, ShouldExchange: u.ShouldExchange, DataDateTime: u.DataDateTime}
+			if err := stream.Send(toRate(u.From, u.To, resp)); err != nil {
+				return err
+			}
+			sent[key] = u.DataDateTime
+		case <-retryC:
+			retry = nil
+			if pending, err = g.sendCurrent(stream, pending, sent); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// sendCurrent - Send the current rate of every pair that has not
+//				 been sent, returning the pairs that could not be
+//				 served so that they are tried again rather than
+//				 ending the stream
+func (g *grpcExchange) sendCurrent(stream exchangepb.ExchangeService_StreamRatesServer, pairs []*exchangepb.Pair, sent map[string]time.Time) ([]*exchangepb.Pair, error) {
+	pending := []*exchangepb.Pair{}
+	for _, p := range pairs {
+		key := p.GetFrom() + p.GetTo()
+		if _, exists := sent[key]; exists {
+			continue
+		}
+
+		resp, err := g.exchangeService.PerformRequest(p.GetFrom(), p.GetTo())
+		if err != nil {
+			pending = append(pending, p)
+			continue
+		}
+
+		if err := stream.Send(toRate(p.GetFrom(), p.GetTo(), resp)); err != nil {
+			return nil, err
+		}
+		sent[key] = resp.DataDateTime
+	}
+	return pending, nil
+}
+
+func (g *grpcExchange) getRate(from, to string) (*exchangepb.Rate, error) {
+	if err := service.ValidatePair(g.validCurrencies, from, to); err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp, err := g.exchangeService.PerformRequest(from, to)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toRate(from, to, resp), nil
+}
+
+func toRate(from, to string, r *service.ExchangeRateServiceResponse) *exchangepb.Rate {
+	return &exchangepb.Rate{From: from,
+		To:             to,
+		SingleUnit:     r.OneUnit,
+		ShouldExchange: r.ShouldExchange,
+		DataDateTime:   timestamppb.New(r.DataDateTime)}
+}
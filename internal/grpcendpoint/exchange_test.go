@@ -0,0 +1,278 @@
+package grpcendpoint_test
+
+import (
+	"context"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/grpcendpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api/exchangepb"
+	"github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+func TestGrpcExchange(t *testing.T) {
+	t.Run("ensure rate is returned when successful", func(t *testing.T) {
+		// given
+		client, stop := givenGrpcClient(t, givenValidExchangeService())
+		defer stop()
+
+		// when
+		resp, err := client.GetRate(context.Background(), &exchangepb.GetRateRequest{From: "EUR", To: "GBP"})
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.GetFrom() == "EUR")
+		util.AssertTrue(t, resp.GetTo() == "GBP")
+		util.AssertEquals(t, 0.8, resp.GetSingleUnit())
+		util.AssertTrue(t, resp.GetShouldExchange())
+	})
+
+	t.Run("ensure invalid currencies are mapped to invalid argument", func(t *testing.T) {
+		// given
+		client, stop := givenGrpcClient(t, givenValidExchangeService())
+		defer stop()
+
+		// when
+		_, err := client.GetRate(context.Background(), &exchangepb.GetRateRequest{From: "EUR", To: "FOO"})
+
+		// then
+		assertStatus(t, err, codes.InvalidArgument, api.ErrorCodeInvalidQuery)
+		util.AssertTrue(t, status.Convert(err).Message() == "FOO is not a valid currency")
+	})
+
+	t.Run("ensure service errors are mapped to internal", func(t *testing.T) {
+		// given
+		client, stop := givenGrpcClient(t, &mockExchangeService{err: errors.New("Network service down")})
+		defer stop()
+
+		// when
+		_, err := client.GetRate(context.Background(), &exchangepb.GetRateRequest{From: "EUR", To: "GBP"})
+
+		// then
+		assertStatus(t, err, codes.Internal, api.ErrorCodeInternal)
+	})
+
+	t.Run("ensure amount is converted", func(t *testing.T) {
+		// given
+		client, stop := givenGrpcClient(t, givenValidExchangeService())
+		defer stop()
+
+		// when
+		resp, err := client.Convert(context.Background(), &exchangepb.ConvertRequest{From: "EUR", To: "GBP", Amount: 10})
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 8, float32(resp.GetConverted()))
+		util.AssertEquals(t, 0.8, resp.GetRate().GetSingleUnit())
+	})
+
+	t.Run("ensure history has a rate for every day", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		client, stop := givenGrpcClient(t, eService)
+		defer stop()
+
+		// when
+		resp, err := client.GetHistory(context.Background(), &exchangepb.GetHistoryRequest{From: "EUR", To: "GBP", Days: 3})
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(resp.GetRates()) == 3)
+		util.AssertTrue(t, len(eService.dates) == 3)
+		util.AssertTrue(t, eService.dates[0].Equal(time.Date(2019, 10, 13, 0, 0, 0, 0, time.UTC)))
+		util.AssertTrue(t, eService.dates[0].After(eService.dates[1]))
+	})
+
+	t.Run("ensure history rejects too many days", func(t *testing.T) {
+		// given
+		client, stop := givenGrpcClient(t, givenValidExchangeService())
+		defer stop()
+
+		// when
+		_, err := client.GetHistory(context.Background(), &exchangepb.GetHistoryRequest{From: "EUR", To: "GBP", Days: 32})
+
+		// then
+		assertStatus(t, err, codes.InvalidArgument, api.ErrorCodeInvalidQuery)
+	})
+
+	t.Run("ensure stream sends current and stored rates", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		broker := pubsub.CreateNewBroker()
+		client, stop := givenGrpcServer(t, eService, broker, util.CreateNewFakeClock(givenNow()))
+		defer stop()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// when
+		stream, err := client.StreamRates(ctx, &exchangepb.StreamRatesRequest{Pairs: []*exchangepb.Pair{{From: "EUR", To: "GBP"}}})
+		util.AssertErrorNil(t, err)
+		first, err := stream.Recv()
+		util.AssertErrorNil(t, err)
+		broker.Publish(pubsub.RateUpdate{From: "EUR", To: "USD", OneUnit: 1.1, DataDateTime: givenNow().Add(time.Second)})
+		broker.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: 0.9, DataDateTime: givenNow().Add(time.Second)})
+		second, err := stream.Recv()
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, first.GetSingleUnit())
+		util.AssertEquals(t, 0.9, second.GetSingleUnit())
+		util.AssertTrue(t, second.GetTo() == "GBP")
+		util.AssertTrue(t, eService.requests() == 1)
+	})
+
+	t.Run("ensure an idle stream does not request rates", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		clock := util.CreateNewFakeClock(givenNow())
+		client, stop := givenGrpcServer(t, eService, pubsub.CreateNewBroker(), clock)
+		defer stop()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, _ := client.StreamRates(ctx, &exchangepb.StreamRatesRequest{Pairs: []*exchangepb.Pair{{From: "EUR", To: "GBP"}}})
+		stream.Recv()
+
+		// when
+		clock.Advance(time.Minute)
+
+		// then
+		util.AssertTrue(t, clock.Timers() == 0)
+		util.AssertTrue(t, eService.requests() == 1)
+	})
+
+	t.Run("ensure stream retries pairs without a rate on the clock", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		eService.failures = 1
+		clock := util.CreateNewFakeClock(givenNow())
+		client, stop := givenGrpcServer(t, eService, pubsub.CreateNewBroker(), clock)
+		defer stop()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		stream, _ := client.StreamRates(ctx, &exchangepb.StreamRatesRequest{Pairs: []*exchangepb.Pair{{From: "EUR", To: "GBP"}}})
+		clock.BlockUntil(1)
+
+		// when
+		clock.Advance(time.Second)
+		rate, err := stream.Recv()
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, rate.GetSingleUnit())
+		util.AssertTrue(t, eService.requests() == 2)
+	})
+}
+
+type mockExchangeService struct {
+	resp     chan *service.ExchangeRateServiceResponse
+	err      error
+	mu       sync.Mutex
+	dates    []time.Time
+	calls    int
+	failures int
+}
+
+func (m *mockExchangeService) requests() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.calls
+}
+
+func (m *mockExchangeService) PerformRequest(from, to string) (*service.ExchangeRateServiceResponse, error) {
+	m.mu.Lock()
+	m.calls++
+	failed := m.failures > 0
+	m.failures--
+	m.mu.Unlock()
+
+	if m.err != nil {
+		return nil, m.err
+	}
+	if failed {
+		return nil, errors.New("Network service down")
+	}
+	resp := <-m.resp
+	m.resp <- resp
+	return resp, nil
+}
+
+func (m *mockExchangeService) PerformBatchRequest(from string, to []string) []*service.ExchangeRateBatchResponse {
+	return nil
+}
+
+func (m *mockExchangeService) PerformHistoricalRequest(from, to string, date time.Time) (*service.ExchangeRateServiceResponse, error) {
+	m.mu.Lock()
+	m.dates = append(m.dates, date)
+	m.mu.Unlock()
+	return m.PerformRequest(from, to)
+}
+
+func givenNow() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
+
+func givenValidExchangeService() *mockExchangeService {
+	m := &mockExchangeService{resp: make(chan *service.ExchangeRateServiceResponse, 1)}
+	m.resp <- &service.ExchangeRateServiceResponse{OneUnit: 0.8, ShouldExchange: true, DataDateTime: givenNow()}
+	return m
+}
+
+// givenGrpcClient - Starts the gRPC server on an in-process
+//					 listener and connects a client to it
+func givenGrpcClient(t *testing.T, eService service.ExchangeRateService) (exchangepb.ExchangeServiceClient, func()) {
+	t.Helper()
+
+	return givenGrpcServer(t, eService, pubsub.CreateNewBroker(), util.CreateNewFakeClock(givenNow()))
+}
+
+// givenGrpcServer - givenGrpcClient with the broker, the clock and
+//					 the options of the server
+func givenGrpcServer(t *testing.T, eService service.ExchangeRateService, broker *pubsub.Broker, clock util.Clock, options ...grpc.ServerOption) (exchangepb.ExchangeServiceClient, func()) {
+	t.Helper()
+
+	lis := bufconn.Listen(1024 * 1024)
+	server := grpc.NewServer(options...)
+	grpcendpoint.CreateNewGrpcExchange(eService, broker, map[string]bool{"EUR": true, "USD": true, "GBP": true}, clock, time.Second).Register(server)
+	go server.Serve(lis)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("cannot connect to gRPC server: %s", err)
+	}
+
+	return exchangepb.NewExchangeServiceClient(conn), func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+func assertStatus(t *testing.T, err error, expected codes.Code, expectedReason string) {
+	t.Helper()
+
+	s := status.Convert(err)
+	if s.Code() != expected {
+		t.Fatalf("expected code '%s' but actual is '%s'", expected, s.Code())
+	}
+
+	for _, d := range s.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok && info.Reason == expectedReason {
+			return
+		}
+	}
+	t.Fatalf("expected error info with reason '%s'", expectedReason)
+}
@@ -0,0 +1,114 @@
+package grpcendpoint
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// grpcMethod - The HTTP method that route limits of gRPC calls are
+//				matched with
+const grpcMethod = "POST"
+
+// RateLimiter - Limits calls by client IP, see service
+//				 CreateNewRateLimiter
+type RateLimiter interface {
+	Allow(client service.Client, method, path string) service.RateLimitResult
+}
+
+// Authorizer - Authenticates calls with API keys, see auth
+//				CreateNewAuthenticator
+type Authorizer interface {
+	Authorize(path, apiKey string) *auth.Decision
+}
+
+type guard struct {
+	limiter    RateLimiter
+	authorizer Authorizer
+}
+
+// CreateNewGuard - Holds gRPC calls to the same limits and API keys
+//					as the HTTP server, in the same order. The full
+//					method such as `/exchange.v1.ExchangeService/GetRate`
+//					is the path, and the API key is read from the
+//					`x-api-key` metadata. Either can be nil.
+func CreateNewGuard(limiter RateLimiter, authorizer Authorizer) *guard {
+	return &guard{limiter: limiter, authorizer: authorizer}
+}
+
+// Options - The interceptors of the guard for grpc.NewServer
+func (g *guard) Options() []grpc.ServerOption {
+	return []grpc.ServerOption{grpc.ChainUnaryInterceptor(g.unary), grpc.ChainStreamInterceptor(g.stream)}
+}
+
+func (g *guard) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	if err := g.check(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+func (g *guard) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := g.check(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
+
+func (g *guard) check(ctx context.Context, method string) error {
+	if g.limiter != nil {
+		if result := g.limiter.Allow(service.Client{IP: peerIP(ctx)}, grpcMethod, method); !result.Allowed {
+			return newRetryStatus(codes.ResourceExhausted, api.ErrorCodeRateLimited, result.Message, result.RetryAfter)
+		}
+	}
+
+	if g.authorizer != nil {
+		if d := g.authorizer.Authorize(method, apiKey(ctx)); !d.Allowed() {
+			return newRetryStatus(authCode(d.Status), d.Code, d.Message, d.RetryAfter)
+		}
+	}
+	return nil
+}
+
+// authCode - The gRPC code of the HTTP status of a denied call
+func authCode(status int) codes.Code {
+	switch status {
+	case 401:
+		return codes.Unauthenticated
+	case 403:
+		return codes.PermissionDenied
+	case 429:
+		return codes.ResourceExhausted
+	default:
+		return codes.Internal
+	}
+}
+
+func apiKey(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get(strings.ToLower(auth.KeyHeader)); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// peerIP - The IP of the client, nil when it is not connected
+//			over TCP
+func peerIP(ctx context.Context) net.IP {
+	p, ok := peer.FromContext(ctx)
+	if !ok {
+		return nil
+	}
+	if addr, ok := p.Addr.(*net.TCPAddr); ok {
+		return addr.IP
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package grpcendpoint_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/grpcendpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api/exchangepb"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestGuard(t *testing.T) {
+	tests := []struct {
+		name   string
+		key    string
+		code   codes.Code
+		reason string
+	}{
+		{"ensure a missing key is unauthenticated", "", codes.Unauthenticated, api.ErrorCodeUnauthorized},
+		{"ensure an unknown key is unauthenticated", "unknown", codes.Unauthenticated, api.ErrorCodeUnauthorized},
+		{"ensure a key without the scope is denied", "alerts-secret", codes.PermissionDenied, api.ErrorCodeForbidden},
+		{"ensure a key with the scope is allowed", "reader-secret", codes.OK, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// given
+			client, stop := givenGuardedGrpcClient(t, nil)
+			defer stop()
+
+			// when
+			_, err := client.GetRate(givenKeyContext(tt.key), &exchangepb.GetRateRequest{From: "EUR", To: "GBP"})
+
+			// then
+			if tt.code == codes.OK {
+				util.AssertErrorNil(t, err)
+				return
+			}
+			assertStatus(t, err, tt.code, tt.reason)
+		})
+	}
+
+	t.Run("ensure streams need a key", func(t *testing.T) {
+		// given
+		client, stop := givenGuardedGrpcClient(t, nil)
+		defer stop()
+
+		// when
+		stream, _ := client.StreamRates(context.Background(), &exchangepb.StreamRatesRequest{Pairs: []*exchangepb.Pair{{From: "EUR", To: "GBP"}}})
+		_, err := stream.Recv()
+
+		// then
+		assertStatus(t, err, codes.Unauthenticated, api.ErrorCodeUnauthorized)
+	})
+
+	t.Run("ensure calls over the rate limit are exhausted with a retry delay", func(t *testing.T) {
+		// given
+		limiter := service.CreateNewRateLimiter(service.RateLimitConfig{PerClient: service.Limit{Rate: 1, Burst: 1}}, util.CreateNewFakeClock(givenNow()))
+		client, stop := givenGuardedGrpcClient(t, limiter)
+		defer stop()
+		client.GetRate(givenKeyContext("reader-secret"), &exchangepb.GetRateRequest{From: "EUR", To: "GBP"})
+
+		// when
+		_, err := client.GetRate(givenKeyContext("reader-secret"), &exchangepb.GetRateRequest{From: "EUR", To: "GBP"})
+
+		// then
+		assertStatus(t, err, codes.ResourceExhausted, api.ErrorCodeRateLimited)
+		assertRetryDelay(t, err, time.Second)
+	})
+}
+
+func givenGuardedGrpcClient(t *testing.T, limiter grpcendpoint.RateLimiter) (exchangepb.ExchangeServiceClient, func()) {
+	t.Helper()
+
+	keys := []*auth.Key{
+		{ID: "reader", Key: "reader-secret", Scopes: []auth.Scope{auth.ScopeReadRates}},
+		{ID: "alerts", Key: "alerts-secret", Scopes: []auth.Scope{auth.ScopeManageAlerts}},
+	}
+	clock := util.CreateNewFakeClock(givenNow())
+	authenticator := auth.CreateNewAuthenticator(auth.CreateNewMemKeyStore(keys), nil, auth.ScopeReadRates, clock)
+	guard := grpcendpoint.CreateNewGuard(limiter, authenticator)
+	return givenGrpcServer(t, givenValidExchangeService(), pubsub.CreateNewBroker(), clock, guard.Options()...)
+}
+
+func givenKeyContext(key string) context.Context {
+	if key == "" {
+		return context.Background()
+	}
+	return metadata.AppendToOutgoingContext(context.Background(), "x-api-key", key)
+}
+
+func assertRetryDelay(t *testing.T, err error, expected time.Duration) {
+	t.Helper()
+
+	for _, d := range status.Convert(err).Details() {
+		if info, ok := d.(*errdetails.RetryInfo); ok && info.GetRetryDelay().AsDuration() == expected {
+			return
+		}
+	}
+	t.Fatalf("expected a retry delay of %s", expected)
+}
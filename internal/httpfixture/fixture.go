@@ -0,0 +1,90 @@
+package httpfixture
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Fixture - A stored response to a request. Requests match on
+//			 Method, Path and Query, whatever the host. Latency
+//			 delays the response, such as `250ms`, and Error is
+//			 returned instead of a response when it is set.
+type Fixture struct {
+	Method  string            `json:"method"`
+	Path    string            `json:"path"`
+	Query   string            `json:"query,omitempty"`
+	Status  int               `json:"status,omitempty"`
+	Header  map[string]string `json:"header,omitempty"`
+	Body    string            `json:"body,omitempty"`
+	Latency string            `json:"latency,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// LoadFixtures - Read fixtures from a JSON file containing a list
+//				  of fixtures
+func LoadFixtures(path string) ([]*Fixture, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot read fixtures from '%s'", path))
+	}
+
+	fixtures := []*Fixture{}
+	if err := json.Unmarshal(data, &fixtures); err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot unmarshall fixtures from '%s'", path))
+	}
+
+	for _, f := range fixtures {
+		if f.Method == "" || f.Path == "" {
+			return nil, errors.New(fmt.Sprintf("Every fixture in '%s' needs a method and a path", path))
+		}
+		if _, err := f.latency(); err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Fixture %s %s in '%s' has an invalid latency", f.Method, f.Path, path))
+		}
+	}
+
+	return fixtures, nil
+}
+
+// SaveFixtures - Write fixtures to a JSON file that LoadFixtures
+//				  can read
+func SaveFixtures(path string, fixtures []*Fixture) error {
+	// Queries are easier to read without & escaped
+	data := bytes.Buffer{}
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(fixtures); err != nil {
+		return errors.Wrap(err, "Cannot marshall fixtures")
+	}
+
+	if err := ioutil.WriteFile(path, data.Bytes(), 0644); err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Cannot write fixtures to '%s'", path))
+	}
+	return nil
+}
+
+func (f *Fixture) key() string {
+	return requestKey(f.Method, f.Path, f.Query)
+}
+
+func (f *Fixture) latency() (time.Duration, error) {
+	if f.Latency == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(f.Latency)
+}
+
+// requestKey - Query params are sorted so that their order does
+//				not matter
+func requestKey(method, path, query string) string {
+	if values, err := url.ParseQuery(query); err == nil {
+		query = values.Encode()
+	}
+	return method + " " + path + "?" + query
+}
@@ -0,0 +1,73 @@
+package httpfixture
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"sync"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/pkg/errors"
+)
+
+type recorder struct {
+	client   dao.HTTPClient
+	mu       sync.Mutex
+	fixtures []*Fixture
+}
+
+// CreateNewRecorder - Create a dao.HTTPClient that records every
+//					   response of client as a fixture, such as
+//					   the responses of a local stand-in server
+func CreateNewRecorder(client dao.HTTPClient) *recorder {
+	return &recorder{client: client}
+}
+
+// Do - Perform the request with the client and record it
+func (r *recorder) Do(req *http.Request) (*http.Response, error) {
+	f := &Fixture{Method: req.Method, Path: req.URL.Path, Query: req.URL.Query().Encode()}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		f.Error = err.Error()
+		r.add(f)
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, errors.Wrap(err, "Cannot read the body to record")
+	}
+
+	f.Status = resp.StatusCode
+	f.Body = string(body)
+	if contentType := resp.Header.Get("Content-Type"); contentType != "" {
+		f.Header = map[string]string{"Content-Type": contentType}
+	}
+	r.add(f)
+
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	return resp, nil
+}
+
+// Fixtures - Every recorded fixture, in the order the requests
+//			  were made
+func (r *recorder) Fixtures() []*Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Fixture{}, r.fixtures...)
+}
+
+// Save - Write the recorded fixtures, see SaveFixtures
+func (r *recorder) Save(path string) error {
+	return SaveFixtures(path, r.Fixtures())
+}
+
+func (r *recorder) add(f *Fixture) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.fixtures = append(r.fixtures, f)
+}
@@ -0,0 +1,95 @@
+package httpfixture_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/httpfixture"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestRecorder(t *testing.T) {
+	t.Run("ensure recorded fixtures replay the responses of the stand-in server", func(t *testing.T) {
+		// given
+		server := givenStandInServer(t)
+		recorder := httpfixture.CreateNewRecorder(server.Client())
+		recorded, _ := recorder.Do(givenRequest(t, server.URL+"/latest?base=EUR"))
+		missing, _ := recorder.Do(givenRequest(t, server.URL+"/missing"))
+		path := filepath.Join(t.TempDir(), "fixtures.json")
+
+		// when
+		err := recorder.Save(path)
+		fixtures, loadErr := httpfixture.LoadFixtures(path)
+		replayer := httpfixture.CreateNewReplayer(util.CreateNewClock(), fixtures...)
+		replayed, _ := replayer.Do(givenRequest(t, "http://provider.test/latest?base=EUR"))
+		replayedMissing, _ := replayer.Do(givenRequest(t, "http://provider.test/missing"))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertErrorNil(t, loadErr)
+		util.AssertTrue(t, len(fixtures) == 2)
+		util.AssertTrue(t, readBody(t, recorded) == `{"base":"EUR"}`)
+		util.AssertTrue(t, readBody(t, replayed) == `{"base":"EUR"}`)
+		util.AssertTrue(t, replayed.Header.Get("Content-Type") == "application/json")
+		util.AssertTrue(t, missing.StatusCode == 404)
+		util.AssertTrue(t, replayedMissing.StatusCode == 404)
+	})
+
+	t.Run("ensure transport errors are recorded", func(t *testing.T) {
+		// given
+		server := givenStandInServer(t)
+		url := server.URL
+		server.Close()
+		recorder := httpfixture.CreateNewRecorder(http.DefaultClient)
+
+		// when
+		_, err := recorder.Do(givenRequest(t, url+"/latest"))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertTrue(t, recorder.Fixtures()[0].Error == err.Error())
+	})
+}
+
+func TestLoadFixtures(t *testing.T) {
+	t.Run("ensure an error when the file is missing", func(t *testing.T) {
+		// when
+		_, err := httpfixture.LoadFixtures(filepath.Join(t.TempDir(), "missing.json"))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure an error when a fixture is invalid", func(t *testing.T) {
+		for _, f := range []*httpfixture.Fixture{
+			{Path: "/latest"},
+			{Method: "GET", Path: "/latest", Latency: "soon"},
+		} {
+			// given
+			path := filepath.Join(t.TempDir(), "fixtures.json")
+			httpfixture.SaveFixtures(path, []*httpfixture.Fixture{f})
+
+			// when
+			_, err := httpfixture.LoadFixtures(path)
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+}
+
+func givenStandInServer(t *testing.T) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/latest" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"base":"%s"}`, r.URL.Query().Get("base"))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
@@ -0,0 +1,127 @@
+package httpfixture
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// ErrNoFixture - Returned when no fixture matches a request
+var ErrNoFixture = errors.New("no fixture matches the request")
+
+type replayer struct {
+	clock    util.Clock
+	mu       sync.Mutex
+	fixtures map[string][]*Fixture
+	served   map[string]int
+	requests []*http.Request
+}
+
+// CreateNewReplayer - Create a dao.HTTPClient that serves the
+//					   fixtures. Fixtures that match the same
+//					   request are served in order, and the last
+//					   one is repeated. Latency waits on the clock.
+func CreateNewReplayer(clock util.Clock, fixtures ...*Fixture) *replayer {
+	r := &replayer{clock: clock,
+		fixtures: make(map[string][]*Fixture),
+		served:   make(map[string]int)}
+	for _, f := range fixtures {
+		r.fixtures[f.key()] = append(r.fixtures[f.key()], f)
+	}
+	return r
+}
+
+// Do - Serve the next fixture that matches the request
+func (r *replayer) Do(req *http.Request) (*http.Response, error) {
+	key := requestKey(req.Method, req.URL.Path, req.URL.RawQuery)
+	f := r.next(req, key)
+	if f == nil {
+		return nil, errors.Wrap(ErrNoFixture, key)
+	}
+
+	latency, err := f.latency()
+	if err != nil {
+		return nil, err
+	}
+	if latency > 0 {
+		timer := r.clock.NewTimer(latency)
+		select {
+		case <-timer.C():
+		case <-req.Context().Done():
+			timer.Stop()
+			return nil, req.Context().Err()
+		}
+	}
+
+	if f.Error != "" {
+		return nil, errors.New(f.Error)
+	}
+
+	return createResponse(req, f), nil
+}
+
+// Requests - Every request that has been made, in order
+func (r *replayer) Requests() []*http.Request {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*http.Request{}, r.requests...)
+}
+
+// Served - How many times a request has been served, which is
+//			how a test asserts on retries and caching
+func (r *replayer) Served(method, path, query string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.served[requestKey(method, path, query)]
+}
+
+func (r *replayer) next(req *http.Request, key string) *Fixture {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests = append(r.requests, req)
+	fixtures := r.fixtures[key]
+	if len(fixtures) == 0 {
+		return nil
+	}
+
+	i := r.served[key]
+	r.served[key]++
+	if i >= len(fixtures) {
+		i = len(fixtures) - 1
+	}
+	return fixtures[i]
+}
+
+// createResponse - A new body every time, so a fixture can be
+//					read by every call that it serves
+func createResponse(req *http.Request, f *Fixture) *http.Response {
+	status := f.Status
+	if status == 0 {
+		status = 200
+	}
+
+	header := http.Header{}
+	for k, v := range f.Header {
+		header.Set(k, v)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          ioutil.NopCloser(strings.NewReader(f.Body)),
+		ContentLength: int64(len(f.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,149 @@
+package httpfixture_test
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/httpfixture"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+func TestReplayer(t *testing.T) {
+	t.Run("ensure requests match on method, path and query whatever the host and query order", func(t *testing.T) {
+		// given
+		r := httpfixture.CreateNewReplayer(util.CreateNewClock(), givenFixture("/latest", "base=EUR&symbols=GBP", `{"base":"EUR"}`))
+
+		// when
+		resp, err := r.Do(givenRequest(t, "http://provider.test/latest?symbols=GBP&base=EUR"))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 200)
+		util.AssertTrue(t, readBody(t, resp) == `{"base":"EUR"}`)
+	})
+
+	t.Run("ensure the body can be read by every call", func(t *testing.T) {
+		// given
+		r := httpfixture.CreateNewReplayer(util.CreateNewClock(), givenFixture("/latest", "", "rates"))
+
+		// when
+		resp1, _ := r.Do(givenRequest(t, "http://provider.test/latest"))
+		resp2, _ := r.Do(givenRequest(t, "http://provider.test/latest"))
+
+		// then
+		util.AssertTrue(t, readBody(t, resp1) == "rates")
+		util.AssertTrue(t, readBody(t, resp2) == "rates")
+		util.AssertTrue(t, r.Served("GET", "/latest", "") == 2)
+		util.AssertTrue(t, len(r.Requests()) == 2)
+	})
+
+	t.Run("ensure fixtures of the same request are served in order and the last is repeated", func(t *testing.T) {
+		// given
+		failing := givenFixture("/latest", "", "")
+		failing.Status = 503
+		r := httpfixture.CreateNewReplayer(util.CreateNewClock(), failing, givenFixture("/latest", "", "rates"))
+
+		// when
+		resp1, _ := r.Do(givenRequest(t, "http://provider.test/latest"))
+		resp2, _ := r.Do(givenRequest(t, "http://provider.test/latest"))
+		resp3, _ := r.Do(givenRequest(t, "http://provider.test/latest"))
+
+		// then
+		util.AssertTrue(t, resp1.StatusCode == 503)
+		util.AssertTrue(t, resp2.StatusCode == 200)
+		util.AssertTrue(t, resp3.StatusCode == 200)
+	})
+
+	t.Run("ensure ErrNoFixture when nothing matches", func(t *testing.T) {
+		// given
+		r := httpfixture.CreateNewReplayer(util.CreateNewClock(), givenFixture("/latest", "base=EUR", "rates"))
+
+		// when
+		_, err := r.Do(givenRequest(t, "http://provider.test/latest?base=GBP"))
+
+		// then
+		util.AssertTrue(t, errors.Cause(err) == httpfixture.ErrNoFixture)
+	})
+
+	t.Run("ensure the error of a fixture is returned", func(t *testing.T) {
+		// given
+		f := givenFixture("/latest", "", "")
+		f.Error = "connection reset by peer"
+		r := httpfixture.CreateNewReplayer(util.CreateNewClock(), f)
+
+		// when
+		resp, err := r.Do(givenRequest(t, "http://provider.test/latest"))
+
+		// then
+		util.AssertTrue(t, resp == nil)
+		util.AssertTrue(t, err.Error() == "connection reset by peer")
+	})
+
+	t.Run("ensure the response waits for the latency on the clock", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+		f := givenFixture("/latest", "", "rates")
+		f.Latency = "2s"
+		r := httpfixture.CreateNewReplayer(clock, f)
+		done := make(chan error, 1)
+		go func() {
+			_, err := r.Do(givenRequest(t, "http://provider.test/latest"))
+			done <- err
+		}()
+
+		// when
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		waiting := len(done) == 0
+		clock.Advance(time.Second)
+
+		// then
+		util.AssertTrue(t, waiting)
+		util.AssertErrorNil(t, <-done)
+	})
+
+	t.Run("ensure a cancelled request stops waiting for the latency", func(t *testing.T) {
+		// given
+		f := givenFixture("/latest", "", "rates")
+		f.Latency = "1h"
+		r := httpfixture.CreateNewReplayer(util.CreateNewFakeClock(time.Now()), f)
+		ctx, cancel := context.WithCancel(context.Background())
+		req := givenRequest(t, "http://provider.test/latest").WithContext(ctx)
+		cancel()
+
+		// when
+		_, err := r.Do(req)
+
+		// then
+		util.AssertTrue(t, err == context.Canceled)
+	})
+}
+
+func givenFixture(path, query, body string) *httpfixture.Fixture {
+	return &httpfixture.Fixture{Method: "GET", Path: path, Query: query, Body: body}
+}
+
+func givenRequest(t *testing.T, url string) *http.Request {
+	t.Helper()
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		t.Fatalf("cannot create request: %s", err)
+	}
+	return req
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	t.Helper()
+
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatalf("cannot read body: %s", err)
+	}
+	return string(body)
+}
@@ -0,0 +1,51 @@
+package lease
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// unsafeFileChars - Replaced in names so that a lease is always a
+//					 file in the directory of the locker
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+type fileLocker struct {
+	dir string
+}
+
+// CreateNewFileLocker - Leases held as locks on files in dir, shared
+//						 by the processes of a host or of a shared
+//						 file system that supports them. A lease is
+//						 held until it is released or the process
+//						 exits, so ttl is not used.
+func CreateNewFileLocker(dir string) (*fileLocker, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "Cannot create the lock directory")
+	}
+	return &fileLocker{dir: dir}, nil
+}
+
+// TryAcquire - See Locker
+func (f *fileLocker) TryAcquire(name string, ttl time.Duration) (Lease, bool, error) {
+	path := filepath.Join(f.dir, unsafeFileChars.ReplaceAllString(name, "_")+".lock")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "Cannot open the lock file")
+	}
+
+	acquired, err := tryLockFile(file)
+	if err != nil || !acquired {
+		file.Close()
+		return nil, false, err
+	}
+
+	return createLease(func() error {
+		// Closing the file releases the lock. The file is kept
+		// as removing it could race with the next holder.
+		return file.Close()
+	}), true, nil
+}
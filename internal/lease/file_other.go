@@ -0,0 +1,14 @@
+//go:build !unix
+
+package lease
+
+import (
+	"os"
+
+	"github.com/pkg/errors"
+)
+
+// tryLockFile - File leases need flock
+func tryLockFile(file *os.File) (bool, error) {
+	return false, errors.New("File leases are not supported on this platform")
+}
@@ -0,0 +1,22 @@
+//go:build unix
+
+package lease
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// tryLockFile - Take an exclusive flock of file without waiting
+func tryLockFile(file *os.File) (bool, error) {
+	err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err == syscall.EWOULDBLOCK {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "Cannot lock the lock file")
+	}
+	return true, nil
+}
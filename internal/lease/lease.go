@@ -0,0 +1,52 @@
+package lease
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"sync"
+	"time"
+)
+
+// Locker - Grants leases on names so that a single holder, across
+//			every replica sharing the locker, has the lease of a
+//			name at a time. A lease ends when it is released and,
+//			for lockers that keep leases outside the holder, after
+//			ttl so that a crashed holder cannot keep it. File and
+//			SQL leases end with the process or the session of
+//			the holder instead of ttl.
+type Locker interface {
+	TryAcquire(name string, ttl time.Duration) (Lease, bool, error)
+}
+
+// Lease - A lease granted by a Locker. Releasing it more than once
+//		   has no effect.
+type Lease interface {
+	Release() error
+}
+
+type lease struct {
+	once    sync.Once
+	release func() error
+	err     error
+}
+
+func createLease(release func() error) *lease {
+	return &lease{release: release}
+}
+
+// Release - End the lease
+func (l *lease) Release() error {
+	l.once.Do(func() {
+		l.err = l.release()
+	})
+	return l.err
+}
+
+// createToken - Tells the leases of a name apart, so that a holder
+//				 whose lease has expired cannot release the lease
+//				 of the next holder
+func createToken() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,383 @@
+package lease_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/lease"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+func TestLocker(t *testing.T) {
+	for name, givenLockers := range map[string]func(t *testing.T) (lease.Locker, lease.Locker){
+		"memory": givenMemLockers,
+		"file":   givenFileLockers,
+		"redis":  givenRedisLockers,
+		"sql":    givenSQLLockers,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Run("ensure a held lease cannot be acquired", func(t *testing.T) {
+				// given
+				first, second := givenLockers(t)
+				held, _, _ := first.TryAcquire("EURGBP", time.Minute)
+				defer held.Release()
+
+				// when
+				_, acquired, err := second.TryAcquire("EURGBP", time.Minute)
+
+				// then
+				util.AssertErrorNil(t, err)
+				util.AssertFalse(t, acquired)
+			})
+
+			t.Run("ensure the leases of other names can be acquired", func(t *testing.T) {
+				// given
+				first, second := givenLockers(t)
+				held, _, _ := first.TryAcquire("EURGBP", time.Minute)
+				defer held.Release()
+
+				// when
+				other, acquired, err := second.TryAcquire("EURUSD", time.Minute)
+
+				// then
+				util.AssertErrorNil(t, err)
+				util.AssertTrue(t, acquired)
+				other.Release()
+			})
+
+			t.Run("ensure a released lease can be acquired", func(t *testing.T) {
+				// given
+				first, second := givenLockers(t)
+				held, _, _ := first.TryAcquire("EURGBP", time.Minute)
+
+				// when
+				errRelease := held.Release()
+				next, acquired, err := second.TryAcquire("EURGBP", time.Minute)
+
+				// then
+				util.AssertErrorNil(t, errRelease)
+				util.AssertErrorNil(t, err)
+				util.AssertTrue(t, acquired)
+				next.Release()
+			})
+
+			t.Run("ensure releasing twice does not release the next lease", func(t *testing.T) {
+				// given
+				first, second := givenLockers(t)
+				held, _, _ := first.TryAcquire("EURGBP", time.Minute)
+				held.Release()
+				next, _, _ := second.TryAcquire("EURGBP", time.Minute)
+				defer next.Release()
+
+				// when
+				held.Release()
+				_, acquired, _ := first.TryAcquire("EURGBP", time.Minute)
+
+				// then
+				util.AssertFalse(t, acquired)
+			})
+		})
+	}
+}
+
+func TestMemLocker(t *testing.T) {
+	t.Run("ensure a lease expires after its ttl", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		locker := lease.CreateNewMemLocker(clock)
+		expired, _, _ := locker.TryAcquire("EURGBP", time.Second)
+		clock.Advance(time.Second * 2)
+
+		// when
+		_, acquired, err := locker.TryAcquire("EURGBP", time.Second)
+		expired.Release()
+		_, acquiredAfterRelease, _ := locker.TryAcquire("EURGBP", time.Second)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, acquired)
+		util.AssertFalse(t, acquiredAfterRelease)
+	})
+}
+
+func TestFileLocker(t *testing.T) {
+	t.Run("ensure names cannot leave the directory", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		locker, _ := lease.CreateNewFileLocker(dir + "/locks")
+		other, _ := lease.CreateNewFileLocker(dir)
+
+		// when
+		held, acquired, err := locker.TryAcquire("../EURGBP", time.Minute)
+		_, acquiredOutside, _ := other.TryAcquire("EURGBP", time.Minute)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, acquired)
+		util.AssertTrue(t, acquiredOutside)
+		held.Release()
+	})
+}
+
+func TestRedisLocker(t *testing.T) {
+	t.Run("ensure a lease expires after its ttl", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		server := givenRedisServer(t, clock)
+		locker := lease.CreateNewRedisLocker(resp.CreateNewClient(server.Addr(), time.Second))
+		expired, _, _ := locker.TryAcquire("EURGBP", time.Second)
+		clock.Advance(time.Second * 2)
+
+		// when
+		_, acquired, err := locker.TryAcquire("EURGBP", time.Second)
+		expired.Release()
+		_, acquiredAfterRelease, _ := locker.TryAcquire("EURGBP", time.Second)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, acquired)
+		util.AssertFalse(t, acquiredAfterRelease)
+	})
+
+	t.Run("ensure an error when the server is down", func(t *testing.T) {
+		// given
+		server := givenRedisServer(t, util.CreateNewClock())
+		locker := lease.CreateNewRedisLocker(resp.CreateNewClient(server.Addr(), time.Second))
+		server.SetDown(true)
+
+		// when
+		_, acquired, err := locker.TryAcquire("EURGBP", time.Second)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertFalse(t, acquired)
+	})
+}
+
+func TestSQLLocker(t *testing.T) {
+	t.Run("ensure the lock of the session is dropped when unlocking fails", func(t *testing.T) {
+		// given
+		database := &fakeLockDatabase{held: make(map[interface{}]*fakeLockConn)}
+		db := sql.OpenDB(database)
+		t.Cleanup(func() { db.Close() })
+		locker := lease.CreateNewSQLLocker(db, lease.PostgresAdvisoryLocks, time.Second)
+		held, _, _ := locker.TryAcquire("EURGBP", time.Minute)
+		database.setFailUnlock(true)
+
+		// when
+		err := held.Release()
+		_, acquired, errAcquire := locker.TryAcquire("EURGBP", time.Minute)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertErrorNil(t, errAcquire)
+		util.AssertTrue(t, acquired)
+	})
+
+	t.Run("ensure MySQL named locks are taken by name", func(t *testing.T) {
+		// given
+		database := &fakeLockDatabase{held: make(map[interface{}]*fakeLockConn)}
+		db := sql.OpenDB(database)
+		t.Cleanup(func() { db.Close() })
+		locker := lease.CreateNewSQLLocker(db, lease.MySQLNamedLocks, time.Second)
+
+		// when
+		held, acquired, err := locker.TryAcquire("EURGBP", time.Minute)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, acquired)
+		util.AssertTrue(t, database.isHeld("EURGBP"))
+		held.Release()
+		util.AssertFalse(t, database.isHeld("EURGBP"))
+	})
+}
+
+func givenMemLockers(t *testing.T) (lease.Locker, lease.Locker) {
+	locker := lease.CreateNewMemLocker(util.CreateNewClock())
+	return locker, locker
+}
+
+func givenFileLockers(t *testing.T) (lease.Locker, lease.Locker) {
+	// Locks of the same file opened twice conflict, even in a
+	// single process
+	dir := t.TempDir()
+	first, err := lease.CreateNewFileLocker(dir)
+	if err != nil {
+		t.Fatalf("cannot create the file locker: %s", err)
+	}
+	second, _ := lease.CreateNewFileLocker(dir)
+	return first, second
+}
+
+func givenRedisLockers(t *testing.T) (lease.Locker, lease.Locker) {
+	server := givenRedisServer(t, util.CreateNewClock())
+	return lease.CreateNewRedisLocker(resp.CreateNewClient(server.Addr(), time.Second)),
+		lease.CreateNewRedisLocker(resp.CreateNewClient(server.Addr(), time.Second))
+}
+
+func givenSQLLockers(t *testing.T) (lease.Locker, lease.Locker) {
+	db := sql.OpenDB(&fakeLockDatabase{held: make(map[interface{}]*fakeLockConn)})
+	t.Cleanup(func() { db.Close() })
+	return lease.CreateNewSQLLocker(db, lease.PostgresAdvisoryLocks, time.Second),
+		lease.CreateNewSQLLocker(db, lease.PostgresAdvisoryLocks, time.Second)
+}
+
+type redisServer interface {
+	Addr() string
+	SetDown(bool)
+	Close() error
+}
+
+func givenRedisServer(t *testing.T, clock util.Clock) redisServer {
+	server, err := resp.StartServer("127.0.0.1:0", clock)
+	if err != nil {
+		t.Fatalf("cannot start the server: %s", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+func givenNow() time.Time {
+	return time.Date(2020, 12, 7, 10, 0, 0, 0, time.UTC)
+}
+
+// fakeLockDatabase - A database/sql driver answering the queries
+//					  of PostgresAdvisoryLocks and MySQLNamedLocks,
+//					  where every connection is a session whose
+//					  locks are dropped when it is closed
+type fakeLockDatabase struct {
+	mu         sync.Mutex
+	held       map[interface{}]*fakeLockConn
+	failUnlock bool
+}
+
+func (d *fakeLockDatabase) Connect(context.Context) (driver.Conn, error) {
+	return &fakeLockConn{database: d}, nil
+}
+
+func (d *fakeLockDatabase) Driver() driver.Driver {
+	return nil
+}
+
+func (d *fakeLockDatabase) setFailUnlock(fail bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.failUnlock = fail
+}
+
+func (d *fakeLockDatabase) isHeld(key interface{}) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	_, exists := d.held[key]
+	return exists
+}
+
+func (d *fakeLockDatabase) query(c *fakeLockConn, query string, key interface{}) (bool, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch query {
+	case lease.PostgresAdvisoryLocks.TryLock, lease.MySQLNamedLocks.TryLock:
+		if holder, exists := d.held[key]; exists && holder != c {
+			return false, nil
+		}
+		d.held[key] = c
+		return true, nil
+	case lease.PostgresAdvisoryLocks.Unlock, lease.MySQLNamedLocks.Unlock:
+		if d.failUnlock {
+			return false, errors.New("connection reset")
+		}
+		if d.held[key] != c {
+			return false, nil
+		}
+		delete(d.held, key)
+		return true, nil
+	default:
+		return false, errors.New("unknown query")
+	}
+}
+
+func (d *fakeLockDatabase) endSession(c *fakeLockConn) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for key, holder := range d.held {
+		if holder == c {
+			delete(d.held, key)
+		}
+	}
+}
+
+type fakeLockConn struct {
+	database *fakeLockDatabase
+}
+
+func (c *fakeLockConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeLockStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeLockConn) Close() error {
+	c.database.endSession(c)
+	return nil
+}
+
+func (c *fakeLockConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("transactions are not supported")
+}
+
+type fakeLockStmt struct {
+	conn  *fakeLockConn
+	query string
+}
+
+func (s *fakeLockStmt) Close() error {
+	return nil
+}
+
+func (s *fakeLockStmt) NumInput() int {
+	return 1
+}
+
+func (s *fakeLockStmt) Exec(args []driver.Value) (driver.Result, error) {
+	return nil, errors.New("exec is not supported")
+}
+
+func (s *fakeLockStmt) Query(args []driver.Value) (driver.Rows, error) {
+	value, err := s.conn.database.query(s.conn, s.query, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return &fakeLockRows{value: value}, nil
+}
+
+type fakeLockRows struct {
+	value bool
+	done  bool
+}
+
+func (r *fakeLockRows) Columns() []string {
+	return []string{"locked"}
+}
+
+func (r *fakeLockRows) Close() error {
+	return nil
+}
+
+func (r *fakeLockRows) Next(dest []driver.Value) error {
+	if r.done {
+		return io.EOF
+	}
+	dest[0] = r.value
+	r.done = true
+	return nil
+}
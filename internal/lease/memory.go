@@ -0,0 +1,48 @@
+package lease
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+type memLease struct {
+	token     string
+	expiresAt time.Time
+}
+
+type memLocker struct {
+	clock  util.Clock
+	mu     sync.Mutex
+	leases map[string]memLease
+}
+
+// CreateNewMemLocker - Leases held in memory, which are only shared
+//						by a single process
+func CreateNewMemLocker(clock util.Clock) *memLocker {
+	return &memLocker{clock: clock, leases: make(map[string]memLease)}
+}
+
+// TryAcquire - See Locker
+func (m *memLocker) TryAcquire(name string, ttl time.Duration) (Lease, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := m.clock.Now()
+	if held, exists := m.leases[name]; exists && now.Before(held.expiresAt) {
+		return nil, false, nil
+	}
+
+	token := createToken()
+	m.leases[name] = memLease{token: token, expiresAt: now.Add(ttl)}
+	return createLease(func() error {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		if m.leases[name].token == token {
+			delete(m.leases, name)
+		}
+		return nil
+	}), true, nil
+}
@@ -0,0 +1,53 @@
+package lease
+
+import (
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/pkg/errors"
+)
+
+// RedisPrefix - Prefix of the keys of leases
+const RedisPrefix = "exchange:lease:"
+
+type redisLocker struct {
+	client resp.Client
+}
+
+// CreateNewRedisLocker - Leases held as keys of a Redis protocol
+//						  server, shared by every replica using it.
+//						  Leases expire after ttl.
+func CreateNewRedisLocker(client resp.Client) *redisLocker {
+	return &redisLocker{client: client}
+}
+
+// TryAcquire - See Locker
+func (r *redisLocker) TryAcquire(name string, ttl time.Duration) (Lease, bool, error) {
+	key := RedisPrefix + name
+	token := createToken()
+	acquired, err := r.client.SetNX(key, token, ttl)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "Cannot acquire the lease")
+	}
+	if !acquired {
+		return nil, false, nil
+	}
+
+	return createLease(func() error {
+		// Only deleted while it holds our token. The lease could
+		// expire between GET and DEL, which needs a holder to run
+		// for about ttl, so ttl should be well above the time a
+		// holder needs.
+		value, exists, err := r.client.Get(key)
+		if err != nil {
+			return errors.Wrap(err, "Cannot release the lease")
+		}
+		if !exists || value != token {
+			return nil
+		}
+		if _, err := r.client.Del(key); err != nil {
+			return errors.Wrap(err, "Cannot release the lease")
+		}
+		return nil
+	}), true, nil
+}
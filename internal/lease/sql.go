@@ -0,0 +1,101 @@
+package lease
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"hash/fnv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// SQLDialect - The queries that take a named lock of a database
+//				session without waiting, returning whether it was
+//				taken, and release it. Key turns the name of a lease
+//				into the argument of both.
+type SQLDialect struct {
+	TryLock string
+	Unlock  string
+	Key     func(name string) interface{}
+}
+
+// PostgresAdvisoryLocks - Session level advisory locks of PostgreSQL,
+//						   keyed by a 64 bit hash of the name
+var PostgresAdvisoryLocks = SQLDialect{
+	TryLock: "SELECT pg_try_advisory_lock($1)",
+	Unlock:  "SELECT pg_advisory_unlock($1)",
+	Key:     advisoryKey,
+}
+
+// MySQLNamedLocks - Named locks of MySQL
+var MySQLNamedLocks = SQLDialect{
+	TryLock: "SELECT GET_LOCK(?, 0) = 1",
+	Unlock:  "SELECT RELEASE_LOCK(?)",
+	Key:     func(name string) interface{} { return name },
+}
+
+type sqlLocker struct {
+	db      *sql.DB
+	dialect SQLDialect
+	timeout time.Duration
+}
+
+// CreateNewSQLLocker - Leases held as locks of database sessions,
+//						shared by every replica using the database.
+//						Every lease holds a connection of db until
+//						it is released, and ends with its session
+//						rather than after ttl. Queries time out
+//						after timeout. db needs a driver of the
+//						database, which is not part of this module.
+func CreateNewSQLLocker(db *sql.DB, dialect SQLDialect, timeout time.Duration) *sqlLocker {
+	return &sqlLocker{db: db, dialect: dialect, timeout: timeout}
+}
+
+// TryAcquire - See Locker
+func (s *sqlLocker) TryAcquire(name string, ttl time.Duration) (Lease, bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+	defer cancel()
+
+	conn, err := s.db.Conn(ctx)
+	if err != nil {
+		return nil, false, errors.Wrap(err, "Cannot get a connection for the lease")
+	}
+
+	key := s.dialect.Key(name)
+	taken := sql.NullBool{}
+	if err := conn.QueryRowContext(ctx, s.dialect.TryLock, key).Scan(&taken); err != nil {
+		discard(conn)
+		return nil, false, errors.Wrap(err, "Cannot acquire the lease")
+	}
+	if !taken.Valid || !taken.Bool {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	return createLease(func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), s.timeout)
+		defer cancel()
+
+		released := sql.NullBool{}
+		if err := conn.QueryRowContext(ctx, s.dialect.Unlock, key).Scan(&released); err != nil {
+			// The session would keep the lock if it went back
+			// into the pool
+			discard(conn)
+			return errors.Wrap(err, "Cannot release the lease")
+		}
+		return conn.Close()
+	}), true, nil
+}
+
+// discard - Close the connection instead of returning it to the pool
+func discard(conn *sql.Conn) {
+	conn.Raw(func(interface{}) error { return driver.ErrBadConn })
+	conn.Close()
+}
+
+func advisoryKey(name string) interface{} {
+	h := fnv.New64a()
+	h.Write([]byte(name))
+	return int64(h.Sum64())
+}
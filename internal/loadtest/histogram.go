@@ -0,0 +1,111 @@
+package loadtest
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Buckets - Upper bounds of the latency histogram, the last
+//			 bucket holds everything slower
+var Buckets = []time.Duration{
+	time.Millisecond,
+	2 * time.Millisecond,
+	5 * time.Millisecond,
+	10 * time.Millisecond,
+	20 * time.Millisecond,
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	200 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2 * time.Second,
+	5 * time.Second,
+}
+
+// histogramWidth - Width of the bar of the fullest bucket
+const histogramWidth = 40
+
+// Histogram - Every latency of a load test
+type Histogram struct {
+	latencies []time.Duration
+	sorted    bool
+}
+
+// Add - Record a latency
+func (h *Histogram) Add(latency time.Duration) {
+	h.latencies = append(h.latencies, latency)
+	h.sorted = false
+}
+
+// Count - Number of latencies recorded
+func (h *Histogram) Count() int {
+	return len(h.latencies)
+}
+
+// Percentile - The latency that p percent of requests were at
+//				or under, zero when nothing was recorded
+func (h *Histogram) Percentile(p float64) time.Duration {
+	if len(h.latencies) == 0 {
+		return 0
+	}
+	h.sort()
+
+	i := int(float64(len(h.latencies))*p/100+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(h.latencies) {
+		i = len(h.latencies) - 1
+	}
+	return h.latencies[i]
+}
+
+// Max - The slowest latency
+func (h *Histogram) Max() time.Duration {
+	return h.Percentile(100)
+}
+
+// Counts - How many latencies fall in each of Buckets, and the
+//			last one for those slower than every bucket
+func (h *Histogram) Counts() []int {
+	counts := make([]int, len(Buckets)+1)
+	for _, l := range h.latencies {
+		i := sort.Search(len(Buckets), func(i int) bool { return l <= Buckets[i] })
+		counts[i]++
+	}
+	return counts
+}
+
+// Write - Print the counts as bars, skipping empty buckets
+func (h *Histogram) Write(w io.Writer) {
+	counts := h.Counts()
+	fullest := 0
+	for _, c := range counts {
+		if c > fullest {
+			fullest = c
+		}
+	}
+
+	for i, c := range counts {
+		if c == 0 {
+			continue
+		}
+		label := "> " + Buckets[len(Buckets)-1].String()
+		if i < len(Buckets) {
+			label = "<= " + Buckets[i].String()
+		}
+		bar := strings.Repeat("#", (c*histogramWidth+fullest-1)/fullest)
+		fmt.Fprintf(w, "  %-8s %-*s %d\n", label, histogramWidth, bar, c)
+	}
+}
+
+func (h *Histogram) sort() {
+	if h.sorted {
+		return
+	}
+	sort.Slice(h.latencies, func(i, j int) bool { return h.latencies[i] < h.latencies[j] })
+	h.sorted = true
+}
@@ -0,0 +1,77 @@
+package loadtest
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// readyTimeout - How long the in process server is given to start
+const readyTimeout = 5 * time.Second
+
+type inProcess struct {
+	URL      string
+	cancel   context.CancelFunc
+	stopped  chan error
+	upstream *http.Server
+}
+
+// StartInProcess - Start the exchange server, composed like
+//					`cmd/exchange-server` but without rate limits,
+//					against a fake provider with providerConfig.
+//					Both listen on ephemeral ports and run on the
+//					real clock until Stop.
+func StartInProcess(config app.Config, providerConfig fakeprovider.Config) (*inProcess, error) {
+	clock := util.CreateNewClock()
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, errors.Wrap(err, "Fake provider listen")
+	}
+	upstream := &http.Server{Handler: fakeprovider.CreateNewProvider(providerConfig, clock)}
+	go upstream.Serve(lis)
+
+	config.ProviderURL = "http://" + lis.Addr().String()
+	config.ClientRate = 0
+	config.GlobalRate = 0
+	config.Listen = []string{"tcp://127.0.0.1:0"}
+	config.GRPCListen = ""
+	a, err := app.CreateNewApp(config, clock)
+	if err != nil {
+		upstream.Close()
+		return nil, err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &inProcess{cancel: cancel, stopped: make(chan error, 1), upstream: upstream}
+	go func() {
+		p.stopped <- a.Run(ctx)
+	}()
+
+	select {
+	case <-a.Ready():
+	case <-time.After(readyTimeout):
+		p.Stop()
+		return nil, errors.New("Server did not start in time")
+	}
+	if len(a.Addrs()) == 0 {
+		upstream.Close()
+		return nil, errors.Wrap(<-p.stopped, "Server did not start")
+	}
+
+	p.URL = "http://" + a.Addrs()[0].String()
+	return p, nil
+}
+
+// Stop - Stop the server and then the fake provider
+func (p *inProcess) Stop() error {
+	p.cancel()
+	err := <-p.stopped
+	p.upstream.Close()
+	return err
+}
@@ -0,0 +1,233 @@
+package loadtest_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/app"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/loadtest"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/gin-gonic/gin"
+)
+
+func TestParseMix(t *testing.T) {
+	t.Run("ensure pairs, paths and weights are parsed", func(t *testing.T) {
+		// given
+		specs := "EUR/GBP*3, /v2/exchange?from=EUR&to=USD,GBP/USD"
+
+		// when
+		mix, err := loadtest.ParseMix(specs)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(mix) == 3)
+		util.AssertTrue(t, mix[0].Path == "/v1/exchange?from=EUR&to=GBP")
+		util.AssertTrue(t, mix[0].Weight == 3)
+		util.AssertTrue(t, mix[1].Path == "/v2/exchange?from=EUR&to=USD")
+		util.AssertTrue(t, mix[1].Weight == 1)
+		util.AssertTrue(t, mix[2].Path == "/v1/exchange?from=GBP&to=USD")
+	})
+
+	t.Run("ensure an error for an invalid request", func(t *testing.T) {
+		for _, spec := range []string{"EUR", "EUR/", "EUR/GBP*0", "EUR/GBP*x"} {
+			// when
+			_, err := loadtest.ParseRequest(spec)
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+}
+
+func TestHistogram(t *testing.T) {
+	t.Run("ensure percentiles and bucket counts", func(t *testing.T) {
+		// given
+		h := loadtest.Histogram{}
+
+		// when
+		for i := 100; i > 0; i-- {
+			h.Add(time.Duration(i) * time.Millisecond)
+		}
+
+		// then
+		util.AssertTrue(t, h.Count() == 100)
+		util.AssertTrue(t, h.Percentile(50) == 50*time.Millisecond)
+		util.AssertTrue(t, h.Percentile(99) == 99*time.Millisecond)
+		util.AssertTrue(t, h.Max() == 100*time.Millisecond)
+		counts := h.Counts()
+		util.AssertTrue(t, counts[0] == 1)
+		util.AssertTrue(t, counts[5] == 30)
+		util.AssertTrue(t, counts[6] == 50)
+		util.AssertTrue(t, counts[len(counts)-1] == 0)
+	})
+
+	t.Run("ensure zero percentiles when nothing was recorded", func(t *testing.T) {
+		// given
+		h := loadtest.Histogram{}
+
+		// then
+		util.AssertTrue(t, h.Percentile(50) == 0)
+	})
+}
+
+func TestRunner(t *testing.T) {
+	t.Run("ensure an error for an invalid config", func(t *testing.T) {
+		// given
+		configs := []loadtest.Config{
+			{Mix: givenMix(), Workers: 1, Requests: 1},
+			{Target: "http://localhost", Workers: 1, Requests: 1},
+			{Target: "http://localhost", Mix: givenMix(), Requests: 1},
+			{Target: "http://localhost", Mix: givenMix(), Workers: 1},
+		}
+
+		for _, c := range configs {
+			// when
+			_, err := loadtest.CreateNewRunner(c, http.DefaultClient)
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+
+	t.Run("ensure the closed model sends exactly the number of requests", func(t *testing.T) {
+		// given
+		server, paths := givenTarget(t)
+		runner, _ := loadtest.CreateNewRunner(loadtest.Config{Target: server.URL,
+			Mix:      loadtest.Mix{{Path: "/ok", Weight: 3}, {Path: "/fail", Weight: 1}},
+			Workers:  4,
+			Requests: 200,
+			Seed:     1}, server.Client())
+
+		// when
+		report := runner.Run(context.Background())
+
+		// then
+		util.AssertTrue(t, report.Requests == 200)
+		util.AssertTrue(t, report.Latency.Count() == 200)
+		util.AssertTrue(t, report.Errors == paths["/fail"])
+		util.AssertTrue(t, report.Status[500] == paths["/fail"])
+		util.AssertTrue(t, report.Status[200] == paths["/ok"])
+		util.AssertTrue(t, paths["/ok"] > paths["/fail"])
+		util.AssertTrue(t, report.ErrorRate() > 0)
+	})
+
+	t.Run("ensure rates older than ValidFor are counted as stale", func(t *testing.T) {
+		// given
+		server, _ := givenTarget(t)
+		runner, _ := loadtest.CreateNewRunner(loadtest.Config{Target: server.URL,
+			Mix:      loadtest.Mix{{Path: "/ok", Weight: 1}, {Path: "/stale", Weight: 1}, {Path: "/stale-quote", Weight: 1}},
+			Workers:  2,
+			Requests: 90,
+			ValidFor: time.Minute}, server.Client())
+
+		// when
+		report := runner.Run(context.Background())
+
+		// then
+		util.AssertTrue(t, report.Errors == 0)
+		util.AssertTrue(t, report.Stale > 0)
+		util.AssertTrue(t, report.Stale < report.Requests)
+	})
+
+	t.Run("ensure the open model drops requests while every worker is busy", func(t *testing.T) {
+		// given
+		server, _ := givenTarget(t)
+		runner, _ := loadtest.CreateNewRunner(loadtest.Config{Target: server.URL,
+			Mix:      loadtest.Mix{{Path: "/slow", Weight: 1}},
+			Workers:  1,
+			Rate:     1000,
+			Requests: 20}, server.Client())
+
+		// when
+		report := runner.Run(context.Background())
+
+		// then
+		util.AssertTrue(t, report.Dropped > 0)
+		util.AssertTrue(t, report.Requests+report.Dropped == 20)
+	})
+
+	t.Run("ensure the report can be written", func(t *testing.T) {
+		// given
+		server, _ := givenTarget(t)
+		runner, _ := loadtest.CreateNewRunner(loadtest.Config{Target: server.URL,
+			Mix:      givenMix(),
+			Workers:  1,
+			Requests: 10}, server.Client())
+		report := runner.Run(context.Background())
+		out := &bytes.Buffer{}
+
+		// when
+		report.Write(out)
+
+		// then
+		util.AssertTrue(t, strings.Contains(out.String(), "Requests:  10 in"))
+		util.AssertTrue(t, strings.Contains(out.String(), "Status:    200=10"))
+	})
+}
+
+// BenchmarkInProcess - Requests to `/v1/exchange` of a server in
+//						this process against a fake provider, run
+//						with -benchtime to change the number
+func BenchmarkInProcess(b *testing.B) {
+	gin.DefaultWriter = ioutil.Discard
+	server, err := loadtest.StartInProcess(app.DefaultConfig(), fakeprovider.Config{Seed: 1})
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer server.Stop()
+	mix, _ := loadtest.ParseMix("EUR/GBP,EUR/USD,GBP/USD")
+	runner, _ := loadtest.CreateNewRunner(loadtest.Config{Target: server.URL,
+		Mix:      mix,
+		Workers:  10,
+		Requests: b.N,
+		Seed:     1}, &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: 10}})
+
+	b.ResetTimer()
+	report := runner.Run(context.Background())
+	b.StopTimer()
+
+	b.ReportMetric(float64(report.Latency.Percentile(99).Microseconds()), "p99-µs")
+	b.ReportMetric(report.ErrorRate(), "errors/op")
+}
+
+func givenMix() loadtest.Mix {
+	return loadtest.Mix{{Path: "/ok", Weight: 1}}
+}
+
+// givenTarget - A server answering /ok, /fail, /slow and /stale,
+//				 counting the requests of each path
+func givenTarget(t *testing.T) (*httptest.Server, map[string]int) {
+	mu := sync.Mutex{}
+	paths := make(map[string]int)
+	now := time.Now().Format(time.RFC3339Nano)
+	old := time.Now().Add(-time.Hour).Format(time.RFC3339Nano)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths[r.URL.Path]++
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/fail":
+			w.WriteHeader(500)
+		case "/slow":
+			time.Sleep(20 * time.Millisecond)
+		case "/stale":
+			fmt.Fprintf(w, `{"dataDateTime":"%s"}`, old)
+		case "/stale-quote":
+			fmt.Fprintf(w, `{"quotes":[{"dataDateTime":"%s"},{"dataDateTime":"%s"}]}`, now, old)
+		default:
+			fmt.Fprintf(w, `{"dataDateTime":"%s"}`, now)
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server, paths
+}
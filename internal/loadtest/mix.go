@@ -0,0 +1,82 @@
+package loadtest
+
+import (
+	"fmt"
+	"math/rand"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Request - A path requested from the target, Weight times as
+//			 often as a request of weight one
+type Request struct {
+	Path   string
+	Weight int
+}
+
+// Mix - The requests a load test chooses between
+type Mix []Request
+
+// ParseRequest - Parse `FROM/TO` as `/v1/exchange?from=FROM&to=TO`,
+//				  or a path starting with `/` as is. Either may end
+//				  with `*N` to give it a weight of N.
+func ParseRequest(spec string) (Request, error) {
+	r := Request{Weight: 1}
+	if i := strings.LastIndex(spec, "*"); i != -1 {
+		weight, err := strconv.Atoi(spec[i+1:])
+		if err != nil || weight < 1 {
+			return r, errors.New(fmt.Sprintf("Weight of '%s' must be a positive number", spec))
+		}
+		r.Weight = weight
+		spec = spec[:i]
+	}
+
+	if strings.HasPrefix(spec, "/") {
+		r.Path = spec
+		return r, nil
+	}
+
+	pair := strings.Split(spec, "/")
+	if len(pair) != 2 || pair[0] == "" || pair[1] == "" {
+		return r, errors.New(fmt.Sprintf("'%s' is neither a pair such as EUR/GBP nor a path", spec))
+	}
+	r.Path = "/v1/exchange?" + url.Values{"from": {pair[0]}, "to": {pair[1]}}.Encode()
+	return r, nil
+}
+
+// ParseMix - Parse comma separated requests, see ParseRequest
+func ParseMix(specs string) (Mix, error) {
+	mix := Mix{}
+	for _, spec := range strings.Split(specs, ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		r, err := ParseRequest(spec)
+		if err != nil {
+			return nil, err
+		}
+		mix = append(mix, r)
+	}
+	return mix, nil
+}
+
+// pick - Choose a path in proportion to the weights
+func (m Mix) pick(random *rand.Rand) string {
+	total := 0
+	for _, r := range m {
+		total += r.Weight
+	}
+
+	n := random.Intn(total)
+	for _, r := range m {
+		if n < r.Weight {
+			return r.Path
+		}
+		n -= r.Weight
+	}
+	return m[len(m)-1].Path
+}
@@ -0,0 +1,105 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Report - The outcome of a load test. Errors are requests that
+//			got no response or a status other than 2xx or 304.
+//			Stale are responses of a rate that had expired, see
+//			Config. Cancelled requests were still in
+//			flight when the test ended and are not counted
+//			anywhere else.
+type Report struct {
+	Requests  int
+	Errors    int
+	Stale     int
+	Dropped   int
+	Cancelled int
+	Status    map[int]int
+	Latency   Histogram
+	Elapsed   time.Duration
+}
+
+// Throughput - Requests answered per second
+func (r *Report) Throughput() float64 {
+	if r.Elapsed <= 0 {
+		return 0
+	}
+	return float64(r.Requests) / r.Elapsed.Seconds()
+}
+
+// ErrorRate - Fraction of requests that were errors
+func (r *Report) ErrorRate() float64 {
+	return fraction(r.Errors, r.Requests)
+}
+
+// StaleRate - Fraction of requests that were served stale
+func (r *Report) StaleRate() float64 {
+	return fraction(r.Stale, r.Requests)
+}
+
+// Write - Print the report for a person to read
+func (r *Report) Write(w io.Writer) {
+	fmt.Fprintf(w, "Requests:  %d in %s (%.1f/s)\n", r.Requests, r.Elapsed.Round(time.Millisecond), r.Throughput())
+	fmt.Fprintf(w, "Errors:    %d (%.2f%%)\n", r.Errors, r.ErrorRate()*100)
+	fmt.Fprintf(w, "Stale:     %d (%.2f%%)\n", r.Stale, r.StaleRate()*100)
+	if r.Dropped > 0 {
+		fmt.Fprintf(w, "Dropped:   %d, every worker was busy\n", r.Dropped)
+	}
+	if r.Cancelled > 0 {
+		fmt.Fprintf(w, "Cancelled: %d, in flight when the test ended\n", r.Cancelled)
+	}
+
+	statuses := []int{}
+	for s := range r.Status {
+		statuses = append(statuses, s)
+	}
+	sort.Ints(statuses)
+	counts := []string{}
+	for _, s := range statuses {
+		label := fmt.Sprint(s)
+		if s == 0 {
+			label = "none"
+		}
+		counts = append(counts, fmt.Sprintf("%s=%d", label, r.Status[s]))
+	}
+	fmt.Fprintf(w, "Status:    %s\n", strings.Join(counts, " "))
+
+	fmt.Fprintf(w, "Latency:   p50=%s p90=%s p99=%s max=%s\n",
+		r.Latency.Percentile(50), r.Latency.Percentile(90), r.Latency.Percentile(99), r.Latency.Max())
+	r.Latency.Write(w)
+}
+
+func (r *Report) add(res result) {
+	if res.err == context.Canceled || res.err == context.DeadlineExceeded {
+		r.Cancelled++
+		return
+	}
+
+	r.Requests++
+	r.Status[res.status]++
+	r.Latency.Add(res.latency)
+	if res.err != nil || !isSuccess(res.status) {
+		r.Errors++
+	}
+	if res.stale {
+		r.Stale++
+	}
+}
+
+func isSuccess(status int) bool {
+	return status == 304 || (status >= 200 && status < 300)
+}
+
+func fraction(n, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return float64(n) / float64(total)
+}
@@ -0,0 +1,243 @@
+package loadtest
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config - What a load test sends and for how long. Workers is
+//			the number of clients of the closed model, which each
+//			send their next request once the last one is
+//			answered. A Rate turns on the open model instead,
+//			where requests start at Rate per second whatever the
+//			response times, with at most Workers in flight. The
+//			test stops after Duration or after Requests, whichever
+//			is first, and Seed makes the choice of requests from
+//			Mix repeatable. A response is stale when a rate in
+//			it is older than ValidFor, which is not checked when
+//			ValidFor is zero.
+type Config struct {
+	Target   string
+	Mix      Mix
+	Workers  int
+	Rate     float64
+	Duration time.Duration
+	Requests int
+	Seed     int64
+	ValidFor time.Duration
+}
+
+// rates - The data times of the rates in a `/v1/exchange` or
+//		   `/v2/exchange` response
+type rates struct {
+	DataDateTime string `json:"dataDateTime"`
+	Quotes       []struct {
+		DataDateTime string `json:"dataDateTime"`
+	} `json:"quotes"`
+}
+
+type result struct {
+	status  int
+	stale   bool
+	err     error
+	latency time.Duration
+}
+
+type runner struct {
+	config Config
+	client *http.Client
+}
+
+// CreateNewRunner - Create a load test of config.Target
+func CreateNewRunner(config Config, client *http.Client) (*runner, error) {
+	if config.Target == "" {
+		return nil, errors.New("A target is needed")
+	}
+	if len(config.Mix) == 0 {
+		return nil, errors.New("At least one request is needed in the mix")
+	}
+	if config.Workers < 1 {
+		return nil, errors.New("At least one worker is needed")
+	}
+	if config.Duration <= 0 && config.Requests <= 0 {
+		return nil, errors.New("A duration or a number of requests is needed")
+	}
+	if config.Rate < 0 {
+		return nil, errors.New("Rate cannot be negative")
+	}
+	return &runner{config: config, client: client}, nil
+}
+
+// Run - Send requests until the test is over or ctx is done
+func (r *runner) Run(ctx context.Context) *Report {
+	if r.config.Duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.config.Duration)
+		defer cancel()
+	}
+
+	results := make(chan result, r.config.Workers)
+	report := &Report{Status: make(map[int]int)}
+	collected := make(chan struct{})
+	go func() {
+		for res := range results {
+			report.add(res)
+		}
+		close(collected)
+	}()
+
+	start := time.Now()
+	if r.config.Rate > 0 {
+		report.Dropped = r.runOpen(ctx, results)
+	} else {
+		r.runClosed(ctx, results)
+	}
+	close(results)
+	<-collected
+	report.Elapsed = time.Since(start)
+
+	return report
+}
+
+// runClosed - Every worker sends its next request once the last
+//			   one has been answered
+func (r *runner) runClosed(ctx context.Context, results chan<- result) {
+	budget := r.createBudget()
+	wg := sync.WaitGroup{}
+	for i := 0; i < r.config.Workers; i++ {
+		wg.Add(1)
+		go func(random *rand.Rand) {
+			defer wg.Done()
+			for ctx.Err() == nil && budget.take() {
+				results <- r.send(ctx, r.config.Mix.pick(random))
+			}
+		}(rand.New(rand.NewSource(r.config.Seed + int64(i))))
+	}
+	wg.Wait()
+}
+
+// runOpen - Start requests at the rate whatever the response
+//			 times. Returns how many were not started because
+//			 every worker was busy.
+func (r *runner) runOpen(ctx context.Context, results chan<- result) int {
+	budget := r.createBudget()
+	random := rand.New(rand.NewSource(r.config.Seed))
+	workers := make(chan struct{}, r.config.Workers)
+	ticker := time.NewTicker(time.Duration(float64(time.Second) / r.config.Rate))
+	defer ticker.Stop()
+
+	dropped := 0
+	wg := sync.WaitGroup{}
+	defer wg.Wait()
+	for {
+		select {
+		case <-ctx.Done():
+			return dropped
+		case <-ticker.C:
+		}
+
+		if !budget.take() {
+			return dropped
+		}
+		select {
+		case workers <- struct{}{}:
+		default:
+			dropped++
+			continue
+		}
+
+		wg.Add(1)
+		go func(path string) {
+			defer wg.Done()
+			results <- r.send(ctx, path)
+			<-workers
+		}(r.config.Mix.pick(random))
+	}
+}
+
+func (r *runner) send(ctx context.Context, path string) result {
+	req, err := http.NewRequest("GET", r.config.Target+path, nil)
+	if err != nil {
+		return result{err: err}
+	}
+
+	start := time.Now()
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			// The test ended while the request was in flight
+			return result{err: ctx.Err()}
+		}
+		return result{err: err, latency: time.Since(start)}
+	}
+	body, err := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	latency := time.Since(start)
+	if err != nil {
+		return result{status: resp.StatusCode, err: err, latency: latency}
+	}
+
+	return result{status: resp.StatusCode,
+		stale:   r.isStale(body),
+		latency: latency}
+}
+
+// isStale - Whether any rate in the body is older than ValidFor.
+//			 Bodies that are not rates are never stale.
+func (r *runner) isStale(body []byte) bool {
+	if r.config.ValidFor <= 0 {
+		return false
+	}
+
+	parsed := rates{}
+	if json.Unmarshal(body, &parsed) != nil {
+		return false
+	}
+	dataDateTimes := []string{parsed.DataDateTime}
+	for _, q := range parsed.Quotes {
+		dataDateTimes = append(dataDateTimes, q.DataDateTime)
+	}
+
+	now := time.Now()
+	for _, d := range dataDateTimes {
+		dataDateTime, err := time.Parse(time.RFC3339Nano, d)
+		if err == nil && now.Sub(dataDateTime) > r.config.ValidFor {
+			return true
+		}
+	}
+	return false
+}
+
+// budget - The number of requests left, unlimited when nil
+type budget struct {
+	mu   sync.Mutex
+	left int
+}
+
+func (r *runner) createBudget() *budget {
+	if r.config.Requests <= 0 {
+		return nil
+	}
+	return &budget{left: r.config.Requests}
+}
+
+func (b *budget) take() bool {
+	if b == nil {
+		return true
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.left == 0 {
+		return false
+	}
+	b.left--
+	return true
+}
@@ -0,0 +1,217 @@
+package openapi
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Version - OpenAPI version of the generated documents
+const Version = "3.0.3"
+
+// Document - OpenAPI 3 document. Only the parts of the
+//			  specification used by this server are modelled.
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Info - Metadata about the API
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// Components - Reusable schemas referenced from operations
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas"`
+}
+
+// Operation - A single method on a path
+type Operation struct {
+	Summary     string               `json:"summary,omitempty"`
+	OperationID string               `json:"operationId,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter - A query, path or header parameter of an operation
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// RequestBody - The body accepted by an operation
+type RequestBody struct {
+	Required bool                  `json:"required,omitempty"`
+	Content  map[string]*MediaType `json:"content"`
+}
+
+// Response - A response of an operation for one status code
+type Response struct {
+	Description string                `json:"description"`
+	Headers     map[string]*Header    `json:"headers,omitempty"`
+	Content     map[string]*MediaType `json:"content,omitempty"`
+}
+
+// Header - A header returned with a response
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+// StringHeader - A string response header
+func StringHeader(description string) *Header {
+	return &Header{Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// MediaType - The schema of a body for one content type
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema - JSON schema of a value
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const refPrefix = "#/components/schemas/"
+
+// CreateNewDocument - Create a new empty OpenAPI document
+func CreateNewDocument(title, version string) *Document {
+	return &Document{OpenAPI: Version,
+		Info:       Info{Title: title, Version: version},
+		Paths:      make(map[string]map[string]*Operation),
+		Components: Components{Schemas: make(map[string]*Schema)}}
+}
+
+// AddOperation - Add the operation for method (e.g. GET) on path
+func (d *Document) AddOperation(method, path string, op *Operation) {
+	if _, exists := d.Paths[path]; !exists {
+		d.Paths[path] = make(map[string]*Operation)
+	}
+	d.Paths[path][strings.ToLower(method)] = op
+}
+
+// Operation - Get the operation for method on path, nil if it
+//			   is not documented
+func (d *Document) Operation(method, path string) *Operation {
+	return d.Paths[path][strings.ToLower(method)]
+}
+
+// Schema - Get the schema of v from its type and json tags.
+//			Structs are added to the components of the
+//			document and a reference to them is returned.
+func (d *Document) Schema(v interface{}) *Schema {
+	return d.schemaForType(reflect.TypeOf(v))
+}
+
+// JSONContent - Content of a body that is JSON described by v
+func (d *Document) JSONContent(v interface{}) map[string]*MediaType {
+	return map[string]*MediaType{"application/json": {Schema: d.Schema(v)}}
+}
+
+// QueryParameter - A string query parameter
+func QueryParameter(name, description string, required bool, enum ...string) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Required: required, Schema: &Schema{Type: "string", Enum: enum}}
+}
+
+// PathParameter - A string path parameter
+func PathParameter(name, description string) Parameter {
+	return Parameter{Name: name, In: "path", Description: description, Required: true, Schema: &Schema{Type: "string"}}
+}
+
+// HeaderParameter - An optional string request header
+func HeaderParameter(name, description string) Parameter {
+	return Parameter{Name: name, In: "header", Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// Path - Convert a gin route path such as /v1/alerts/:id to
+//		  an OpenAPI path such as /v1/alerts/{id}
+func Path(route string) string {
+	parts := strings.Split(route, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = "{" + p[1:] + "}"
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+func (d *Document) schemaForType(t reflect.Type) *Schema {
+	if t == reflect.TypeOf(time.Time{}) {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		return d.schemaForType(t.Elem())
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: d.schemaForType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaForType(t.Elem())}
+	case reflect.Struct:
+		if _, exists := d.Components.Schemas[t.Name()]; !exists {
+			// Reserve the name first so recursive types terminate
+			d.Components.Schemas[t.Name()] = &Schema{}
+			*d.Components.Schemas[t.Name()] = *d.schemaForStruct(t)
+		}
+		return &Schema{Ref: refPrefix + t.Name()}
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) schemaForStruct(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+
+		name, omitempty := f.Name, false
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			parts := strings.Split(tag, ",")
+			if parts[0] == "-" {
+				continue
+			}
+			if parts[0] != "" {
+				name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				omitempty = omitempty || opt == "omitempty"
+			}
+		}
+
+		s.Properties[name] = d.schemaForType(f.Type)
+		if !omitempty {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
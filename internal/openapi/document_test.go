@@ -0,0 +1,67 @@
+package openapi_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+type testQuote struct {
+	From     string            `json:"from"`
+	Rate     float32           `json:"rate"`
+	Count    int               `json:"count,omitempty"`
+	Tags     []string          `json:"tags"`
+	Rates    map[string]string `json:"rates"`
+	When     time.Time         `json:"when"`
+	Ignored  string            `json:"-"`
+	Untagged bool
+	hidden   string
+}
+
+func TestDocument(t *testing.T) {
+	t.Run("ensure struct schema is generated from json tags", func(t *testing.T) {
+		// given
+		d := openapi.CreateNewDocument("test", "1")
+
+		// when
+		s := d.Schema(testQuote{})
+
+		// then
+		util.AssertTrue(t, s.Ref == "#/components/schemas/testQuote")
+		c := d.Components.Schemas["testQuote"]
+		util.AssertTrue(t, c.Type == "object")
+		util.AssertTrue(t, c.Properties["from"].Type == "string")
+		util.AssertTrue(t, c.Properties["rate"].Type == "number")
+		util.AssertTrue(t, c.Properties["count"].Type == "integer")
+		util.AssertTrue(t, c.Properties["tags"].Items.Type == "string")
+		util.AssertTrue(t, c.Properties["rates"].AdditionalProperties.Type == "string")
+		util.AssertTrue(t, c.Properties["when"].Format == "date-time")
+		util.AssertTrue(t, c.Properties["Untagged"].Type == "boolean")
+		util.AssertTrue(t, c.Properties["Ignored"] == nil)
+		util.AssertTrue(t, c.Properties["hidden"] == nil)
+		util.AssertTrue(t, len(c.Required) == 6)
+	})
+
+	t.Run("ensure operations can be found by method and path", func(t *testing.T) {
+		// given
+		d := openapi.CreateNewDocument("test", "1")
+
+		// when
+		d.AddOperation("GET", "/v1/test", &openapi.Operation{OperationID: "getTest"})
+
+		// then
+		util.AssertTrue(t, d.Operation("GET", "/v1/test").OperationID == "getTest")
+		util.AssertTrue(t, d.Operation("POST", "/v1/test") == nil)
+		util.AssertTrue(t, d.Operation("GET", "/v1/other") == nil)
+	})
+
+	t.Run("ensure gin route paths are converted to OpenAPI paths", func(t *testing.T) {
+		// when
+		path := openapi.Path("/v1/alerts/:id/deliveries")
+
+		// then
+		util.AssertTrue(t, path == "/v1/alerts/{id}/deliveries")
+	})
+}
@@ -0,0 +1,155 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"mime"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// ValidateResponse - Checks that a response of method on path
+//					  is described by the document and that a
+//					  JSON body matches its schema
+func (d *Document) ValidateResponse(method, path string, status int, contentType string, body []byte) error {
+	op := d.Operation(method, path)
+	if op == nil {
+		return errors.New(fmt.Sprintf("%s %s is not documented", method, path))
+	}
+
+	resp, exists := op.Responses[strconv.Itoa(status)]
+	if !exists {
+		resp, exists = op.Responses["default"]
+	}
+	if !exists {
+		return errors.New(fmt.Sprintf("%s %s does not document status %d", method, path, status))
+	}
+
+	if len(resp.Content) == 0 {
+		if len(body) != 0 {
+			return errors.New(fmt.Sprintf("%s %s does not document a body for status %d", method, path, status))
+		}
+		return nil
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return errors.Wrap(err, fmt.Sprintf("Cannot parse content type '%s'", contentType))
+	}
+
+	content, exists := resp.Content[mediaType]
+	if !exists {
+		return errors.New(fmt.Sprintf("%s %s does not document '%s' for status %d", method, path, mediaType, status))
+	}
+
+	if mediaType != "application/json" {
+		return nil
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(body, &v); err != nil {
+		return errors.Wrap(err, "Cannot unmarshall response body")
+	}
+
+	return d.Validate(content.Schema, v)
+}
+
+// Validate - Checks that v, as decoded by encoding/json into
+//			  an interface{}, matches the schema
+func (d *Document) Validate(s *Schema, v interface{}) error {
+	return d.validate(s, v, "$")
+}
+
+func (d *Document) validate(s *Schema, v interface{}, at string) error {
+	if s.Ref != "" {
+		ref, exists := d.Components.Schemas[strings.TrimPrefix(s.Ref, refPrefix)]
+		if !exists {
+			return errors.New(fmt.Sprintf("%s: unknown schema '%s'", at, s.Ref))
+		}
+		return d.validate(ref, v, at)
+	}
+
+	switch s.Type {
+	case "":
+		return nil
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, str) {
+			return errors.New(fmt.Sprintf("%s: '%s' is not one of %v", at, str, s.Enum))
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeError(at, s.Type, v)
+		}
+	case "number":
+		if _, ok := v.(float64); !ok {
+			return typeError(at, s.Type, v)
+		}
+	case "integer":
+		f, ok := v.(float64)
+		if !ok || f != float64(int64(f)) {
+			return typeError(at, s.Type, v)
+		}
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+		for i, item := range items {
+			if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "object":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return typeError(at, s.Type, v)
+		}
+		return d.validateObject(s, obj, at)
+	default:
+		return errors.New(fmt.Sprintf("%s: unknown schema type '%s'", at, s.Type))
+	}
+
+	return nil
+}
+
+func (d *Document) validateObject(s *Schema, obj map[string]interface{}, at string) error {
+	for _, name := range s.Required {
+		if _, exists := obj[name]; !exists {
+			return errors.New(fmt.Sprintf("%s: missing required property '%s'", at, name))
+		}
+	}
+
+	for name, value := range obj {
+		prop, exists := s.Properties[name]
+		if !exists {
+			prop = s.AdditionalProperties
+		}
+		if prop == nil {
+			return errors.New(fmt.Sprintf("%s: unexpected property '%s'", at, name))
+		}
+		if err := d.validate(prop, value, at+"."+name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func typeError(at, expected string, v interface{}) error {
+	return errors.New(fmt.Sprintf("%s: expected %s but actual is %T", at, expected, v))
+}
+
+func contains(values []string, v string) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,97 @@
+package openapi_test
+
+import (
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestValidateResponse(t *testing.T) {
+	t.Run("ensure valid response matches the document", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		err := d.ValidateResponse("GET", "/v1/test", 200, "application/json; charset=utf-8",
+			[]byte(`{"from":"EUR","rate":0.8,"tags":["a"],"rates":{"GBP":"0.8"},"when":"2019-10-14T19:21:48Z","Untagged":true}`))
+
+		// then
+		util.AssertErrorNil(t, err)
+	})
+
+	t.Run("ensure missing required property is caught", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		err := d.ValidateResponse("GET", "/v1/test", 200, "application/json",
+			[]byte(`{"rate":0.8,"tags":[],"rates":{},"when":"2019-10-14T19:21:48Z","Untagged":true}`))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure wrong type is caught", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		err := d.ValidateResponse("GET", "/v1/test", 200, "application/json",
+			[]byte(`{"from":"EUR","rate":"0.8","tags":[],"rates":{},"when":"2019-10-14T19:21:48Z","Untagged":true}`))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure undocumented property is caught", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		err := d.ValidateResponse("GET", "/v1/test", 200, "application/json",
+			[]byte(`{"from":"EUR","rate":0.8,"tags":[],"rates":{},"when":"2019-10-14T19:21:48Z","Untagged":true,"extra":1}`))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure undocumented status and content type are caught", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		errStatus := d.ValidateResponse("GET", "/v1/test", 404, "application/json", []byte(`{}`))
+		errContentType := d.ValidateResponse("GET", "/v1/test", 200, "text/csv", []byte(``))
+		errPath := d.ValidateResponse("GET", "/v1/other", 200, "application/json", []byte(`{}`))
+
+		// then
+		util.AssertErrorNotNil(t, errStatus)
+		util.AssertErrorNotNil(t, errContentType)
+		util.AssertErrorNotNil(t, errPath)
+	})
+
+	t.Run("ensure responses without content have no body", func(t *testing.T) {
+		// given
+		d := givenTestDocument()
+
+		// when
+		errEmpty := d.ValidateResponse("GET", "/v1/test", 304, "", []byte(``))
+		errBody := d.ValidateResponse("GET", "/v1/test", 304, "application/json", []byte(`{}`))
+
+		// then
+		util.AssertErrorNil(t, errEmpty)
+		util.AssertErrorNotNil(t, errBody)
+	})
+}
+
+func givenTestDocument() *openapi.Document {
+	d := openapi.CreateNewDocument("test", "1")
+	d.AddOperation("GET", "/v1/test", &openapi.Operation{
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "test", Content: d.JSONContent(testQuote{})},
+			"304": {Description: "not modified"},
+		},
+	})
+	return d
+}
@@ -0,0 +1,142 @@
+package pubsub
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// RateUpdate - A rate that has just been stored
+type RateUpdate struct {
+	From           string
+	To             string
+	OneUnit        float32
+	ShouldExchange bool
+	DataDateTime   time.Time
+}
+
+// Subscription - Receives the updates of the pairs it is
+//				  subscribed to on C. If the subscriber falls
+//				  behind by more than the buffer then the oldest
+//				  update is dropped so that the newest rates are
+//				  always delivered, unless it is blocking.
+type Subscription struct {
+	C        <-chan RateUpdate
+	c        chan RateUpdate
+	pairs    map[string]bool
+	dropped  uint64
+	blocking bool
+	done     chan struct{}
+	once     sync.Once
+}
+
+// Dropped - Number of updates dropped because the subscriber
+//			 was too slow
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+func (s *Subscription) wants(u RateUpdate) bool {
+	return s.pairs == nil || s.pairs[u.From+u.To]
+}
+
+// Broker - Publishes stored rates to every subscriber
+type Broker struct {
+	mu   sync.Mutex
+	subs map[*Subscription]bool
+}
+
+// CreateNewBroker - Create a new broker with no subscribers
+func CreateNewBroker() *Broker {
+	return &Broker{subs: make(map[*Subscription]bool)}
+}
+
+// Subscribe - Subscribe to the updates of pairs, given as from
+//			   and to concatenated (e.g. EURGBP). A nil pairs
+//			   subscribes to every pair. buffer is the number
+//			   of updates held for a slow subscriber, at
+//			   least one.
+func (b *Broker) Subscribe(pairs []string, buffer int) *Subscription {
+	return b.subscribe(pairs, buffer, false)
+}
+
+// SubscribeBlocking - Subscribe, except that no update is ever
+//					   dropped. Publish waits for a full buffer
+//					   to have room, so the subscriber must keep
+//					   reading until it unsubscribes.
+func (b *Broker) SubscribeBlocking(pairs []string, buffer int) *Subscription {
+	return b.subscribe(pairs, buffer, true)
+}
+
+func (b *Broker) subscribe(pairs []string, buffer int, blocking bool) *Subscription {
+	if buffer < 1 {
+		buffer = 1
+	}
+	c := make(chan RateUpdate, buffer)
+	s := &Subscription{C: c, c: c, blocking: blocking, done: make(chan struct{})}
+	if pairs != nil {
+		s.pairs = make(map[string]bool)
+		for _, p := range pairs {
+			s.pairs[p] = true
+		}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = true
+	return s
+}
+
+// Unsubscribe - Stop sending updates to s and close its channel
+func (b *Broker) Unsubscribe(s *Subscription) {
+	// Release a Publish waiting on s before taking its lock
+	s.once.Do(func() { close(s.done) })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subs[s] {
+		delete(b.subs, s)
+		close(s.c)
+	}
+}
+
+// Publish - Send u to every subscriber of its pair. It only
+//			 blocks on a slow blocking subscriber.
+func (b *Broker) Publish(u RateUpdate) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for s := range b.subs {
+		if !s.wants(u) {
+			continue
+		}
+		if s.blocking {
+			select {
+			case s.c <- u:
+			case <-s.done:
+			}
+			continue
+		}
+		select {
+		case s.c <- u:
+			continue
+		default:
+		}
+
+		// Make room by dropping the oldest update. Only Publish
+		// sends and it holds the lock, so there is room after
+		// a single receive.
+		select {
+		case <-s.c:
+			atomic.AddUint64(&s.dropped, 1)
+		default:
+		}
+		s.c <- u
+	}
+}
+
+// Subscribers - Number of current subscribers
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.subs)
+}
@@ -0,0 +1,138 @@
+package pubsub_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestBroker(t *testing.T) {
+	t.Run("ensure subscribers only receive their pairs", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.Subscribe([]string{"EURGBP"}, 10)
+
+		// when
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "USD", OneUnit: 1.1})
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: 0.8})
+
+		// then
+		u := <-s.C
+		util.AssertTrue(t, u.To == "GBP")
+		util.AssertTrue(t, len(s.C) == 0)
+	})
+
+	t.Run("ensure nil pairs receives every pair", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.Subscribe(nil, 10)
+
+		// when
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "USD", OneUnit: 1.1})
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: 0.8})
+
+		// then
+		util.AssertTrue(t, len(s.C) == 2)
+	})
+
+	t.Run("ensure slow subscribers drop the oldest updates", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.Subscribe(nil, 2)
+
+		// when
+		for i := 1; i <= 5; i++ {
+			b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: float32(i)})
+		}
+
+		// then
+		util.AssertTrue(t, s.Dropped() == 3)
+		util.AssertEquals(t, 4, (<-s.C).OneUnit)
+		util.AssertEquals(t, 5, (<-s.C).OneUnit)
+	})
+
+	t.Run("ensure publish does not block on a subscriber that never reads", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		b.Subscribe(nil, 1)
+		done := make(chan bool)
+
+		// when
+		go func() {
+			for i := 0; i < 100; i++ {
+				b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP"})
+			}
+			done <- true
+		}()
+
+		// then
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("publish blocked on a slow subscriber")
+		}
+	})
+
+	t.Run("ensure unsubscribe closes the channel", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.Subscribe(nil, 1)
+
+		// when
+		b.Unsubscribe(s)
+		b.Unsubscribe(s)
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP"})
+
+		// then
+		_, open := <-s.C
+		util.AssertFalse(t, open)
+		util.AssertTrue(t, b.Subscribers() == 0)
+	})
+
+	t.Run("ensure blocking subscribers receive every update", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.SubscribeBlocking(nil, 1)
+		done := make(chan bool)
+
+		// when
+		go func() {
+			for i := 1; i <= 5; i++ {
+				b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: float32(i)})
+			}
+			done <- true
+		}()
+
+		// then
+		for i := 1; i <= 5; i++ {
+			util.AssertEquals(t, float32(i), (<-s.C).OneUnit)
+		}
+		<-done
+		util.AssertTrue(t, s.Dropped() == 0)
+	})
+
+	t.Run("ensure unsubscribe releases a publish blocked on the subscriber", func(t *testing.T) {
+		// given
+		b := pubsub.CreateNewBroker()
+		s := b.SubscribeBlocking(nil, 1)
+		b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP"})
+		done := make(chan bool)
+		go func() {
+			b.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP"})
+			done <- true
+		}()
+
+		// when
+		b.Unsubscribe(s)
+
+		// then
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("publish blocked on an unsubscribed subscriber")
+		}
+		util.AssertTrue(t, b.Subscribers() == 0)
+	})
+}
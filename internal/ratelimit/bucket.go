@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+// TokenBucket - Allows bursts of up to burst requests and then
+//				 rate requests per second
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+	clock  util.Clock
+}
+
+// CreateNewTokenBucket - Create a full token bucket
+func CreateNewTokenBucket(rate float64, burst int, clock util.Clock) *TokenBucket {
+	return &TokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst), last: clock.Now(), clock: clock}
+}
+
+// Take - Take a token if one is available. Returns whether it
+//		  was taken, the whole tokens left and, when none was
+//		  available, how long until the next one is.
+func (b *TokenBucket) Take() (bool, int, time.Duration) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.clock.Now()
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+
+	if b.tokens < 1 {
+		if b.rate <= 0 {
+			return false, 0, time.Duration(math.MaxInt64)
+		}
+		wait := time.Duration((1 - b.tokens) / b.rate * float64(time.Second))
+		return false, 0, wait
+	}
+
+	b.tokens--
+	return true, int(b.tokens), 0
+}
+
+// Return - Put back a token that was taken for a request that
+//			was refused after all, returning the whole tokens left
+func (b *TokenBucket) Return() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.tokens = math.Min(b.burst, b.tokens+1)
+	return int(b.tokens)
+}
+
+// Limit - The burst size of the bucket
+func (b *TokenBucket) Limit() int {
+	return int(b.burst)
+}
+
+// Full - Whether the bucket has refilled to its burst size, in
+//		  which case it behaves the same as a new bucket
+func (b *TokenBucket) Full() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.tokens+b.clock.Now().Sub(b.last).Seconds()*b.rate >= b.burst
+}
@@ -0,0 +1,77 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/ratelimit"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestTokenBucket(t *testing.T) {
+	t.Run("ensure burst is allowed and then limited", func(t *testing.T) {
+		// given
+		b := ratelimit.CreateNewTokenBucket(0.001, 3, util.CreateNewClock())
+
+		// when
+		ok1, remaining1, _ := b.Take()
+		ok2, _, _ := b.Take()
+		ok3, remaining3, _ := b.Take()
+		ok4, _, retryAfter := b.Take()
+
+		// then
+		util.AssertTrue(t, ok1 && ok2 && ok3)
+		util.AssertTrue(t, remaining1 == 2)
+		util.AssertTrue(t, remaining3 == 0)
+		util.AssertFalse(t, ok4)
+		util.AssertTrue(t, retryAfter > time.Minute)
+	})
+
+	t.Run("ensure tokens are refilled at the rate", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+		b := ratelimit.CreateNewTokenBucket(100, 1, clock)
+		b.Take()
+
+		// when
+		ok1, _, _ := b.Take()
+		clock.Advance(time.Millisecond * 10)
+		ok2, _, _ := b.Take()
+
+		// then
+		util.AssertFalse(t, ok1)
+		util.AssertTrue(t, ok2)
+	})
+
+	t.Run("ensure a returned token can be taken again but not past the burst", func(t *testing.T) {
+		// given
+		b := ratelimit.CreateNewTokenBucket(0.001, 1, util.CreateNewClock())
+		b.Take()
+
+		// when
+		remaining := b.Return()
+		ok, _, _ := b.Take()
+		b.Return()
+		overfilled := b.Return()
+
+		// then
+		util.AssertTrue(t, remaining == 1)
+		util.AssertTrue(t, ok)
+		util.AssertTrue(t, overfilled == 1)
+	})
+}
+
+func TestTokenBucketFull(t *testing.T) {
+	t.Run("ensure a bucket is full until a token is taken", func(t *testing.T) {
+		// given
+		b := ratelimit.CreateNewTokenBucket(0.001, 2, util.CreateNewClock())
+		full := b.Full()
+
+		// when
+		b.Take()
+
+		// then
+		util.AssertTrue(t, full)
+		util.AssertFalse(t, b.Full())
+	})
+}
@@ -0,0 +1,255 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MaxIdleConns - Connections kept open between commands
+const MaxIdleConns = 8
+
+// Client - Commands of a Redis protocol server
+type Client interface {
+	Do(args ...string) (interface{}, error)
+	Ping() error
+	Get(key string) (string, bool, error)
+	Set(key, value string, ttl time.Duration) error
+	SetNX(key, value string, ttl time.Duration) (bool, error)
+	Del(keys ...string) (int64, error)
+	Publish(channel, message string) (int64, error)
+	Subscribe(channel string) (*Subscription, error)
+	Close() error
+}
+
+type conn struct {
+	net.Conn
+	r *bufio.Reader
+	w *bufio.Writer
+}
+
+type client struct {
+	addr    string
+	timeout time.Duration
+	mu      sync.Mutex
+	idle    []*conn
+}
+
+// CreateNewClient - Create a client of the Redis protocol server at
+//					 addr. Connecting and every command time out
+//					 after timeout. Connections are opened when
+//					 needed and reused.
+func CreateNewClient(addr string, timeout time.Duration) *client {
+	return &client{addr: addr, timeout: timeout}
+}
+
+// Do - Send a command and read its reply, see readReply. An error
+//		reply is returned as an Error.
+func (c *client) Do(args ...string) (interface{}, error) {
+	cn, err := c.get()
+	if err != nil {
+		return nil, err
+	}
+
+	cn.SetDeadline(time.Now().Add(c.timeout))
+	if err := writeCommand(cn.w, args...); err != nil {
+		cn.Close()
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot send %s", args[0]))
+	}
+	reply, err := readReply(cn.r)
+	if err != nil {
+		cn.Close()
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot read reply of %s", args[0]))
+	}
+	c.put(cn)
+
+	if e, ok := reply.(Error); ok {
+		return nil, e
+	}
+	return reply, nil
+}
+
+// Ping - Check that the server is reachable
+func (c *client) Ping() error {
+	_, err := c.Do("PING")
+	return err
+}
+
+// Get - The value of key, false when it is not set
+func (c *client) Get(key string) (string, bool, error) {
+	reply, err := c.Do("GET", key)
+	if err != nil || reply == nil {
+		return "", false, err
+	}
+	value, ok := reply.(string)
+	if !ok {
+		return "", false, errors.New(fmt.Sprintf("Unexpected reply %v to GET", reply))
+	}
+	return value, true, nil
+}
+
+// Set - Set key to value, which expires after ttl unless it is zero
+func (c *client) Set(key, value string, ttl time.Duration) error {
+	args := []string{"SET", key, value}
+	if ttl > 0 {
+		args = append(args, "PX", fmt.Sprint(ttl.Milliseconds()))
+	}
+	_, err := c.Do(args...)
+	return err
+}
+
+// SetNX - Set key to value only if it is not set, returning whether
+//			it was. The value expires after ttl unless it is zero.
+func (c *client) SetNX(key, value string, ttl time.Duration) (bool, error) {
+	args := []string{"SET", key, value, "NX"}
+	if ttl > 0 {
+		args = append(args, "PX", fmt.Sprint(ttl.Milliseconds()))
+	}
+	reply, err := c.Do(args...)
+	if err != nil {
+		return false, err
+	}
+	return reply != nil, nil
+}
+
+// Del - Delete keys, returning how many existed
+func (c *client) Del(keys ...string) (int64, error) {
+	reply, err := c.Do(append([]string{"DEL"}, keys...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return n, nil
+}
+
+// Publish - Send message to the subscribers of channel, returning
+//			 how many received it
+func (c *client) Publish(channel, message string) (int64, error) {
+	reply, err := c.Do("PUBLISH", channel, message)
+	if err != nil {
+		return 0, err
+	}
+	n, _ := reply.(int64)
+	return n, nil
+}
+
+// Subscribe - Receive the messages of channel on a connection of
+//			   its own, see Subscription
+func (c *client) Subscribe(channel string) (*Subscription, error) {
+	cn, err := c.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	cn.SetDeadline(time.Now().Add(c.timeout))
+	if err := writeCommand(cn.w, "SUBSCRIBE", channel); err != nil {
+		cn.Close()
+		return nil, errors.Wrap(err, "Cannot send SUBSCRIBE")
+	}
+	if _, err := readReply(cn.r); err != nil {
+		cn.Close()
+		return nil, errors.Wrap(err, "Cannot read reply of SUBSCRIBE")
+	}
+	// Messages can take any time to arrive
+	cn.SetDeadline(time.Time{})
+
+	s := &Subscription{C: make(chan string), conn: cn}
+	go s.receive()
+	return s, nil
+}
+
+// Close - Close the idle connections
+func (c *client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, cn := range c.idle {
+		cn.Close()
+	}
+	c.idle = nil
+	return nil
+}
+
+func (c *client) get() (*conn, error) {
+	c.mu.Lock()
+	if n := len(c.idle); n > 0 {
+		cn := c.idle[n-1]
+		c.idle = c.idle[:n-1]
+		c.mu.Unlock()
+		return cn, nil
+	}
+	c.mu.Unlock()
+
+	return c.dial()
+}
+
+func (c *client) put(cn *conn) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if len(c.idle) >= MaxIdleConns {
+		cn.Close()
+		return
+	}
+	c.idle = append(c.idle, cn)
+}
+
+func (c *client) dial() (*conn, error) {
+	nc, err := net.DialTimeout("tcp", c.addr, c.timeout)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot connect to '%s'", c.addr))
+	}
+	return &conn{Conn: nc, r: bufio.NewReader(nc), w: bufio.NewWriter(nc)}, nil
+}
+
+// Subscription - Messages of a channel are sent on C, which is
+//				  closed when the connection is lost or Close is
+//				  called. Err is then why. C must be read until
+//				  it is closed.
+type Subscription struct {
+	C    chan string
+	conn *conn
+	mu   sync.Mutex
+	err  error
+}
+
+// Close - Stop receiving messages
+func (s *Subscription) Close() error {
+	return s.conn.Close()
+}
+
+// Err - Why C was closed
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.err
+}
+
+func (s *Subscription) receive() {
+	defer close(s.C)
+
+	for {
+		reply, err := readReply(s.conn.r)
+		if err != nil {
+			s.mu.Lock()
+			s.err = err
+			s.mu.Unlock()
+			s.conn.Close()
+			return
+		}
+
+		// Messages are ["message", channel, payload]
+		m, ok := reply.([]interface{})
+		if !ok || len(m) != 3 || m[0] != "message" {
+			continue
+		}
+		if payload, ok := m[2].(string); ok {
+			s.C <- payload
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Error - An error reply of the server, such as
+//		   `ERR unknown command`
+type Error string
+
+func (e Error) Error() string {
+	return string(e)
+}
+
+// writeCommand - Write args as an array of bulk strings, which
+//				  is how every command is sent
+func writeCommand(w *bufio.Writer, args ...string) error {
+	fmt.Fprintf(w, "*%d\r\n", len(args))
+	for _, a := range args {
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(a), a)
+	}
+	return w.Flush()
+}
+
+// readReply - Read a reply, which is a string for simple and bulk
+//			   strings, nil for a nil bulk string or array, an int64,
+//			   an Error or an []interface{} of replies
+func readReply(r *bufio.Reader) (interface{}, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(line) == 0 {
+		return nil, errors.New("Empty reply")
+	}
+
+	switch line[0] {
+	case '+':
+		return line[1:], nil
+	case '-':
+		return Error(line[1:]), nil
+	case ':':
+		n, err := strconv.ParseInt(line[1:], 10, 64)
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid integer reply")
+		}
+		return n, nil
+	case '$':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid bulk string length")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, n+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, err
+		}
+		return string(buf[:n]), nil
+	case '*':
+		n, err := strconv.Atoi(line[1:])
+		if err != nil {
+			return nil, errors.Wrap(err, "Invalid array length")
+		}
+		if n < 0 {
+			return nil, nil
+		}
+		replies := make([]interface{}, n)
+		for i := range replies {
+			if replies[i], err = readReply(r); err != nil {
+				return nil, err
+			}
+		}
+		return replies, nil
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown reply type '%c'", line[0]))
+}
+
+// readCommand - Read a command sent by a client, an array of bulk
+//				 strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	reply, err := readReply(r)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, ok := reply.([]interface{})
+	if !ok || len(replies) == 0 {
+		return nil, errors.New("Command is not an array of bulk strings")
+	}
+	args := make([]string, len(replies))
+	for i, a := range replies {
+		if args[i], ok = a.(string); !ok {
+			return nil, errors.New("Command is not an array of bulk strings")
+		}
+	}
+	return args, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	if len(line) < 2 || line[len(line)-2] != '\r' {
+		return "", errors.New("Line does not end with CRLF")
+	}
+	return line[:len(line)-2], nil
+}
@@ -0,0 +1,143 @@
+package resp_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("ensure values are set, read and deleted", func(t *testing.T) {
+		// given
+		_, c := givenServerAndClient(t, util.CreateNewClock())
+
+		// when
+		setErr := c.Set("rate", "0.8", 0)
+		value, exists, getErr := c.Get("rate")
+		deleted, delErr := c.Del("rate", "missing")
+		_, existsAfter, _ := c.Get("rate")
+
+		// then
+		util.AssertErrorNil(t, setErr)
+		util.AssertErrorNil(t, getErr)
+		util.AssertErrorNil(t, delErr)
+		util.AssertTrue(t, exists)
+		util.AssertTrue(t, value == "0.8")
+		util.AssertTrue(t, deleted == 1)
+		util.AssertFalse(t, existsAfter)
+	})
+
+	t.Run("ensure values expire on the clock of the server", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+		_, c := givenServerAndClient(t, clock)
+		c.Set("rate", "0.8", time.Second)
+
+		// when
+		_, existsBefore, _ := c.Get("rate")
+		clock.Advance(time.Second)
+		_, existsAfter, _ := c.Get("rate")
+
+		// then
+		util.AssertTrue(t, existsBefore)
+		util.AssertFalse(t, existsAfter)
+	})
+
+	t.Run("ensure a value is only set if missing with SetNX", func(t *testing.T) {
+		// given
+		_, c := givenServerAndClient(t, util.CreateNewClock())
+
+		// when
+		first, err1 := c.SetNX("lock", "a", time.Second)
+		second, err2 := c.SetNX("lock", "b", time.Second)
+		value, _, _ := c.Get("lock")
+
+		// then
+		util.AssertErrorNil(t, err1)
+		util.AssertErrorNil(t, err2)
+		util.AssertTrue(t, first)
+		util.AssertFalse(t, second)
+		util.AssertTrue(t, value == "a")
+	})
+
+	t.Run("ensure error replies are returned as errors", func(t *testing.T) {
+		// given
+		_, c := givenServerAndClient(t, util.CreateNewClock())
+
+		// when
+		_, err := c.Do("EXPLODE")
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		_, isReply := err.(resp.Error)
+		util.AssertTrue(t, isReply)
+	})
+
+	t.Run("ensure published messages are received by subscribers", func(t *testing.T) {
+		// given
+		_, c := givenServerAndClient(t, util.CreateNewClock())
+		sub, err := c.Subscribe("updates")
+		util.AssertErrorNil(t, err)
+		defer sub.Close()
+
+		// when
+		received, _ := c.Publish("updates", "EURGBP")
+		c.Publish("others", "EURUSD")
+
+		// then
+		util.AssertTrue(t, received == 1)
+		util.AssertTrue(t, <-sub.C == "EURGBP")
+	})
+
+	t.Run("ensure errors while the server is down and recovery after", func(t *testing.T) {
+		// given
+		s, c := givenServerAndClient(t, util.CreateNewClock())
+		c.Set("rate", "0.8", 0)
+		sub, _ := c.Subscribe("updates")
+
+		// when
+		s.SetDown(true)
+		_, _, errDown := c.Get("rate")
+		_, open := <-sub.C
+		s.SetDown(false)
+		value, _, errUp := c.Get("rate")
+
+		// then
+		util.AssertErrorNotNil(t, errDown)
+		util.AssertFalse(t, open)
+		util.AssertErrorNotNil(t, sub.Err())
+		util.AssertErrorNil(t, errUp)
+		util.AssertTrue(t, value == "0.8")
+	})
+
+	t.Run("ensure an error when nothing is listening", func(t *testing.T) {
+		// given
+		s, c := givenServerAndClient(t, util.CreateNewClock())
+		s.Close()
+
+		// when
+		err := c.Ping()
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+type server interface {
+	SetDown(down bool)
+	Close() error
+}
+
+func givenServerAndClient(t *testing.T, clock util.Clock) (server, resp.Client) {
+	s, err := resp.StartServer("127.0.0.1:0", clock)
+	if err != nil {
+		t.Fatalf("cannot start the server: %s", err)
+	}
+	t.Cleanup(func() { s.Close() })
+
+	c := resp.CreateNewClient(s.Addr(), time.Second)
+	t.Cleanup(func() { c.Close() })
+	return s, c
+}
@@ -0,0 +1,296 @@
+package resp
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// publishTimeout - How long a message may take to be written to
+//					a subscriber
+const publishTimeout = time.Second
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+type server struct {
+	listener    net.Listener
+	clock       util.Clock
+	mu          sync.Mutex
+	data        map[string]entry
+	conns       map[net.Conn]bool
+	subscribers map[string]map[*serverConn]bool
+	down        bool
+	commands    int
+	wg          sync.WaitGroup
+}
+
+type serverConn struct {
+	net.Conn
+	mu sync.Mutex
+	w  *bufio.Writer
+}
+
+// StartServer - Start an in process server of the Redis protocol
+//				 on addr, such as `127.0.0.1:0`, for tests and local
+//				 runs. It keeps everything in memory and supports
+//				 PING, GET, SET with NX, EX or PX, DEL, EXISTS, FLUSHALL,
+//				 PUBLISH and SUBSCRIBE. Keys expire on clock.
+func StartServer(addr string, clock util.Clock) (*server, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot listen on '%s'", addr))
+	}
+
+	s := &server{listener: l,
+		clock:       clock,
+		data:        make(map[string]entry),
+		conns:       make(map[net.Conn]bool),
+		subscribers: make(map[string]map[*serverConn]bool)}
+	s.wg.Add(1)
+	go s.accept()
+	return s, nil
+}
+
+// Addr - The address being listened on
+func (s *server) Addr() string {
+	return s.listener.Addr().String()
+}
+
+// SetDown - Simulate an outage. Every connection is closed and new
+//			 ones are closed as soon as they are accepted until the
+//			 server is up again. Data is kept.
+func (s *server) SetDown(down bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.down = down
+	if down {
+		s.closeConns()
+	}
+}
+
+// Commands - How many commands have been served
+func (s *server) Commands() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.commands
+}
+
+// Close - Stop listening and close every connection
+func (s *server) Close() error {
+	err := s.listener.Close()
+	s.mu.Lock()
+	s.closeConns()
+	s.mu.Unlock()
+	s.wg.Wait()
+	return err
+}
+
+func (s *server) accept() {
+	defer s.wg.Done()
+
+	for {
+		nc, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+
+		s.mu.Lock()
+		if s.down {
+			s.mu.Unlock()
+			nc.Close()
+			continue
+		}
+		s.conns[nc] = true
+		s.mu.Unlock()
+
+		s.wg.Add(1)
+		go s.serve(&serverConn{Conn: nc, w: bufio.NewWriter(nc)})
+	}
+}
+
+func (s *server) serve(c *serverConn) {
+	defer s.wg.Done()
+	defer s.disconnect(c)
+
+	r := bufio.NewReader(c)
+	for {
+		args, err := readCommand(r)
+		if err != nil {
+			return
+		}
+		reply := s.execute(c, args)
+		if reply == "" {
+			continue
+		}
+		if err := c.write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// execute - Run a command, returning the encoded reply, which is
+//			 empty when it has already been written
+func (s *server) execute(c *serverConn, args []string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.commands++
+	name := strings.ToUpper(args[0])
+	switch name {
+	case "PING":
+		return "+PONG\r\n"
+	case "GET":
+		if len(args) != 2 {
+			return wrongArgs(name)
+		}
+		e, exists := s.lookup(args[1])
+		if !exists {
+			return "$-1\r\n"
+		}
+		return bulk(e.value)
+	case "SET":
+		return s.set(args)
+	case "DEL", "EXISTS":
+		if len(args) < 2 {
+			return wrongArgs(name)
+		}
+		n := 0
+		for _, key := range args[1:] {
+			if _, exists := s.lookup(key); exists {
+				n++
+				if name == "DEL" {
+					delete(s.data, key)
+				}
+			}
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "FLUSHALL":
+		s.data = make(map[string]entry)
+		return "+OK\r\n"
+	case "PUBLISH":
+		if len(args) != 3 {
+			return wrongArgs(name)
+		}
+		message := "*3\r\n" + bulk("message") + bulk(args[1]) + bulk(args[2])
+		n := 0
+		for sub := range s.subscribers[args[1]] {
+			// A subscriber that is not reading is disconnected
+			// rather than holding up the server
+			sub.SetWriteDeadline(time.Now().Add(publishTimeout))
+			err := sub.write(message)
+			sub.SetWriteDeadline(time.Time{})
+			if err != nil {
+				sub.Close()
+				continue
+			}
+			n++
+		}
+		return fmt.Sprintf(":%d\r\n", n)
+	case "SUBSCRIBE":
+		if len(args) < 2 {
+			return wrongArgs(name)
+		}
+		for i, channel := range args[1:] {
+			if s.subscribers[channel] == nil {
+				s.subscribers[channel] = make(map[*serverConn]bool)
+			}
+			s.subscribers[channel][c] = true
+			c.write(fmt.Sprintf("*3\r\n%s%s:%d\r\n", bulk("subscribe"), bulk(channel), i+1))
+		}
+		return ""
+	}
+	return fmt.Sprintf("-ERR unknown command '%s'\r\n", args[0])
+}
+
+func (s *server) set(args []string) string {
+	if len(args) < 3 {
+		return wrongArgs("SET")
+	}
+
+	e := entry{value: args[2]}
+	onlyIfMissing := false
+	for i := 3; i < len(args); i++ {
+		switch strings.ToUpper(args[i]) {
+		case "NX":
+			onlyIfMissing = true
+		case "EX", "PX":
+			if i+1 == len(args) {
+				return "-ERR syntax error\r\n"
+			}
+			n, err := strconv.ParseInt(args[i+1], 10, 64)
+			if err != nil || n <= 0 {
+				return "-ERR invalid expire time in 'set' command\r\n"
+			}
+			unit := time.Millisecond
+			if strings.ToUpper(args[i]) == "EX" {
+				unit = time.Second
+			}
+			e.expiresAt = s.clock.Now().Add(time.Duration(n) * unit)
+			i++
+		default:
+			return "-ERR syntax error\r\n"
+		}
+	}
+
+	if _, exists := s.lookup(args[1]); exists && onlyIfMissing {
+		return "$-1\r\n"
+	}
+	s.data[args[1]] = e
+	return "+OK\r\n"
+}
+
+func (s *server) lookup(key string) (entry, bool) {
+	e, exists := s.data[key]
+	if exists && !e.expiresAt.IsZero() && !s.clock.Now().Before(e.expiresAt) {
+		delete(s.data, key)
+		return entry{}, false
+	}
+	return e, exists
+}
+
+func (s *server) disconnect(c *serverConn) {
+	c.Close()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.conns, c.Conn)
+	for _, subs := range s.subscribers {
+		delete(subs, c)
+	}
+}
+
+// closeConns - Close every connection, s.mu must be held
+func (s *server) closeConns() {
+	for nc := range s.conns {
+		nc.Close()
+	}
+}
+
+func (c *serverConn) write(reply string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.w.WriteString(reply)
+	return c.w.Flush()
+}
+
+func bulk(s string) string {
+	return fmt.Sprintf("$%d\r\n%s\r\n", len(s), s)
+}
+
+func wrongArgs(name string) string {
+	return fmt.Sprintf("-ERR wrong number of arguments for '%s' command\r\n", strings.ToLower(name))
+}
@@ -5,17 +5,33 @@ import (
 	"time"
 
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/lease"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
 	"github.com/pkg/errors"
 	"golang.org/x/sync/semaphore"
 )
 
 // ExchangeRateServiceResponse - Response for the exchange rate
-//								 request from the service.
+//								 request from the service. ValidFor
+//								 is how much longer the rate will be
+//								 served before it is refreshed, zero
+//								 once it has expired.
 type ExchangeRateServiceResponse struct {
 	OneUnit        float32
 	ShouldExchange bool
 	DataDateTime   time.Time
+	ValidFor       time.Duration
+}
+
+// ExchangeRateBatchResponse - Response for a single {to} currency
+//							   of a batch request from the service.
+//							   Err is set when this pair could not
+//							   be served; the rest of the batch is
+//							   unaffected.
+type ExchangeRateBatchResponse struct {
+	To       string
+	Response *ExchangeRateServiceResponse
+	Err      error
 }
 
 type grResponse struct {
@@ -23,21 +39,27 @@ type grResponse struct {
 	err error
 }
 
+type networkRequest func() (*dao.ExchangeRateResponse, error)
+
 // ExchangeRateService - The service that will perform the requests
 //						 against the exchange rate site and decide
 //						 whether it's a good idea to exchange the
 //						 chosen currency.
 type ExchangeRateService interface {
 	PerformRequest(from, to string) (*ExchangeRateServiceResponse, error)
+	PerformBatchRequest(from string, to []string) []*ExchangeRateBatchResponse
+	PerformHistoricalRequest(from, to string, date time.Time) (*ExchangeRateServiceResponse, error)
 }
 
 type localExchangeRateService struct {
 	networkDAO        dao.NetworkDAO
 	dbDAO             dao.DatabaseDAO
 	dataValidDuration time.Duration
-	clock             *util.Clock
+	clock             util.Clock
 	timeout           time.Duration
 	sem               *semaphore.Weighted
+	locker            lease.Locker
+	leaseConfig       LeaseConfig
 }
 
 // CreateNewExchangeRateService - Use this to create the service
@@ -45,7 +67,7 @@ type localExchangeRateService struct {
 func CreateNewExchangeRateService(networkDAO dao.NetworkDAO,
 	dbDAO dao.DatabaseDAO,
 	dataValidDuration time.Duration,
-	clock *util.Clock,
+	clock util.Clock,
 	timeout time.Duration) *localExchangeRateService {
 
 	return &localExchangeRateService{networkDAO: networkDAO,
@@ -69,16 +91,109 @@ func (l *localExchangeRateService) PerformRequest(from, to string) (*ExchangeRat
 				return nil, errors.New("Timed out waiting for another thread to complete network request")
 			}
 			// Use expired data
-			return &ExchangeRateServiceResponse{DataDateTime: dataDateTime, OneUnit: oneUnit, ShouldExchange: shouldExchange}, nil
+			return l.createResponse(oneUnit, shouldExchange, dataDateTime), nil
 		}
 		defer l.sem.Release(1)
-		oneUnit, shouldExchange, dataDateTime, err := l.getAndStoreNewValues(from, to)
-		if err != nil {
-			return nil, err
+		return l.refresh(from, to, l.createResponse(oneUnit, shouldExchange, dataDateTime))
+	}
+	return l.createResponse(oneUnit, shouldExchange, dataDateTime), nil
+}
+
+// PerformBatchRequest - Get the exchange rates from one currency to
+//						 many. Expired or missing pairs are fetched
+//						 together in a single grouped request per
+//						 base. Failures are reported per pair.
+func (l *localExchangeRateService) PerformBatchRequest(from string, to []string) []*ExchangeRateBatchResponse {
+	results := make([]*ExchangeRateBatchResponse, len(to))
+	expired := []*ExchangeRateBatchResponse{}
+	for i, t := range to {
+		oneUnit, shouldExchange, dataDateTime := l.dbDAO.Get(from, t)
+		results[i] = &ExchangeRateBatchResponse{To: t,
+			Response: l.createResponse(oneUnit, shouldExchange, dataDateTime)}
+		if l.hasStoredValueExpired(dataDateTime) {
+			expired = append(expired, results[i])
+		}
+	}
+
+	if len(expired) == 0 {
+		return results
+	}
+
+	// Only allow one thread to perform
+	// the network request and save to the db
+	if !l.sem.TryAcquire(1) {
+		for _, r := range results {
+			// If no data available then return error,
+			// otherwise use expired data
+			if r.Response.DataDateTime.IsZero() {
+				r.Response = nil
+				r.Err = errors.New("Timed out waiting for another thread to complete network request")
+			}
+		}
+		return results
+	}
+	defer l.sem.Release(1)
+
+	fetch, others, leases := l.leaseBatchRefresh(from, expired)
+	for _, refreshLease := range leases {
+		defer releaseRefresh(refreshLease)
+	}
+
+	if len(fetch) > 0 {
+		latest, weekOld, err := l.getNewValues(from, fetch...)
+		for _, r := range expired {
+			if !l.hasStoredValueExpired(r.Response.DataDateTime) || !contains(fetch, r.To) {
+				continue
+			}
+			if err != nil {
+				r.Response, r.Err = nil, err
+				continue
+			}
+			r.Response, r.Err = l.storeNewValues(from, r.To, latest, weekOld)
+		}
+	}
+
+	// Pairs refreshed by another replica are expired until it is
+	// done, which is only waited for when nothing is stored
+	if len(others) > 0 {
+		deadline := l.clock.NewTimer(l.timeout)
+		defer deadline.Stop()
+		for _, r := range others {
+			if r.Response.DataDateTime.IsZero() {
+				r.Response, r.Err = l.waitForRefresh(from, r.To, deadline.C())
+			}
 		}
-		return &ExchangeRateServiceResponse{DataDateTime: dataDateTime, OneUnit: oneUnit, ShouldExchange: shouldExchange}, nil
 	}
-	return &ExchangeRateServiceResponse{DataDateTime: dataDateTime, OneUnit: oneUnit, ShouldExchange: shouldExchange}, nil
+
+	return results
+}
+
+// HistoricalValidFor - ValidFor of a historical rate, which never
+//						changes once it has been published
+const HistoricalValidFor = 365 * 24 * time.Hour
+
+// PerformHistoricalRequest - Get the exchange rate between from
+//							  and to on the given date. Decide if
+//							  it was a good time to exchange compared
+//							  to a week before that date. Rates are
+//							  stored by day and never expire.
+func (l *localExchangeRateService) PerformHistoricalRequest(from, to string, date time.Time) (*ExchangeRateServiceResponse, error) {
+	if oneUnit, shouldExchange, exists := l.dbDAO.GetHistorical(from, to, date); exists {
+		return l.createHistoricalResponse(oneUnit, shouldExchange, date), nil
+	}
+
+	onDate, weekBefore, err := l.getPastValues(from, date, to)
+	if err != nil {
+		return nil, err
+	}
+
+	oneUnit, shouldExchange, err := l.compareRates(to, onDate, weekBefore)
+	if err != nil {
+		return nil, err
+	}
+
+	l.dbDAO.StoreHistorical(from, to, date, oneUnit, shouldExchange)
+	return l.createHistoricalResponse(oneUnit, shouldExchange, date), nil
 }
 
 func (l *localExchangeRateService) hasStoredValueExpired(dataDateTime time.Time) bool {
@@ -87,67 +202,137 @@ func (l *localExchangeRateService) hasStoredValueExpired(dataDateTime time.Time)
 	return diff > l.dataValidDuration
 }
 
+// remainingValidity - How long until hasStoredValueExpired is
+//					   true for dataDateTime
+func (l *localExchangeRateService) remainingValidity(dataDateTime time.Time) time.Duration {
+	remaining := dataDateTime.Add(l.dataValidDuration).Sub(l.clock.Now())
+	if remaining < 0 {
+		return 0
+	}
+	return remaining
+}
+
+func (l *localExchangeRateService) createResponse(oneUnit float32, shouldExchange bool, dataDateTime time.Time) *ExchangeRateServiceResponse {
+	return &ExchangeRateServiceResponse{DataDateTime: dataDateTime,
+		OneUnit:        oneUnit,
+		ShouldExchange: shouldExchange,
+		ValidFor:       l.remainingValidity(dataDateTime)}
+}
+
+func (l *localExchangeRateService) createHistoricalResponse(oneUnit float32, shouldExchange bool, date time.Time) *ExchangeRateServiceResponse {
+	return &ExchangeRateServiceResponse{DataDateTime: date,
+		OneUnit:        oneUnit,
+		ShouldExchange: shouldExchange,
+		ValidFor:       HistoricalValidFor}
+}
+
 func (l *localExchangeRateService) getAndStoreNewValues(from, to string) (float32, bool, time.Time, error) {
-	chan1 := make(chan grResponse)
-	chan2 := make(chan grResponse)
+	latest, weekOld, err := l.getNewValues(from, to)
+	if err != nil {
+		return 0, false, time.Time{}, err
+	}
+
+	resp, err := l.storeNewValues(from, to, latest, weekOld)
+	if err != nil {
+		return 0, false, time.Time{}, err
+	}
+
+	return resp.OneUnit, resp.ShouldExchange, resp.DataDateTime, nil
+}
+
+func (l *localExchangeRateService) getNewValues(from string, to ...string) (*dao.ExchangeRateResponse, *dao.ExchangeRateResponse, error) {
+	weekAgo := l.getDateFromWeekAgo()
+	return l.waitForValues(
+		func() (*dao.ExchangeRateResponse, error) { return l.networkDAO.GetExchangeRateForNow(from, to...) },
+		func() (*dao.ExchangeRateResponse, error) { return l.networkDAO.GetExchangeRateFromPast(from, weekAgo, to...) })
+}
+
+func (l *localExchangeRateService) getPastValues(from string, date time.Time, to ...string) (*dao.ExchangeRateResponse, *dao.ExchangeRateResponse, error) {
+	weekBefore := date.AddDate(0, 0, -7)
+	if weekBefore.Before(EarliestDate) {
+		// Nothing was published before the first day
+		weekBefore = EarliestDate
+	}
+	return l.waitForValues(
+		func() (*dao.ExchangeRateResponse, error) { return l.networkDAO.GetExchangeRateFromPast(from, date, to...) },
+		func() (*dao.ExchangeRateResponse, error) { return l.networkDAO.GetExchangeRateFromPast(from, weekBefore, to...) })
+}
+
+// waitForValues - Performs both network requests concurrently
+//				   and waits for them, up to the timeout each
+func (l *localExchangeRateService) waitForValues(latestRequest, weekOldRequest networkRequest) (*dao.ExchangeRateResponse, *dao.ExchangeRateResponse, error) {
+	chan1 := make(chan grResponse, 1)
+	chan2 := make(chan grResponse, 1)
 
-	go l.performLatestRequest(from, to, chan1)
-	go l.performWeekAgoRequest(from, to, chan2)
+	go l.performRequest(latestRequest, chan1)
+	go l.performRequest(weekOldRequest, chan2)
 
 	var latest *dao.ExchangeRateResponse = nil
 	var weekOld *dao.ExchangeRateResponse = nil
 
+	timeout := l.clock.NewTimer(l.timeout)
 	select {
 	case res := <-chan1:
+		timeout.Stop()
 		if res.err != nil {
-			return 0, false, time.Time{}, res.err
+			return nil, nil, res.err
 		}
 		latest = res.res
-	case <-time.After(l.timeout):
-		return 0, false, time.Time{}, errors.New("Timeout occured while waiting for response from network layer")
+	case <-timeout.C():
+		return nil, nil, errors.New("Timeout occured while waiting for response from network layer")
 	}
 
+	timeout = l.clock.NewTimer(l.timeout)
 	select {
 	case res := <-chan2:
+		timeout.Stop()
 		if res.err != nil {
-			return 0, false, time.Time{}, res.err
+			return nil, nil, res.err
 		}
 		weekOld = res.res
-	case <-time.After(l.timeout):
-		return 0, false, time.Time{}, errors.New("Timeout occured while waiting for response from network layer")
+	case <-timeout.C():
+		return nil, nil, errors.New("Timeout occured while waiting for response from network layer")
 	}
 
-	shouldExchange := weekOld.Rates[to] < latest.Rates[to]
-	dataDateTime := l.clock.Now()
-	l.dbDAO.Store(from, to, latest.Rates[to], shouldExchange, dataDateTime)
-
-	return latest.Rates[to], shouldExchange, dataDateTime, nil
+	return latest, weekOld, nil
 }
 
-func (l *localExchangeRateService) performLatestRequest(from, to string, c chan grResponse) {
-	respLatest, err := l.networkDAO.GetExchangeRateForNow(from, to)
-	l.extractResponse(respLatest, c, err, to)
-}
+func (l *localExchangeRateService) storeNewValues(from, to string, latest, weekOld *dao.ExchangeRateResponse) (*ExchangeRateServiceResponse, error) {
+	latestRate, shouldExchange, err := l.compareRates(to, latest, weekOld)
+	if err != nil {
+		return nil, err
+	}
 
-func (l *localExchangeRateService) performWeekAgoRequest(from, to string, c chan grResponse) {
-	weekAgo := l.getDateFromWeekAgo()
-	respLatest, err := l.networkDAO.GetExchangeRateFromPast(from, to, weekAgo)
-	l.extractResponse(respLatest, c, err, to)
+	dataDateTime := l.clock.Now()
+	l.dbDAO.Store(from, to, latestRate, shouldExchange, dataDateTime)
+
+	return l.createResponse(latestRate, shouldExchange, dataDateTime), nil
 }
 
-func (l *localExchangeRateService) extractResponse(resp *dao.ExchangeRateResponse, c chan grResponse, err error, to string) {
-	if err != nil {
-		c <- grResponse{nil, errors.Wrap(err, "Cannot get week old requested data")}
-		return
+// compareRates - Naive comparison of the rate to {to} against
+//				  the rate a week before
+func (l *localExchangeRateService) compareRates(to string, latest, weekOld *dao.ExchangeRateResponse) (float32, bool, error) {
+	latestRate, exists := latest.Rates[to]
+	if !exists {
+		return 0, false, errors.New(fmt.Sprintf("Response doesn't contain conversion value to '%s'", to))
 	}
 
-	_, exists := resp.Rates[to]
+	weekOldRate, exists := weekOld.Rates[to]
 	if !exists {
-		c <- grResponse{nil, errors.New(fmt.Sprintf("Response doesn't contain conversion value to '%s'", to))}
+		return 0, false, errors.New(fmt.Sprintf("Week old response doesn't contain conversion value to '%s'", to))
+	}
+
+	return latestRate, weekOldRate < latestRate, nil
+}
+
+func (l *localExchangeRateService) performRequest(request networkRequest, c chan grResponse) {
+	resp, err := request()
+	if err != nil {
+		c <- grResponse{nil, errors.Wrap(err, "Cannot get requested data")}
 		return
 	}
 
-	c <- grResponse{resp, err}
+	c <- grResponse{resp, nil}
 }
 
 func (l *localExchangeRateService) getDateFromWeekAgo() time.Time {
@@ -1,10 +1,12 @@
 package service_test
 
 import (
+	"sync"
 	"testing"
 	"time"
 
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/httpfixture"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
 	"github.com/pkg/errors"
@@ -13,10 +15,10 @@ import (
 func TestExchangeRateService(t *testing.T) {
 	t.Run("ensure new values retrieved if none in DB", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenValidNetworkDao()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
 
 		// when
 		resp, err := service.PerformRequest("EUR", "GBP")
@@ -25,17 +27,18 @@ func TestExchangeRateService(t *testing.T) {
 		util.AssertErrorNil(t, err)
 		util.AssertFalse(t, resp == nil)
 		util.AssertTrue(t, resp.ShouldExchange)
+		util.AssertTrue(t, clock.Timers() == 0)
 	})
 
 	t.Run("ensure cached values retrieved if valid in DB", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenValidNetworkDao()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
 		resp1, _ := service.PerformRequest("EUR", "GBP")
 		networkDao.resetFlags()
-		time.Sleep(time.Millisecond * 500)
+		clock.Advance(time.Millisecond * 500)
 
 		// when
 		resp2, err := service.PerformRequest("EUR", "GBP")
@@ -45,19 +48,35 @@ func TestExchangeRateService(t *testing.T) {
 		util.AssertFalse(t, resp2 == nil)
 		util.AssertTrue(t, resp2.ShouldExchange)
 		util.AssertTrue(t, resp1.DataDateTime == resp2.DataDateTime)
-		util.AssertFalse(t, networkDao.latestCalled)
-		util.AssertFalse(t, networkDao.weekOldCalled)
+		util.AssertFalse(t, networkDao.isLatestCalled())
+		util.AssertFalse(t, networkDao.isWeekOldCalled())
+	})
+
+	t.Run("ensure remaining validity of the stored value is returned", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Minute), clock, time.Duration(time.Second*5))
+		dbDao.Store("EUR", "GBP", 0.8, true, clock.Now().Add(-time.Second*20))
+
+		// when
+		resp, err := service.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.ValidFor == time.Second*40)
 	})
 
 	t.Run("ensure new values retrieved when cached values are invalid in DB", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenValidNetworkDao()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
 		resp1, _ := service.PerformRequest("EUR", "GBP")
 		networkDao.resetFlags()
-		time.Sleep(time.Millisecond * 500)
+		clock.Advance(time.Millisecond * 500)
 
 		// when
 		resp2, err := service.PerformRequest("EUR", "GBP")
@@ -67,16 +86,84 @@ func TestExchangeRateService(t *testing.T) {
 		util.AssertFalse(t, resp2 == nil)
 		util.AssertTrue(t, resp2.ShouldExchange)
 		util.AssertFalse(t, resp1.DataDateTime == resp2.DataDateTime)
-		util.AssertTrue(t, networkDao.latestCalled)
-		util.AssertTrue(t, networkDao.weekOldCalled)
+		util.AssertTrue(t, networkDao.isLatestCalled())
+		util.AssertTrue(t, networkDao.isWeekOldCalled())
+	})
+
+	t.Run("ensure cached values expire exactly after the valid duration", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		service.PerformRequest("EUR", "GBP")
+		networkDao.resetFlags()
+
+		// when
+		clock.Advance(time.Second)
+		_, err1 := service.PerformRequest("EUR", "GBP")
+		validCalled := networkDao.isLatestCalled()
+		clock.Advance(time.Nanosecond)
+		_, err2 := service.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err1)
+		util.AssertErrorNil(t, err2)
+		util.AssertFalse(t, validCalled)
+		util.AssertTrue(t, networkDao.isLatestCalled())
+	})
+
+	t.Run("ensure error returned if network calls time out", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenBlockedNetworkDao()
+		defer close(networkDao.release)
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		errs := make(chan error, 1)
+		go func() {
+			_, err := service.PerformRequest("EUR", "GBP")
+			errs <- err
+		}()
+
+		// when
+		clock.BlockUntil(1)
+		clock.Advance(time.Second * 5)
+
+		// then
+		util.AssertErrorNotNil(t, <-errs)
+	})
+
+	t.Run("ensure expired values are refreshed from the provider", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		replayer := httpfixture.CreateNewReplayer(clock,
+			&httpfixture.Fixture{Method: "GET", Path: "/latest", Query: "base=EUR&symbols=GBP", Body: `{"base":"EUR","date":"2019-10-14","rates":{"GBP":0.9}}`},
+			&httpfixture.Fixture{Method: "GET", Path: "/latest", Query: "base=EUR&symbols=GBP", Body: `{"base":"EUR","date":"2019-10-14","rates":{"GBP":0.7}}`},
+			&httpfixture.Fixture{Method: "GET", Path: "/2019-10-07", Query: "base=EUR&symbols=GBP", Body: `{"base":"EUR","date":"2019-10-07","rates":{"GBP":0.8}}`})
+		networkDao := dao.CreateNewFerAPI("https://api.exchangeratesapi.io", "latest", "2006-01-02", replayer)
+		service := service.CreateNewExchangeRateService(networkDao, dao.CreateNewMemstore(), time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		resp1, _ := service.PerformRequest("EUR", "GBP")
+
+		// when
+		clock.Advance(time.Second * 2)
+		resp2, err := service.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.9, resp1.OneUnit)
+		util.AssertTrue(t, resp1.ShouldExchange)
+		util.AssertEquals(t, 0.7, resp2.OneUnit)
+		util.AssertFalse(t, resp2.ShouldExchange)
+		util.AssertTrue(t, replayer.Served("GET", "/latest", "base=EUR&symbols=GBP") == 2)
 	})
 
 	t.Run("ensure error returned if response from network is missing latest data", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenInvalidLatestNetworkDao()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
 
 		// when
 		_, err := service.PerformRequest("EUR", "GBP")
@@ -87,10 +174,10 @@ func TestExchangeRateService(t *testing.T) {
 
 	t.Run("ensure error returned if response from network is missing week old data", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenInvalidWeekOldNetworkDao()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
 
 		// when
 		_, err := service.PerformRequest("EUR", "GBP")
@@ -101,10 +188,10 @@ func TestExchangeRateService(t *testing.T) {
 
 	t.Run("ensure error returned if network call for latest data fails", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenNetworkServiceDownDuringLatestDataRequest()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
 
 		// when
 		_, err := service.PerformRequest("EUR", "GBP")
@@ -115,10 +202,10 @@ func TestExchangeRateService(t *testing.T) {
 
 	t.Run("ensure error returned if network call for week old data fails", func(t *testing.T) {
 		// given
-		clock := util.CreateNewClock()
+		clock := util.CreateNewFakeClock(givenNow())
 		dbDao := dao.CreateNewMemstore()
 		networkDao := givenNetworkServiceDownDuringWeekAgoDataRequest()
-		service := service.CreateNewExchangeRateService(&networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Millisecond*200), clock, time.Duration(time.Second*5))
 
 		// when
 		_, err := service.PerformRequest("EUR", "GBP")
@@ -126,22 +213,183 @@ func TestExchangeRateService(t *testing.T) {
 		// then
 		util.AssertErrorNotNil(t, err)
 	})
+
+	t.Run("ensure historical values are retrieved for the requested date", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		date := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+
+		// when
+		resp, err := service.PerformHistoricalRequest("EUR", "GBP", date)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, resp.OneUnit)
+		util.AssertTrue(t, resp.DataDateTime == date)
+		util.AssertFalse(t, networkDao.isLatestCalled())
+		util.AssertTrue(t, networkDao.isWeekOldCalled())
+	})
+
+	t.Run("ensure historical values are stored and never expire", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidNetworkDao()
+		eService := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		date := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+		eService.PerformHistoricalRequest("EUR", "GBP", date)
+		networkDao.resetFlags()
+
+		// when
+		resp, err := eService.PerformHistoricalRequest("EUR", "GBP", date)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, resp.OneUnit)
+		util.AssertFalse(t, networkDao.isWeekOldCalled())
+		util.AssertTrue(t, resp.ValidFor == service.HistoricalValidFor)
+	})
+
+	t.Run("ensure failed historical values are not stored", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenNetworkServiceDownDuringLatestDataRequest()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		date := time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)
+		networkDao.weekOld = nil
+
+		// when
+		_, err := service.PerformHistoricalRequest("EUR", "GBP", date)
+		_, _, stored := dbDao.GetHistorical("EUR", "GBP", date)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertFalse(t, stored)
+	})
+
+	t.Run("ensure batch values are retrieved with a single grouped network call", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidBatchNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+
+		// when
+		resp := service.PerformBatchRequest("EUR", []string{"GBP", "USD"})
+
+		// then
+		util.AssertTrue(t, len(resp) == 2)
+		util.AssertTrue(t, networkDao.latestCallCount() == 1)
+		util.AssertErrorNil(t, resp[0].Err)
+		util.AssertErrorNil(t, resp[1].Err)
+		util.AssertEquals(t, 0.9, resp[0].Response.OneUnit)
+		util.AssertTrue(t, resp[0].Response.ShouldExchange)
+		util.AssertEquals(t, 1.1, resp[1].Response.OneUnit)
+		util.AssertFalse(t, resp[1].Response.ShouldExchange)
+	})
+
+	t.Run("ensure batch only retrieves pairs that are not valid in DB", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidBatchNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		resp1, _ := service.PerformRequest("EUR", "GBP")
+		networkDao.resetFlags()
+
+		// when
+		resp2 := service.PerformBatchRequest("EUR", []string{"GBP", "USD"})
+
+		// then
+		util.AssertTrue(t, networkDao.latestCallCount() == 1)
+		util.AssertTrue(t, resp1.DataDateTime == resp2[0].Response.DataDateTime)
+		util.AssertErrorNil(t, resp2[1].Err)
+	})
+
+	t.Run("ensure batch reports errors per pair", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenValidBatchNetworkDao()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+
+		// when
+		resp := service.PerformBatchRequest("EUR", []string{"GBP", "JPY"})
+
+		// then
+		util.AssertErrorNil(t, resp[0].Err)
+		util.AssertFalse(t, resp[0].Response == nil)
+		util.AssertErrorNotNil(t, resp[1].Err)
+		util.AssertTrue(t, resp[1].Response == nil)
+	})
+
+	t.Run("ensure batch reports network failure for every expired pair", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		dbDao := dao.CreateNewMemstore()
+		networkDao := givenNetworkServiceDownDuringLatestDataRequest()
+		service := service.CreateNewExchangeRateService(networkDao, dbDao, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+
+		// when
+		resp := service.PerformBatchRequest("EUR", []string{"GBP", "USD"})
+
+		// then
+		util.AssertErrorNotNil(t, resp[0].Err)
+		util.AssertErrorNotNil(t, resp[1].Err)
+	})
 }
 
+// mockNetworkDAO - Records its calls, which are made from the
+//				    goroutines of waitForValues
 type mockNetworkDAO struct {
 	latest        *dao.ExchangeRateResponse
 	weekOld       *dao.ExchangeRateResponse
+	mu            sync.Mutex
 	latestCalled  bool
 	weekOldCalled bool
+	latestCalls   int
 }
 
 func (m *mockNetworkDAO) resetFlags() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.latestCalled = false
 	m.weekOldCalled = false
+	m.latestCalls = 0
+}
+
+func (m *mockNetworkDAO) isLatestCalled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.latestCalled
+}
+
+func (m *mockNetworkDAO) isWeekOldCalled() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.weekOldCalled
+}
+
+func (m *mockNetworkDAO) latestCallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.latestCalls
 }
 
-func (m *mockNetworkDAO) GetExchangeRateForNow(from, to string) (*dao.ExchangeRateResponse, error) {
+func (m *mockNetworkDAO) GetExchangeRateForNow(from string, to ...string) (*dao.ExchangeRateResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.latestCalled = true
+	m.latestCalls++
 	if m.latest != nil {
 		return m.latest, nil
 	} else {
@@ -149,7 +397,10 @@ func (m *mockNetworkDAO) GetExchangeRateForNow(from, to string) (*dao.ExchangeRa
 	}
 }
 
-func (m *mockNetworkDAO) GetExchangeRateFromPast(from, to string, date time.Time) (*dao.ExchangeRateResponse, error) {
+func (m *mockNetworkDAO) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*dao.ExchangeRateResponse, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
 	m.weekOldCalled = true
 	if m.weekOld != nil {
 		return m.weekOld, nil
@@ -158,38 +409,69 @@ func (m *mockNetworkDAO) GetExchangeRateFromPast(from, to string, date time.Time
 	}
 }
 
-func givenValidNetworkDao() mockNetworkDAO {
+// blockedNetworkDAO - Does not respond until release is closed
+type blockedNetworkDAO struct {
+	release chan struct{}
+}
+
+func (b *blockedNetworkDAO) GetExchangeRateForNow(from string, to ...string) (*dao.ExchangeRateResponse, error) {
+	<-b.release
+	return nil, errors.New("Network service down")
+}
+
+func (b *blockedNetworkDAO) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*dao.ExchangeRateResponse, error) {
+	<-b.release
+	return nil, errors.New("Network service down")
+}
+
+func givenNow() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
+
+func givenBlockedNetworkDao() *blockedNetworkDAO {
+	return &blockedNetworkDAO{release: make(chan struct{})}
+}
+
+func givenValidNetworkDao() *mockNetworkDAO {
 	latestRates := map[string]float32{"GBP": 0.9}
 	weekAgoRates := map[string]float32{"GBP": 0.8}
 	latest := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: latestRates}
 	weekOld := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-07", Rates: weekAgoRates}
-	return mockNetworkDAO{&latest, &weekOld, false, false}
+	return &mockNetworkDAO{latest: &latest, weekOld: &weekOld}
+}
+
+func givenValidBatchNetworkDao() *mockNetworkDAO {
+	latestRates := map[string]float32{"GBP": 0.9, "USD": 1.1}
+	weekAgoRates := map[string]float32{"GBP": 0.8, "USD": 1.2}
+	latest := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: latestRates}
+	weekOld := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-07", Rates: weekAgoRates}
+	return &mockNetworkDAO{latest: &latest, weekOld: &weekOld}
 }
 
-func givenInvalidLatestNetworkDao() mockNetworkDAO {
+func givenInvalidLatestNetworkDao() *mockNetworkDAO {
 	latestRates := map[string]float32{"USD": 0.9}
 	weekAgoRates := map[string]float32{"GBP": 0.8}
 	latest := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: latestRates}
 	weekOld := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-07", Rates: weekAgoRates}
-	return mockNetworkDAO{&latest, &weekOld, false, false}
+	return &mockNetworkDAO{latest: &latest, weekOld: &weekOld}
 }
 
-func givenInvalidWeekOldNetworkDao() mockNetworkDAO {
+func givenInvalidWeekOldNetworkDao() *mockNetworkDAO {
 	latestRates := map[string]float32{"GBP": 0.9}
 	weekAgoRates := map[string]float32{"USD": 0.8}
 	latest := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: latestRates}
 	weekOld := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-07", Rates: weekAgoRates}
-	return mockNetworkDAO{&latest, &weekOld, false, false}
+	return &mockNetworkDAO{latest: &latest, weekOld: &weekOld}
 }
 
-func givenNetworkServiceDownDuringLatestDataRequest() mockNetworkDAO {
+func givenNetworkServiceDownDuringLatestDataRequest() *mockNetworkDAO {
 	weekAgoRates := map[string]float32{"USD": 0.8}
 	weekOld := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-07", Rates: weekAgoRates}
-	return mockNetworkDAO{nil, &weekOld, false, false}
+	return &mockNetworkDAO{weekOld: &weekOld}
 }
 
-func givenNetworkServiceDownDuringWeekAgoDataRequest() mockNetworkDAO {
+func givenNetworkServiceDownDuringWeekAgoDataRequest() *mockNetworkDAO {
 	latestRates := map[string]float32{"GBP": 0.9}
 	latest := dao.ExchangeRateResponse{Base: "EUR", Date: "2019-10-14", Rates: latestRates}
-	return mockNetworkDAO{&latest, nil, false, false}
+	return &mockNetworkDAO{latest: &latest}
 }
@@ -0,0 +1,369 @@
+package service_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/fakeprovider"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/lease"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/resp"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/pkg/errors"
+)
+
+// TestExchangeRateServiceWithFakeProvider - Runs the service and the
+//											 provider adapter against
+//											 the fake provider over
+//											 HTTP, sharing a FakeClock
+func TestExchangeRateServiceWithFakeProvider(t *testing.T) {
+	t.Run("ensure rates are served from the DB until they expire", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, eService := givenFakeProviderService(t, clock)
+		resp1, _ := eService.PerformRequest("EUR", "GBP")
+
+		// when
+		clock.Advance(time.Second)
+		resp2, err2 := eService.PerformRequest("EUR", "GBP")
+		requestsWhileValid := provider.Requests()
+		clock.Advance(time.Second)
+		resp3, err3 := eService.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err2)
+		util.AssertErrorNil(t, err3)
+		util.AssertTrue(t, requestsWhileValid == 2)
+		util.AssertTrue(t, provider.Requests() == 4)
+		util.AssertTrue(t, resp1.DataDateTime == resp2.DataDateTime)
+		util.AssertTrue(t, resp3.DataDateTime.Equal(givenNow().Add(time.Second*2)))
+		util.AssertTrue(t, resp1.OneUnit == resp3.OneUnit)
+	})
+
+	t.Run("ensure the latest rate changes with the day", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		_, eService := givenFakeProviderService(t, clock)
+		monday, _ := eService.PerformRequest("EUR", "GBP")
+
+		// when
+		clock.Advance(time.Hour * 24)
+		tuesday, err := eService.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, monday.OneUnit != tuesday.OneUnit)
+	})
+
+	t.Run("ensure an error during an outage once the stored rate has expired", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, eService := givenFakeProviderService(t, clock)
+		eService.PerformRequest("EUR", "GBP")
+		provider.SetDown(true)
+		clock.Advance(time.Second * 2)
+
+		// when
+		_, errDown := eService.PerformRequest("EUR", "GBP")
+		provider.SetDown(false)
+		_, errUp := eService.PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNotNil(t, errDown)
+		util.AssertErrorNil(t, errUp)
+	})
+
+	t.Run("ensure stale rates are served while another request refreshes them", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, eService := givenFakeProviderService(t, clock)
+		stored, _ := eService.PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second * 2)
+		provider.SetLatency(time.Second)
+		timers := clock.Timers()
+		refreshed := make(chan *service.ExchangeRateServiceResponse, 1)
+		go func() {
+			resp, _ := eService.PerformRequest("EUR", "GBP")
+			refreshed <- resp
+		}()
+		// The refresh waits on its timeout and on the latency of
+		// both provider requests
+		clock.BlockUntil(timers + 3)
+
+		// when
+		stale, err := eService.PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second)
+		fresh := <-refreshed
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, stale.DataDateTime == stored.DataDateTime)
+		util.AssertTrue(t, stale.ValidFor == 0)
+		util.AssertTrue(t, fresh.DataDateTime.Equal(givenNow().Add(time.Second*3)))
+	})
+
+	t.Run("ensure an error when the provider is slower than the timeout", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, eService := givenFakeProviderService(t, clock)
+		provider.SetLatency(time.Minute)
+		errs := make(chan error, 1)
+		go func() {
+			_, err := eService.PerformRequest("EUR", "GBP")
+			errs <- err
+		}()
+		clock.BlockUntil(3)
+
+		// when
+		clock.Advance(time.Second * 5)
+
+		// then
+		util.AssertErrorNotNil(t, <-errs)
+		// Let the provider respond so that its server can close
+		clock.Advance(time.Minute)
+	})
+}
+
+// TestExchangeRateServiceReplicas - Runs two replicas of the service
+//									  sharing a cache and the fake
+//									  provider
+func TestExchangeRateServiceReplicas(t *testing.T) {
+	t.Run("ensure a replica uses the rates another one fetched", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, nil)
+		first, _ := replicas[0].PerformRequest("EUR", "GBP")
+
+		// when
+		second, err := replicas[1].PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, provider.Requests() == 2)
+		util.AssertTrue(t, first.DataDateTime.Equal(second.DataDateTime))
+		util.AssertTrue(t, first.OneUnit == second.OneUnit)
+	})
+
+	t.Run("ensure a replica uses the rates another one refreshed", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, nil)
+		replicas[0].PerformRequest("EUR", "GBP")
+		replicas[1].PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second * 2)
+		refreshed, _ := replicas[0].PerformRequest("EUR", "GBP")
+
+		// when
+		resp, err := replicas[1].PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, provider.Requests() == 4)
+		util.AssertTrue(t, resp.DataDateTime.Equal(refreshed.DataDateTime))
+	})
+}
+
+// TestExchangeRateServiceRefreshLeases - Runs two replicas of the
+//										   service sharing a cache
+//										   and the leases of refreshes
+func TestExchangeRateServiceRefreshLeases(t *testing.T) {
+	t.Run("ensure a single replica refreshes an expired rate", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, &service.DefaultLeaseConfig)
+		stored, _ := replicas[0].PerformRequest("EUR", "GBP")
+		replicas[1].PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second * 2)
+		provider.SetLatency(time.Second)
+		timers := clock.Timers()
+		refreshed := make(chan *service.ExchangeRateServiceResponse, 1)
+		go func() {
+			resp, _ := replicas[0].PerformRequest("EUR", "GBP")
+			refreshed <- resp
+		}()
+		clock.BlockUntil(timers + 3)
+
+		// when
+		stale, err := replicas[1].PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second)
+		fresh := <-refreshed
+		shared, errShared := replicas[1].PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertErrorNil(t, errShared)
+		util.AssertTrue(t, provider.Requests() == 4)
+		util.AssertTrue(t, stale.DataDateTime.Equal(stored.DataDateTime))
+		util.AssertTrue(t, shared.DataDateTime.Equal(fresh.DataDateTime))
+	})
+
+	t.Run("ensure a replica without a rate waits for the one fetching it", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, &service.DefaultLeaseConfig)
+		provider.SetLatency(time.Second)
+		timers := clock.Timers()
+		fetched := make(chan *service.ExchangeRateServiceResponse, 1)
+		go func() {
+			resp, _ := replicas[0].PerformRequest("EUR", "GBP")
+			fetched <- resp
+		}()
+		clock.BlockUntil(timers + 3)
+		waited := make(chan *service.ExchangeRateServiceResponse, 1)
+		go func() {
+			resp, _ := replicas[1].PerformRequest("EUR", "GBP")
+			waited <- resp
+		}()
+		// The wait is on its timeout and on polling the DB
+		clock.BlockUntil(timers + 5)
+
+		// when
+		clock.Advance(time.Second)
+		first := <-fetched
+		second := givenPolledResponse(t, clock, waited)
+
+		// then
+		util.AssertTrue(t, provider.Requests() == 2)
+		util.AssertTrue(t, second != nil)
+		util.AssertTrue(t, second.DataDateTime.Equal(first.DataDateTime))
+	})
+
+	t.Run("ensure a batch only fetches the pairs no other replica is refreshing", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, &service.DefaultLeaseConfig)
+		stored, _ := replicas[0].PerformRequest("EUR", "GBP")
+		replicas[1].PerformRequest("EUR", "GBP")
+		clock.Advance(time.Second * 2)
+		provider.SetLatency(time.Second)
+		timers := clock.Timers()
+		go replicas[0].PerformRequest("EUR", "GBP")
+		clock.BlockUntil(timers + 3)
+		batches := make(chan []*service.ExchangeRateBatchResponse, 1)
+		go func() {
+			batches <- replicas[1].PerformBatchRequest("EUR", []string{"GBP", "USD"})
+		}()
+		clock.BlockUntil(timers + 6)
+
+		// when
+		clock.Advance(time.Second)
+		batch := <-batches
+
+		// then
+		util.AssertErrorNil(t, batch[0].Err)
+		util.AssertErrorNil(t, batch[1].Err)
+		util.AssertTrue(t, batch[0].Response.DataDateTime.Equal(stored.DataDateTime))
+		util.AssertTrue(t, batch[1].Response.ValidFor > 0)
+		util.AssertTrue(t, provider.Requests() == 6)
+	})
+
+	t.Run("ensure a lease config without a poll or TTL falls back to the default", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, &service.LeaseConfig{})
+		provider.SetLatency(time.Second)
+		timers := clock.Timers()
+		go replicas[0].PerformRequest("EUR", "GBP")
+		clock.BlockUntil(timers + 3)
+		waited := make(chan *service.ExchangeRateServiceResponse, 1)
+		go func() {
+			resp, _ := replicas[1].PerformRequest("EUR", "GBP")
+			waited <- resp
+		}()
+
+		// when
+		clock.BlockUntil(timers + 5)
+		clock.Advance(time.Second)
+		resp := givenPolledResponse(t, clock, waited)
+
+		// then
+		util.AssertTrue(t, resp != nil)
+		util.AssertTrue(t, provider.Requests() == 2)
+	})
+
+	t.Run("ensure every replica refreshes when the leases are unavailable", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(givenNow())
+		provider, replicas := givenReplicatedServices(t, clock, nil)
+		replica := replicas[0].(interface {
+			UseLocker(lease.Locker, service.LeaseConfig)
+		})
+		replica.UseLocker(failingLocker{}, service.DefaultLeaseConfig)
+
+		// when
+		resp, err := replicas[0].PerformRequest("EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.ValidFor > 0)
+		util.AssertTrue(t, provider.Requests() == 2)
+	})
+}
+
+// givenPolledResponse - Advance the clock by the poll interval
+//						 until the response of a replica waiting
+//						 for another one arrives
+func givenPolledResponse(t *testing.T, clock *util.FakeClock, responses <-chan *service.ExchangeRateServiceResponse) *service.ExchangeRateServiceResponse {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		select {
+		case resp := <-responses:
+			return resp
+		case <-time.After(time.Millisecond * 10):
+			clock.Advance(service.DefaultLeaseConfig.Poll)
+		}
+	}
+	t.Fatal("the waiting replica did not respond")
+	return nil
+}
+
+type failingLocker struct{}
+
+func (failingLocker) TryAcquire(string, time.Duration) (lease.Lease, bool, error) {
+	return nil, false, errors.New("unavailable")
+}
+
+type fakeProvider interface {
+	SetDown(bool)
+	SetLatency(time.Duration)
+	Requests() int
+}
+
+// givenReplicatedServices - Replicas sharing a cache, which also
+//							 hold the leases of refreshes with the
+//							 lease config when there is one
+func givenReplicatedServices(t *testing.T, clock util.Clock, leaseConfig *service.LeaseConfig) (fakeProvider, []service.ExchangeRateService) {
+	provider := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, clock)
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+	cache, err := resp.StartServer("127.0.0.1:0", clock)
+	if err != nil {
+		t.Fatalf("cannot start the shared cache: %s", err)
+	}
+	t.Cleanup(func() { cache.Close() })
+
+	replicas := []service.ExchangeRateService{}
+	for i := 0; i < 2; i++ {
+		store := dao.CreateNewTieredStore(dao.CreateNewMemstore(), resp.CreateNewClient(cache.Addr(), time.Second), dao.DefaultTieredConfig, clock)
+		t.Cleanup(func() { store.Close() })
+		networkDao := dao.CreateNewFerAPI(server.URL, "latest", "2006-01-02", server.Client())
+		replica := service.CreateNewExchangeRateService(networkDao, store, time.Duration(time.Second), clock, time.Duration(time.Second*5))
+		if leaseConfig != nil {
+			replica.UseLocker(lease.CreateNewRedisLocker(resp.CreateNewClient(cache.Addr(), time.Second)), *leaseConfig)
+		}
+		replicas = append(replicas, replica)
+	}
+	return provider, replicas
+}
+
+func givenFakeProviderService(t *testing.T, clock util.Clock) (fakeProvider, service.ExchangeRateService) {
+	provider := fakeprovider.CreateNewProvider(fakeprovider.Config{Seed: 1}, clock)
+	server := httptest.NewServer(provider)
+	t.Cleanup(server.Close)
+
+	networkDao := dao.CreateNewFerAPI(server.URL, "latest", "2006-01-02", server.Client())
+	return provider, service.CreateNewExchangeRateService(networkDao, dao.CreateNewMemstore(), time.Duration(time.Second), clock, time.Duration(time.Second*5))
+}
@@ -0,0 +1,209 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultListenSpec - Listened on when no listen spec is given
+const DefaultListenSpec = "tcp://:8080"
+
+// firstListenFD - systemd passes sockets from this fd onwards
+const firstListenFD = 3
+
+// Listen - Open the listeners of a listen spec. A spec is one of
+//
+//		tcp://host:port, or just host:port
+//		unix:///path/to.sock?mode=0660
+//		systemd://, every socket passed in LISTEN_FDS
+//		systemd://name, the sockets named name in LISTEN_FDNAMES
+func Listen(spec string) ([]net.Listener, error) {
+	switch {
+	case strings.HasPrefix(spec, "tcp://"):
+		return listenTCP(strings.TrimPrefix(spec, "tcp://"))
+	case strings.HasPrefix(spec, "unix://"):
+		return listenUnix(strings.TrimPrefix(spec, "unix://"))
+	case strings.HasPrefix(spec, "systemd://"):
+		return listenSystemd(strings.TrimPrefix(spec, "systemd://"))
+	case !strings.Contains(spec, "://"):
+		return listenTCP(spec)
+	}
+	return nil, errors.New(fmt.Sprintf("Unknown listen spec '%s'", spec))
+}
+
+func listenTCP(addr string) ([]net.Listener, error) {
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot listen on '%s'", addr))
+	}
+	return []net.Listener{l}, nil
+}
+
+func listenUnix(spec string) ([]net.Listener, error) {
+	path, mode := spec, os.FileMode(0660)
+	if i := strings.Index(spec, "?"); i >= 0 {
+		path = spec[:i]
+		query := spec[i+1:]
+		if !strings.HasPrefix(query, "mode=") {
+			return nil, errors.New(fmt.Sprintf("Unknown option '%s' in unix listen spec", query))
+		}
+		m, err := strconv.ParseUint(strings.TrimPrefix(query, "mode="), 8, 32)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Cannot parse mode of unix socket '%s'", path))
+		}
+		mode = os.FileMode(m)
+	}
+
+	if path == "" {
+		return nil, errors.New("A unix listen spec needs a path")
+	}
+
+	// A socket left behind by a process that did not shut down
+	// cleanly would stop us from listening
+	if info, err := os.Stat(path); err == nil && info.Mode()&os.ModeSocket != 0 {
+		os.Remove(path)
+	}
+
+	// The socket is created with the umask, so it is created in a
+	// directory only we can enter and moved into place once it has
+	// its mode
+	dir, err := os.MkdirTemp(filepath.Dir(path), ".sock")
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot create unix socket '%s'", path))
+	}
+	defer os.RemoveAll(dir)
+
+	tmp := filepath.Join(dir, "s")
+	l, err := net.ListenUnix("unix", &net.UnixAddr{Name: tmp, Net: "unix"})
+	if err != nil {
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot listen on unix socket '%s'", path))
+	}
+	l.SetUnlinkOnClose(false)
+
+	if err := os.Chmod(tmp, mode); err != nil {
+		l.Close()
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot set mode of unix socket '%s'", path))
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		l.Close()
+		return nil, errors.Wrap(err, fmt.Sprintf("Cannot listen on unix socket '%s'", path))
+	}
+
+	return []net.Listener{&unixListener{UnixListener: l, addr: &net.UnixAddr{Name: path, Net: "unix"}}}, nil
+}
+
+// unixListener - A unix socket that was moved to addr after it was
+//				  listened on
+type unixListener struct {
+	*net.UnixListener
+	addr *net.UnixAddr
+}
+
+// Addr - Where the socket is now
+func (l *unixListener) Addr() net.Addr {
+	return l.addr
+}
+
+// Close - Stop listening and remove the socket
+func (l *unixListener) Close() error {
+	err := l.UnixListener.Close()
+	os.Remove(l.addr.Name)
+	return err
+}
+
+// processSystemdSockets - The sockets passed to this process,
+//							read once as the environment is then
+//							cleared so that child processes do
+//							not try to use them
+var processSystemdSockets struct {
+	once    sync.Once
+	sockets *systemdSockets
+	err     error
+}
+
+// listenSystemd - Use the sockets passed by systemd socket
+//				   activation
+func listenSystemd(name string) ([]net.Listener, error) {
+	processSystemdSockets.once.Do(func() {
+		defer os.Unsetenv("LISTEN_PID")
+		defer os.Unsetenv("LISTEN_FDS")
+		defer os.Unsetenv("LISTEN_FDNAMES")
+
+		processSystemdSockets.sockets, processSystemdSockets.err = CreateNewSystemdSockets(os.Getenv, os.Getpid(), func(i int) *os.File {
+			fd := firstListenFD + i
+			return os.NewFile(uintptr(fd), fmt.Sprintf("LISTEN_FD_%d", fd))
+		})
+	})
+	if processSystemdSockets.err != nil {
+		return nil, processSystemdSockets.err
+	}
+
+	return processSystemdSockets.sockets.Listen(name)
+}
+
+type systemdSockets struct {
+	files []*os.File
+	names []string
+}
+
+// CreateNewSystemdSockets - Read the sockets that systemd socket
+//							 activation passed to the process pid
+//							 from getenv. The i-th socket of
+//							 LISTEN_FDS is file(i), which is fd
+//							 3 onwards when passed by systemd.
+func CreateNewSystemdSockets(getenv func(string) string, pid int, file func(i int) *os.File) (*systemdSockets, error) {
+	listenPID, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil || listenPID != pid {
+		return nil, errors.New("No sockets were passed by systemd, LISTEN_PID is not this process")
+	}
+
+	count, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, errors.New("No sockets were passed by systemd in LISTEN_FDS")
+	}
+
+	s := &systemdSockets{names: strings.Split(getenv("LISTEN_FDNAMES"), ":")}
+	for i := 0; i < count; i++ {
+		s.files = append(s.files, file(i))
+	}
+	return s, nil
+}
+
+// Listen - Listen on the sockets named name, or on every socket
+//			when name is empty
+func (s *systemdSockets) Listen(name string) ([]net.Listener, error) {
+	listeners := []net.Listener{}
+	for i, f := range s.files {
+		if name != "" && (i >= len(s.names) || s.names[i] != name) {
+			continue
+		}
+
+		// FileListener dups the fd so the same socket can be
+		// used by more than one spec
+		l, err := net.FileListener(f)
+		if err != nil {
+			closeListeners(listeners)
+			return nil, errors.Wrap(err, fmt.Sprintf("Socket %s passed by systemd is not a listener", f.Name()))
+		}
+		listeners = append(listeners, l)
+	}
+
+	if len(listeners) == 0 {
+		return nil, errors.New(fmt.Sprintf("No socket named '%s' was passed by systemd", name))
+	}
+
+	return listeners, nil
+}
+
+func closeListeners(listeners []net.Listener) {
+	for _, l := range listeners {
+		l.Close()
+	}
+}
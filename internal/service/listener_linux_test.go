@@ -0,0 +1,75 @@
+package service_test
+
+import (
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+// systemdChildEnv - Set in the test binary that TestListenSystemd
+//					 starts with the sockets
+const systemdChildEnv = "SERVICE_TEST_SYSTEMD_CHILD"
+
+// TestListenSystemd - The sockets of a process are read from its
+//					   environment once, so the test binary starts
+//					   itself with sockets at fd 3 onwards as
+//					   systemd does and the specs are tested there
+func TestListenSystemd(t *testing.T) {
+	if os.Getenv(systemdChildEnv) != "" {
+		testListenSystemdChild(t)
+		return
+	}
+
+	// given
+	files := []*os.File{}
+	file := givenSocketFiles(t, 2)
+	for i := 0; i < 2; i++ {
+		files = append(files, file(i))
+	}
+	// LISTEN_PID is the pid the shell execs the test binary as
+	cmd := exec.Command("sh", "-c", `LISTEN_PID=$$ exec "$0" "$@"`, os.Args[0], "-test.run=^TestListenSystemd$", "-test.v")
+	cmd.Env = append(os.Environ(), systemdChildEnv+"=1", "LISTEN_FDS=2", "LISTEN_FDNAMES=http:admin")
+	cmd.ExtraFiles = files
+
+	// when
+	out, err := cmd.CombinedOutput()
+
+	// then
+	if err != nil {
+		t.Fatalf("systemd specs failed: %s\n%s", err, out)
+	}
+}
+
+func testListenSystemdChild(t *testing.T) {
+	t.Run("ensure every socket is used", func(t *testing.T) {
+		// when
+		listeners, err := service.Listen("systemd://")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(listeners) == 2)
+		assertAccepts(t, listeners[0])
+		assertAccepts(t, listeners[1])
+	})
+
+	t.Run("ensure sockets are picked by name", func(t *testing.T) {
+		// when
+		listeners, err := service.Listen("systemd://admin")
+		_, errMissing := service.Listen("systemd://metrics")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(listeners) == 1)
+		assertAccepts(t, listeners[0])
+		util.AssertErrorNotNil(t, errMissing)
+	})
+
+	t.Run("ensure the environment is cleared", func(t *testing.T) {
+		// then
+		util.AssertTrue(t, os.Getenv("LISTEN_FDS") == "")
+		util.AssertTrue(t, os.Getenv("LISTEN_PID") == "")
+	})
+}
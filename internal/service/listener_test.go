@@ -0,0 +1,192 @@
+package service_test
+
+import (
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestListen(t *testing.T) {
+	t.Run("ensure tcp specs are listened on", func(t *testing.T) {
+		for _, spec := range []string{"tcp://127.0.0.1:0", "127.0.0.1:0"} {
+			// when
+			listeners, err := service.Listen(spec)
+
+			// then
+			util.AssertErrorNil(t, err)
+			util.AssertTrue(t, len(listeners) == 1)
+			util.AssertTrue(t, listeners[0].Addr().Network() == "tcp")
+			assertAccepts(t, listeners[0])
+		}
+	})
+
+	t.Run("ensure unix sockets are listened on with the mode", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "exchange.sock")
+
+		// when
+		listeners, err := service.Listen("unix://" + path + "?mode=0600")
+
+		// then
+		util.AssertErrorNil(t, err)
+		info, err := os.Stat(path)
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, info.Mode().Perm() == 0600)
+		assertAccepts(t, listeners[0])
+	})
+
+	t.Run("ensure a unix socket is moved into place and removed on close", func(t *testing.T) {
+		// given
+		dir := t.TempDir()
+		path := filepath.Join(dir, "exchange.sock")
+
+		// when
+		listeners, err := service.Listen("unix://" + path)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, listeners[0].Addr().String() == path)
+		entries, err := os.ReadDir(dir)
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(entries) == 1)
+		assertAccepts(t, listeners[0])
+		_, err = os.Stat(path)
+		util.AssertTrue(t, os.IsNotExist(err))
+	})
+
+	t.Run("ensure a stale unix socket is replaced", func(t *testing.T) {
+		// given
+		path := filepath.Join(t.TempDir(), "exchange.sock")
+		stale, err := net.Listen("unix", path)
+		util.AssertErrorNil(t, err)
+		stale.(*net.UnixListener).SetUnlinkOnClose(false)
+		stale.Close()
+
+		// when
+		listeners, err := service.Listen("unix://" + path)
+
+		// then
+		util.AssertErrorNil(t, err)
+		assertAccepts(t, listeners[0])
+	})
+
+	t.Run("ensure invalid specs are rejected", func(t *testing.T) {
+		for _, spec := range []string{"udp://:53", "unix://", "unix:///tmp/x.sock?mode=rw", "unix:///tmp/x.sock?user=me", "tcp://256.0.0.1:0"} {
+			// when
+			_, err := service.Listen(spec)
+
+			// then
+			util.AssertErrorNotNil(t, err)
+		}
+	})
+}
+
+func TestCreateNewSystemdSockets(t *testing.T) {
+	t.Run("ensure every socket is used", func(t *testing.T) {
+		// given
+		file := givenSocketFiles(t, 2)
+		givenSystemdEnv(t, os.Getpid(), "2", "http:admin")
+
+		// when
+		sockets, err := service.CreateNewSystemdSockets(os.Getenv, os.Getpid(), file)
+		util.AssertErrorNil(t, err)
+		listeners, err := sockets.Listen("")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(listeners) == 2)
+		assertAccepts(t, listeners[0])
+		assertAccepts(t, listeners[1])
+	})
+
+	t.Run("ensure sockets are picked by name", func(t *testing.T) {
+		// given
+		file := givenSocketFiles(t, 2)
+		givenSystemdEnv(t, os.Getpid(), "2", "http:admin")
+
+		// when
+		sockets, err := service.CreateNewSystemdSockets(os.Getenv, os.Getpid(), file)
+		util.AssertErrorNil(t, err)
+		listeners, err := sockets.Listen("admin")
+		_, errMissing := sockets.Listen("metrics")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(listeners) == 1)
+		assertAccepts(t, listeners[0])
+		util.AssertErrorNotNil(t, errMissing)
+	})
+
+	t.Run("ensure sockets passed to another process are not used", func(t *testing.T) {
+		// given
+		file := givenSocketFiles(t, 1)
+		givenSystemdEnv(t, os.Getpid()+1, "1", "http")
+
+		// when
+		_, err := service.CreateNewSystemdSockets(os.Getenv, os.Getpid(), file)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure LISTEN_FDS is needed", func(t *testing.T) {
+		// given
+		file := givenSocketFiles(t, 1)
+		givenSystemdEnv(t, os.Getpid(), "", "")
+
+		// when
+		_, err := service.CreateNewSystemdSockets(os.Getenv, os.Getpid(), file)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+// givenSocketFiles - The files of count listening sockets, indexed
+//					  as CreateNewSystemdSockets asks for them
+func givenSocketFiles(t *testing.T, count int) func(i int) *os.File {
+	t.Helper()
+
+	files := []*os.File{}
+	for i := 0; i < count; i++ {
+		l, err := net.Listen("tcp", "127.0.0.1:0")
+		util.AssertErrorNil(t, err)
+		f, err := l.(*net.TCPListener).File()
+		util.AssertErrorNil(t, err)
+		l.Close()
+		t.Cleanup(func() { f.Close() })
+		files = append(files, f)
+	}
+
+	return func(i int) *os.File { return files[i] }
+}
+
+// givenSystemdEnv - The environment systemd passes sockets in,
+//					 restored when the test ends
+func givenSystemdEnv(t *testing.T, pid int, fds, names string) {
+	t.Setenv("LISTEN_PID", strconv.Itoa(pid))
+	t.Setenv("LISTEN_FDS", fds)
+	t.Setenv("LISTEN_FDNAMES", names)
+}
+
+func assertAccepts(t *testing.T, l net.Listener) {
+	t.Helper()
+	defer l.Close()
+
+	go func() {
+		if c, err := l.Accept(); err == nil {
+			c.Close()
+		}
+	}()
+
+	c, err := net.Dial(l.Addr().Network(), l.Addr().String())
+	if err != nil {
+		t.Fatalf("cannot connect to %s: %s", l.Addr(), err)
+	}
+	c.Close()
+}
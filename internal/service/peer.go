@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"net"
+)
+
+// UnixPeerPrefix - Starts the key of every client connected over
+//					a unix socket, see UnixPeer
+const UnixPeerPrefix = "unix"
+
+type unixPeerKey struct{}
+
+// withUnixPeer - ConnContext of the server that records the key
+//				  of clients connected over a unix socket, which
+//				  have no IP to be told apart by
+func withUnixPeer(ctx context.Context, conn net.Conn) context.Context {
+	if unixConn, ok := conn.(*net.UnixConn); ok {
+		return context.WithValue(ctx, unixPeerKey{}, UnixPeer(unixConn))
+	}
+	return ctx
+}
+
+// unixPeerFrom - The key recorded by withUnixPeer, empty when the
+//				  client is not connected over a unix socket
+func unixPeerFrom(ctx context.Context) string {
+	peer, _ := ctx.Value(unixPeerKey{}).(string)
+	return peer
+}
+
+// socketPeer - The key of a unix peer whose credentials are not
+//				known, every peer of the socket shares it
+func socketPeer(conn *net.UnixConn) string {
+	return UnixPeerPrefix + ":" + conn.LocalAddr().String()
+}
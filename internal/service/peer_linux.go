@@ -0,0 +1,28 @@
+package service
+
+import (
+	"fmt"
+	"net"
+	"syscall"
+)
+
+// UnixPeer - The key of a client connected over a unix socket,
+//			  `unix:uid=1000` from the SO_PEERCRED credentials of
+//			  the peer process, or `unix:<socket path>` when they
+//			  cannot be read
+func UnixPeer(conn *net.UnixConn) string {
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		return socketPeer(conn)
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	})
+	if err != nil || credErr != nil {
+		return socketPeer(conn)
+	}
+	return fmt.Sprintf("%s:uid=%d", UnixPeerPrefix, cred.Uid)
+}
@@ -0,0 +1,45 @@
+package service_test
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestUnixPeer(t *testing.T) {
+	t.Run("ensure unix socket clients are allowlisted by their uid", func(t *testing.T) {
+		// given
+		client, _ := givenUnixRateLimitedServer(t, service.RateLimitConfig{
+			PerClient:     service.Limit{Rate: 0.001, Burst: 1},
+			UnixAllowlist: []string{fmt.Sprintf("unix:uid=%d", os.Getuid())},
+		})
+		client.Get("http://unix/openapi.json")
+
+		// when
+		resp, err := client.Get("http://unix/openapi.json")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == http.StatusOK)
+	})
+
+	t.Run("ensure other uids are limited", func(t *testing.T) {
+		// given
+		client, _ := givenUnixRateLimitedServer(t, service.RateLimitConfig{
+			PerClient:     service.Limit{Rate: 0.001, Burst: 1},
+			UnixAllowlist: []string{fmt.Sprintf("unix:uid=%d", os.Getuid()+1)},
+		})
+		client.Get("http://unix/openapi.json")
+
+		// when
+		resp, err := client.Get("http://unix/openapi.json")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == http.StatusTooManyRequests)
+	})
+}
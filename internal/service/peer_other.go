@@ -0,0 +1,12 @@
+//go:build !linux
+
+package service
+
+import "net"
+
+// UnixPeer - The key of a client connected over a unix socket,
+//			  `unix:<socket path>` as peer credentials are only
+//			  read on linux
+func UnixPeer(conn *net.UnixConn) string {
+	return socketPeer(conn)
+}
@@ -0,0 +1,296 @@
+package service
+
+import (
+	"math"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/ratelimit"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// maxTrackedClients - Once more clients than this are tracked
+//					   the buckets that have refilled are removed
+const maxTrackedClients = 10000
+
+// Limit - Requests per second with bursts of up to Burst. A zero
+//		   Rate means unlimited.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// RouteLimit - The per client limit of every request whose
+//				path starts with Prefix, instead of the default
+//				per client limit. An empty Method matches every
+//				method.
+type RouteLimit struct {
+	Method string
+	Prefix string
+	Limit  Limit
+}
+
+// RateLimitConfig - Limits applied by the RateLimit middleware
+type RateLimitConfig struct {
+	Global            Limit
+	PerClient         Limit
+	Routes            []RouteLimit
+	Allowlist         []*net.IPNet
+	UnixAllowlist     []string
+	TrustForwardedFor bool
+}
+
+// Client - Who a request is from. Clients connected over a unix
+//			socket have no IP and are told apart by Unix, their
+//			key from UnixPeer.
+type Client struct {
+	IP   net.IP
+	Unix string
+}
+
+func (c Client) key() string {
+	if c.Unix != "" {
+		return c.Unix
+	}
+	return c.IP.String()
+}
+
+type rateLimiter struct {
+	config  RateLimitConfig
+	clock   util.Clock
+	global  *ratelimit.TokenBucket
+	mu      sync.Mutex
+	clients map[string]*ratelimit.TokenBucket
+}
+
+// ParseCIDRs - Parse an allowlist such as `10.0.0.0/8,127.0.0.1/32`
+func ParseCIDRs(list string) ([]*net.IPNet, error) {
+	nets := []*net.IPNet{}
+	for _, cidr := range strings.Split(list, ",") {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, errors.Wrap(err, "Cannot parse allowlist")
+		}
+		nets = append(nets, n)
+	}
+	return nets, nil
+}
+
+// ParseAllowlist - Parse an allowlist of CIDRs and unix peers such
+//					as `127.0.0.1/32,unix:uid=1000`. `unix` allows
+//					every client of a unix socket, see UnixPeer.
+func ParseAllowlist(list string) ([]*net.IPNet, []string, error) {
+	cidrs, peers := []string{}, []string{}
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == UnixPeerPrefix || strings.HasPrefix(entry, UnixPeerPrefix+":") {
+			peers = append(peers, entry)
+		} else {
+			cidrs = append(cidrs, entry)
+		}
+	}
+
+	nets, err := ParseCIDRs(strings.Join(cidrs, ","))
+	if err != nil {
+		return nil, nil, err
+	}
+	return nets, peers, nil
+}
+
+// RateLimitResult - Whether a request is within the limits, see
+//					 Allow. Limit and Remaining are those of the
+//					 client's bucket, zero when the client is not
+//					 limited. Requests over a limit have the
+//					 Message to answer with and a RetryAfter.
+type RateLimitResult struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	RetryAfter time.Duration
+	Message    string
+}
+
+// CreateNewRateLimiter - Limits requests per client and across all
+//						  clients with token buckets. Requests from
+//						  the allowlist are never limited.
+func CreateNewRateLimiter(config RateLimitConfig, clock util.Clock) *rateLimiter {
+	routes := append([]RouteLimit{}, config.Routes...)
+	sort.SliceStable(routes, func(i, j int) bool { return len(routes[i].Prefix) > len(routes[j].Prefix) })
+	config.Routes = routes
+
+	l := &rateLimiter{config: config, clock: clock, clients: make(map[string]*ratelimit.TokenBucket)}
+	if config.Global.Rate > 0 {
+		l.global = createBucket(config.Global, clock)
+	}
+	return l
+}
+
+// RateLimit - Middleware that limits requests with a new
+//			   rateLimiter, see CreateNewRateLimiter. Requests over
+//			   a limit get a 429 with a Retry-After header.
+func RateLimit(config RateLimitConfig, clock util.Clock) gin.HandlerFunc {
+	return CreateNewRateLimiter(config, clock).Middleware()
+}
+
+// Middleware - See RateLimit
+func (l *rateLimiter) Middleware() gin.HandlerFunc {
+	return l.handle
+}
+
+// Allow - Take a token for a request from client, whose IP is nil
+//		   when it is unknown, for the method and path. The
+//		   client's token is put back when the global limit
+//		   refuses the request.
+func (l *rateLimiter) Allow(client Client, method, path string) RateLimitResult {
+	if l.allowlisted(client) {
+		return RateLimitResult{Allowed: true}
+	}
+
+	result := RateLimitResult{Allowed: true}
+	var bucket *ratelimit.TokenBucket
+	name, limit := l.limitFor(method, path)
+	if limit.Rate > 0 {
+		bucket = l.clientBucket(name+" "+client.key(), limit)
+		ok, remaining, retryAfter := bucket.Take()
+		result.Limit, result.Remaining = bucket.Limit(), remaining
+		if !ok {
+			result.Allowed, result.RetryAfter, result.Message = false, retryAfter, "too many requests from the client, slow down"
+			return result
+		}
+	}
+
+	if l.global != nil {
+		if ok, _, retryAfter := l.global.Take(); !ok {
+			result.Allowed, result.RetryAfter, result.Message = false, retryAfter, "the server is receiving too many requests, slow down"
+			// The client is not held to a request the server refused
+			if bucket != nil {
+				result.Remaining = bucket.Return()
+			}
+		}
+	}
+	return result
+}
+
+func (l *rateLimiter) handle(c *gin.Context) {
+	result := l.Allow(l.client(c), c.Request.Method, c.Request.URL.Path)
+	if result.Limit > 0 {
+		c.Header("X-RateLimit-Limit", strconv.Itoa(result.Limit))
+		c.Header("X-RateLimit-Remaining", strconv.Itoa(result.Remaining))
+	}
+	if !result.Allowed {
+		tooManyRequests(c, result.RetryAfter.Seconds(), result.Message)
+		return
+	}
+
+	c.Next()
+}
+
+// limitFor - The per client limit for the request and the name
+//			  that its buckets are kept under
+func (l *rateLimiter) limitFor(method, path string) (string, Limit) {
+	for _, r := range l.config.Routes {
+		if (r.Method == "" || r.Method == method) && strings.HasPrefix(path, r.Prefix) {
+			return r.Method + " " + r.Prefix, r.Limit
+		}
+	}
+	return "", l.config.PerClient
+}
+
+func (l *rateLimiter) clientBucket(name string, limit Limit) *ratelimit.TokenBucket {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if bucket, exists := l.clients[name]; exists {
+		return bucket
+	}
+
+	if len(l.clients) >= maxTrackedClients {
+		for n, b := range l.clients {
+			if b.Full() {
+				delete(l.clients, n)
+			}
+		}
+	}
+
+	bucket := createBucket(limit, l.clock)
+	l.clients[name] = bucket
+	return bucket
+}
+
+func (l *rateLimiter) client(c *gin.Context) Client {
+	if peer := unixPeerFrom(c.Request.Context()); peer != "" {
+		// gin only reads X-Forwarded-For from IP peers, so a proxy
+		// on a unix socket is trusted here
+		if l.config.TrustForwardedFor {
+			if ip := forwardedFor(c); ip != nil {
+				return Client{IP: ip}
+			}
+		}
+		return Client{Unix: peer}
+	}
+
+	addr := c.Request.RemoteAddr
+	if l.config.TrustForwardedFor {
+		addr = c.ClientIP()
+	}
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		addr = host
+	}
+	return Client{IP: net.ParseIP(addr)}
+}
+
+// forwardedFor - The first IP of X-Forwarded-For, as gin takes it
+//				  when every proxy is trusted
+func forwardedFor(c *gin.Context) net.IP {
+	first := strings.Split(c.GetHeader("X-Forwarded-For"), ",")[0]
+	return net.ParseIP(strings.TrimSpace(first))
+}
+
+func (l *rateLimiter) allowlisted(client Client) bool {
+	if client.Unix != "" {
+		for _, peer := range l.config.UnixAllowlist {
+			if peer == UnixPeerPrefix || peer == client.Unix {
+				return true
+			}
+		}
+		return false
+	}
+	if client.IP == nil {
+		return false
+	}
+	for _, n := range l.config.Allowlist {
+		if n.Contains(client.IP) {
+			return true
+		}
+	}
+	return false
+}
+
+func createBucket(limit Limit, clock util.Clock) *ratelimit.TokenBucket {
+	burst := limit.Burst
+	if burst < 1 {
+		burst = int(math.Ceil(limit.Rate))
+	}
+	return ratelimit.CreateNewTokenBucket(limit.Rate, burst, clock)
+}
+
+func tooManyRequests(c *gin.Context, retryAfter float64, message string) {
+	c.Header("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter))))
+	c.AbortWithStatusJSON(429, api.ExchangeErrorResponse{
+		Code:      api.ErrorCodeRateLimited,
+		Message:   message,
+		RequestID: GetRequestID(c),
+		Reason:    message,
+	})
+}
@@ -0,0 +1,220 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestRateLimit(t *testing.T) {
+	t.Run("ensure requests over the per client limit are rejected", func(t *testing.T) {
+		// given
+		router := givenRateLimitedRouter(service.RateLimitConfig{PerClient: service.Limit{Rate: 0.001, Burst: 2}})
+		first := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+		givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// when
+		rec := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// then
+		util.AssertTrue(t, first.Code == 200)
+		util.AssertTrue(t, first.Header().Get("X-RateLimit-Limit") == "2")
+		util.AssertTrue(t, first.Header().Get("X-RateLimit-Remaining") == "1")
+		util.AssertTrue(t, rec.Code == 429)
+		util.AssertTrue(t, rec.Header().Get("X-RateLimit-Remaining") == "0")
+		util.AssertTrue(t, rec.Header().Get("Retry-After") != "")
+		body := api.ExchangeErrorResponse{}
+		util.AssertErrorNil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		util.AssertTrue(t, body.Code == api.ErrorCodeRateLimited)
+	})
+
+	t.Run("ensure clients are limited separately", func(t *testing.T) {
+		// given
+		router := givenRateLimitedRouter(service.RateLimitConfig{PerClient: service.Limit{Rate: 0.001, Burst: 1}})
+		givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// when
+		rec := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.2:1234")
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+	})
+
+	t.Run("ensure the global limit applies to every client", func(t *testing.T) {
+		// given
+		router := givenRateLimitedRouter(service.RateLimitConfig{Global: service.Limit{Rate: 0.001, Burst: 1}})
+		givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// when
+		rec := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.2:1234")
+
+		// then
+		util.AssertTrue(t, rec.Code == 429)
+	})
+
+	t.Run("ensure a request refused by the global limit does not use the client's token", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+		limiter := service.CreateNewRateLimiter(service.RateLimitConfig{PerClient: service.Limit{Rate: 0.001, Burst: 1}, Global: service.Limit{Rate: 1, Burst: 1}}, clock)
+		client := service.Client{IP: net.ParseIP("10.1.1.1")}
+		limiter.Allow(service.Client{IP: net.ParseIP("10.1.1.2")}, "GET", "/v1/exchange")
+
+		// when
+		refused := limiter.Allow(client, "GET", "/v1/exchange")
+		clock.Advance(time.Second)
+		allowed := limiter.Allow(client, "GET", "/v1/exchange")
+
+		// then
+		util.AssertFalse(t, refused.Allowed)
+		util.AssertTrue(t, refused.Remaining == 1)
+		util.AssertTrue(t, allowed.Allowed)
+	})
+
+	t.Run("ensure route limits replace the per client limit", func(t *testing.T) {
+		// given
+		config := service.RateLimitConfig{
+			PerClient: service.Limit{Rate: 0.001, Burst: 5},
+			Routes:    []service.RouteLimit{{Method: "POST", Prefix: "/v1/exchange/batch", Limit: service.Limit{Rate: 0.001, Burst: 1}}},
+		}
+		router := givenRateLimitedRouter(config)
+		givenRequestFrom(router, "POST", "/v1/exchange/batch", "10.1.1.1:1234")
+
+		// when
+		batch := givenRequestFrom(router, "POST", "/v1/exchange/batch", "10.1.1.1:1234")
+		exchange := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// then
+		util.AssertTrue(t, batch.Code == 429)
+		util.AssertTrue(t, exchange.Code == 200)
+		util.AssertTrue(t, exchange.Header().Get("X-RateLimit-Limit") == "5")
+	})
+
+	t.Run("ensure the allowlist is never limited", func(t *testing.T) {
+		// given
+		allowlist, err := service.ParseCIDRs("10.0.0.0/8, 192.168.0.0/16")
+		util.AssertErrorNil(t, err)
+		config := service.RateLimitConfig{Global: service.Limit{Rate: 0.001, Burst: 1}, Allowlist: allowlist}
+		router := givenRateLimitedRouter(config)
+		givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+
+		// when
+		allowed := givenRequestFrom(router, "GET", "/v1/exchange", "10.1.1.1:1234")
+		limited := givenRequestFrom(router, "GET", "/v1/exchange", "172.16.0.1:1234")
+
+		// then
+		util.AssertTrue(t, allowed.Code == 200)
+		util.AssertTrue(t, limited.Code == 200)
+		limited = givenRequestFrom(router, "GET", "/v1/exchange", "172.16.0.1:1234")
+		util.AssertTrue(t, limited.Code == 429)
+	})
+
+	t.Run("ensure an invalid allowlist is an error", func(t *testing.T) {
+		// when
+		_, err := service.ParseCIDRs("10.0.0.0/33")
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure unix peers are parsed from the allowlist", func(t *testing.T) {
+		// when
+		nets, peers, err := service.ParseAllowlist("127.0.0.0/8, unix, unix:uid=1000")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(nets) == 1)
+		util.AssertTrue(t, len(peers) == 2)
+		util.AssertTrue(t, peers[1] == "unix:uid=1000")
+	})
+
+	t.Run("ensure unix socket clients have their own bucket", func(t *testing.T) {
+		// given
+		client, limiter := givenUnixRateLimitedServer(t, service.RateLimitConfig{PerClient: service.Limit{Rate: 0.001, Burst: 1}})
+		unknown := limiter.Allow(service.Client{}, "GET", "/openapi.json")
+		first, err := client.Get("http://unix/openapi.json")
+		util.AssertErrorNil(t, err)
+
+		// when
+		second, err := client.Get("http://unix/openapi.json")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, unknown.Allowed)
+		util.AssertTrue(t, first.StatusCode == 200)
+		util.AssertTrue(t, second.StatusCode == 429)
+	})
+
+	t.Run("ensure unix allows every unix socket client", func(t *testing.T) {
+		// given
+		client, _ := givenUnixRateLimitedServer(t, service.RateLimitConfig{
+			PerClient:     service.Limit{Rate: 0.001, Burst: 1},
+			UnixAllowlist: []string{"unix"},
+		})
+		client.Get("http://unix/openapi.json")
+
+		// when
+		resp, err := client.Get("http://unix/openapi.json")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 200)
+	})
+}
+
+func givenRateLimitedRouter(config service.RateLimitConfig) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(service.RequestID(), service.RateLimit(config, util.CreateNewClock()))
+	ok := func(c *gin.Context) { c.Status(200) }
+	router.GET("/v1/exchange", ok)
+	router.POST("/v1/exchange/batch", ok)
+	return router
+}
+
+func givenRequestFrom(router *gin.Engine, method, path, remoteAddr string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	req.RemoteAddr = remoteAddr
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+type limiter interface {
+	Allow(client service.Client, method, path string) service.RateLimitResult
+}
+
+// givenUnixRateLimitedServer - Serve a rate limited server on a unix
+//								socket until the test ends, with a
+//								client that connects to it and
+//								the limiter
+func givenUnixRateLimitedServer(t *testing.T, config service.RateLimitConfig) (*http.Client, limiter) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "exchange.sock")
+	server := service.CreateNewServer()
+	server.ListenOn("unix://" + path)
+	l := server.LimitRate(config, util.CreateNewClock())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	<-server.Ready()
+
+	return &http.Client{Transport: &http.Transport{
+		DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, "unix", path)
+		}}}, l
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"log"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/lease"
+	"github.com/pkg/errors"
+)
+
+// LeaseConfig - TTL is the longest a replica holds the lease to
+//				 refresh a pair, which should be well above the
+//				 timeout of the provider. Poll is how often a
+//				 replica without a rate checks the DB for the one
+//				 the holder of the lease stores.
+type LeaseConfig struct {
+	TTL  time.Duration
+	Poll time.Duration
+}
+
+// DefaultLeaseConfig - The config of `cmd/exchange-server`
+var DefaultLeaseConfig = LeaseConfig{TTL: 15 * time.Second, Poll: 100 * time.Millisecond}
+
+// refreshLeasePrefix - Prefix of the name of the lease of a pair
+const refreshLeasePrefix = "exchange:refresh:"
+
+// UseLocker - Only refresh an expired pair from the provider while
+//			   holding its lease from locker, so that replicas
+//			   sharing the DB and the locker make a single refresh.
+//			   The others serve the expired rate meanwhile or, when
+//			   they have none, wait for the holder to store it.
+//			   Every replica refreshes when the locker fails. A
+//			   TTL or Poll that is not above 0 is the one of
+//			   DefaultLeaseConfig.
+func (l *localExchangeRateService) UseLocker(locker lease.Locker, config LeaseConfig) {
+	if config.TTL <= 0 {
+		config.TTL = DefaultLeaseConfig.TTL
+	}
+	// Waiting replicas would query the DB without pause otherwise
+	if config.Poll <= 0 {
+		config.Poll = DefaultLeaseConfig.Poll
+	}
+	l.locker = locker
+	l.leaseConfig = config
+}
+
+// refresh - Fetch and store the rate between from and to, or serve
+//			 stored, the expired rate, while another replica
+//			 refreshes it
+func (l *localExchangeRateService) refresh(from, to string, stored *ExchangeRateServiceResponse) (*ExchangeRateServiceResponse, error) {
+	refreshLease, acquired := l.acquireRefresh(from, to)
+	if !acquired {
+		if !stored.DataDateTime.IsZero() {
+			return stored, nil
+		}
+		deadline := l.clock.NewTimer(l.timeout)
+		defer deadline.Stop()
+		return l.waitForRefresh(from, to, deadline.C())
+	}
+	defer releaseRefresh(refreshLease)
+
+	if l.locker != nil {
+		// Another replica may have refreshed it before the lease
+		// was acquired
+		if oneUnit, shouldExchange, dataDateTime := l.dbDAO.Get(from, to); !l.hasStoredValueExpired(dataDateTime) {
+			return l.createResponse(oneUnit, shouldExchange, dataDateTime), nil
+		}
+	}
+
+	oneUnit, shouldExchange, dataDateTime, err := l.getAndStoreNewValues(from, to)
+	if err != nil {
+		return nil, err
+	}
+	return l.createResponse(oneUnit, shouldExchange, dataDateTime), nil
+}
+
+// leaseBatchRefresh - Split the expired pairs of a batch into the
+//					   ones to fetch, holding their leases, and the
+//					   ones another replica refreshes. Pairs another
+//					   replica refreshed before the lease was
+//					   acquired are served from the DB.
+func (l *localExchangeRateService) leaseBatchRefresh(from string, expired []*ExchangeRateBatchResponse) ([]string, []*ExchangeRateBatchResponse, []lease.Lease) {
+	fetch := []string{}
+	others := []*ExchangeRateBatchResponse{}
+	leases := []lease.Lease{}
+	for _, r := range expired {
+		refreshLease, acquired := l.acquireRefresh(from, r.To)
+		if !acquired {
+			others = append(others, r)
+			continue
+		}
+		leases = append(leases, refreshLease)
+
+		if l.locker != nil {
+			if oneUnit, shouldExchange, dataDateTime := l.dbDAO.Get(from, r.To); !l.hasStoredValueExpired(dataDateTime) {
+				r.Response = l.createResponse(oneUnit, shouldExchange, dataDateTime)
+				continue
+			}
+		}
+		fetch = append(fetch, r.To)
+	}
+	return fetch, others, leases
+}
+
+// acquireRefresh - Whether to refresh the rate between from and to,
+//					which is always true without a locker or when it
+//					fails. The lease must be released.
+func (l *localExchangeRateService) acquireRefresh(from, to string) (lease.Lease, bool) {
+	if l.locker == nil {
+		return nil, true
+	}
+
+	refreshLease, acquired, err := l.locker.TryAcquire(refreshLeasePrefix+from+to, l.leaseConfig.TTL)
+	if err != nil {
+		log.Printf("Refreshing '%s%s' without its lease: %s\n", from, to, err)
+		return nil, true
+	}
+	return refreshLease, acquired
+}
+
+// waitForRefresh - Poll the DB for the rate between from and to,
+//					which the holder of its lease stores, until
+//					deadline
+func (l *localExchangeRateService) waitForRefresh(from, to string, deadline <-chan time.Time) (*ExchangeRateServiceResponse, error) {
+	for {
+		poll := l.clock.NewTimer(l.leaseConfig.Poll)
+		select {
+		case <-poll.C():
+		case <-deadline:
+			poll.Stop()
+			return nil, errors.New("Timed out waiting for another replica to complete network request")
+		}
+
+		if oneUnit, shouldExchange, dataDateTime := l.dbDAO.Get(from, to); !l.hasStoredValueExpired(dataDateTime) {
+			return l.createResponse(oneUnit, shouldExchange, dataDateTime), nil
+		}
+	}
+}
+
+func releaseRefresh(refreshLease lease.Lease) {
+	if refreshLease == nil {
+		return
+	}
+	if err := refreshLease.Release(); err != nil {
+		log.Printf("Cannot release a refresh lease: %s\n", err)
+	}
+}
+
+func contains(currencies []string, currency string) bool {
+	for _, c := range currencies {
+		if c == currency {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader - Header used to pass the request ID in and
+//					 out of the server
+const RequestIDHeader = "X-Request-ID"
+
+const requestIDKey = "requestID"
+
+// RequestID - Middleware that gives every request an ID. An ID
+//			   passed in by the caller is kept, otherwise a new
+//			   one is generated. The ID is returned in the
+//			   response headers.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+		c.Set(requestIDKey, id)
+		c.Header(RequestIDHeader, id)
+		c.Next()
+	}
+}
+
+// GetRequestID - Get the ID of the request. If the RequestID
+//				  middleware has not run then one is created.
+func GetRequestID(c *gin.Context) string {
+	if id := c.GetString(requestIDKey); id != "" {
+		return id
+	}
+
+	id := c.GetHeader(RequestIDHeader)
+	if id == "" {
+		id = newRequestID()
+	}
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
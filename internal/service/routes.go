@@ -0,0 +1,190 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+// Route - A route declared by an Endpoint. Path is relative to
+//		   the group of its Version, so `/exchange` in `v1` is
+//		   served at `/v1/exchange`. An empty Version serves the
+//		   route from the root. Middleware only runs for this
+//		   route, after the server's middleware. Doc describes
+//		   the route in the OpenAPI document.
+type Route struct {
+	Method     string
+	Version    string
+	Path       string
+	Middleware []gin.HandlerFunc
+	Handler    gin.HandlerFunc
+	Doc        func(d *openapi.Document) *openapi.Operation
+}
+
+// Endpoint - Declares the routes that it serves
+type Endpoint interface {
+	Routes() []Route
+}
+
+// FullPath - The path that the route is served at
+func (r Route) FullPath() string {
+	if r.Version == "" {
+		return r.Path
+	}
+	return "/" + r.Version + r.Path
+}
+
+// Mount - Serve the routes of every endpoint from their version
+//		   group. Nothing is mounted if two routes have the same
+//		   method and path. Other conflicts that gin finds, such
+//		   as /a/:id and /a/:name/b, are also returned as errors.
+func Mount(engine *gin.Engine, endpoints ...Endpoint) (err error) {
+	routes, err := collectRoutes(endpoints)
+	if err != nil {
+		return err
+	}
+
+	// gin panics on routes that conflict in ways not found by
+	// collectRoutes, such as /a/:id and /a/:name
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New(fmt.Sprintf("Cannot mount routes: %v", r))
+		}
+	}()
+
+	groups := make(map[string]gin.IRoutes)
+	for _, r := range routes {
+		group, exists := groups[r.Version]
+		if !exists {
+			group = engine
+			if r.Version != "" {
+				group = engine.Group("/" + r.Version)
+			}
+			groups[r.Version] = group
+		}
+
+		handlers := append(append([]gin.HandlerFunc{}, r.Middleware...), r.Handler)
+		group.Handle(r.Method, r.Path, handlers...)
+	}
+
+	return nil
+}
+
+// DocumentRoutes - Add the routes of every endpoint that have a
+//					Doc to the OpenAPI document
+func DocumentRoutes(d *openapi.Document, endpoints ...Endpoint) {
+	for _, e := range endpoints {
+		for _, r := range e.Routes() {
+			if r.Doc != nil {
+				d.AddOperation(r.Method, openapi.Path(r.FullPath()), r.Doc(d))
+			}
+		}
+	}
+}
+
+// collectRoutes - Every route, ordered by path and then method,
+//				   or an error naming the first duplicate
+func collectRoutes(endpoints []Endpoint) ([]Route, error) {
+	routes := []Route{}
+	seen := make(map[string]bool)
+	for _, e := range endpoints {
+		for _, r := range e.Routes() {
+			if r.Method == "" || !strings.HasPrefix(r.Path, "/") || r.Handler == nil {
+				return nil, errors.New(fmt.Sprintf("Route '%s %s' needs a method, a path starting with / and a handler", r.Method, r.FullPath()))
+			}
+
+			key := r.Method + " " + routePattern(r.FullPath())
+			if seen[key] {
+				return nil, errors.New(fmt.Sprintf("Duplicate route '%s %s'", r.Method, r.FullPath()))
+			}
+			seen[key] = true
+			routes = append(routes, r)
+		}
+	}
+
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].FullPath() != routes[j].FullPath() {
+			return routes[i].FullPath() < routes[j].FullPath()
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes, nil
+}
+
+// routePattern - The path with parameter names removed, so that
+//				  /alerts/:id and /alerts/:name are the same
+func routePattern(path string) string {
+	parts := strings.Split(path, "/")
+	for i, p := range parts {
+		if strings.HasPrefix(p, ":") || strings.HasPrefix(p, "*") {
+			parts[i] = p[:1]
+		}
+	}
+	return strings.Join(parts, "/")
+}
+
+type serverEndpoint struct {
+	doc    *openapi.Document
+	routes []api.RouteResponse
+}
+
+// Routes - `/openapi.json` and `/routes`
+func (s *serverEndpoint) Routes() []Route {
+	return []Route{
+		{Method: "GET", Path: "/openapi.json", Handler: s.getOpenAPI, Doc: s.documentGetOpenAPI},
+		{Method: "GET", Path: "/routes", Handler: s.listRoutes, Doc: s.documentListRoutes},
+	}
+}
+
+func (s *serverEndpoint) getOpenAPI(c *gin.Context) {
+	c.JSON(200, s.doc)
+}
+
+func (s *serverEndpoint) listRoutes(c *gin.Context) {
+	c.JSON(200, api.RouteListResponse{Routes: s.routes})
+}
+
+// documentGetOpenAPI - Describe `/openapi.json` in the OpenAPI
+//						document
+func (s *serverEndpoint) documentGetOpenAPI(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get this OpenAPI document",
+		OperationID: "getOpenAPI",
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "OpenAPI 3 document",
+				Content: map[string]*openapi.MediaType{"application/json": {Schema: &openapi.Schema{Type: "object"}}}},
+		},
+	}
+}
+
+// documentListRoutes - Describe `/routes` in the OpenAPI document
+func (s *serverEndpoint) documentListRoutes(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "List every route served, ordered by path and method",
+		OperationID: "listRoutes",
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Routes", Content: d.JSONContent(api.RouteListResponse{})},
+		},
+	}
+}
+
+// describeRoutes - The routes of every endpoint with their
+//					summary from the OpenAPI document
+func describeRoutes(d *openapi.Document, endpoints []Endpoint) []api.RouteResponse {
+	routes, _ := collectRoutes(endpoints)
+	described := []api.RouteResponse{}
+	for _, r := range routes {
+		route := api.RouteResponse{Method: r.Method, Path: r.FullPath(), Version: r.Version}
+		if op := d.Operation(r.Method, openapi.Path(r.FullPath())); op != nil {
+			route.OperationID = op.OperationID
+			route.Summary = op.Summary
+		}
+		described = append(described, route)
+	}
+	return described
+}
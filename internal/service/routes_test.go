@@ -0,0 +1,179 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestMount(t *testing.T) {
+	t.Run("ensure routes are served from their version group", func(t *testing.T) {
+		// given
+		router := gin.New()
+
+		// when
+		err := service.Mount(router, givenEndpoint(
+			service.Route{Method: "GET", Version: "v1", Path: "/test", Handler: givenStatusHandler(201)},
+			service.Route{Method: "GET", Version: "v2", Path: "/test", Handler: givenStatusHandler(202)},
+			service.Route{Method: "GET", Path: "/test", Handler: givenStatusHandler(203)}))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, givenMountedRequest(router, "GET", "/v1/test").Code == 201)
+		util.AssertTrue(t, givenMountedRequest(router, "GET", "/v2/test").Code == 202)
+		util.AssertTrue(t, givenMountedRequest(router, "GET", "/test").Code == 203)
+	})
+
+	t.Run("ensure route middleware only runs for the route", func(t *testing.T) {
+		// given
+		router := gin.New()
+		header := func(c *gin.Context) { c.Header("X-Test", "true") }
+
+		// when
+		err := service.Mount(router, givenEndpoint(
+			service.Route{Method: "GET", Version: "v1", Path: "/with", Middleware: []gin.HandlerFunc{header}, Handler: givenStatusHandler(200)},
+			service.Route{Method: "GET", Version: "v1", Path: "/without", Handler: givenStatusHandler(200)}))
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, givenMountedRequest(router, "GET", "/v1/with").Header().Get("X-Test") == "true")
+		util.AssertTrue(t, givenMountedRequest(router, "GET", "/v1/without").Header().Get("X-Test") == "")
+	})
+
+	t.Run("ensure duplicate routes across endpoints are an error", func(t *testing.T) {
+		// given
+		router := gin.New()
+		first := givenEndpoint(service.Route{Method: "GET", Version: "v1", Path: "/alerts/:id", Handler: givenStatusHandler(200)})
+		second := givenEndpoint(service.Route{Method: "GET", Version: "v1", Path: "/alerts/:name", Handler: givenStatusHandler(200)})
+
+		// when
+		err := service.Mount(router, first, second)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		util.AssertTrue(t, len(router.Routes()) == 0)
+	})
+
+	t.Run("ensure conflicting routes are an error instead of a panic", func(t *testing.T) {
+		// given
+		router := gin.New()
+
+		// when
+		err := service.Mount(router, givenEndpoint(
+			service.Route{Method: "GET", Version: "v1", Path: "/alerts/:id", Handler: givenStatusHandler(200)},
+			service.Route{Method: "GET", Version: "v1", Path: "/alerts/:name/deliveries", Handler: givenStatusHandler(200)}))
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure incomplete routes are an error", func(t *testing.T) {
+		// when
+		errMethod := service.Mount(gin.New(), givenEndpoint(service.Route{Path: "/test", Handler: givenStatusHandler(200)}))
+		errPath := service.Mount(gin.New(), givenEndpoint(service.Route{Method: "GET", Path: "test", Handler: givenStatusHandler(200)}))
+		errHandler := service.Mount(gin.New(), givenEndpoint(service.Route{Method: "GET", Path: "/test"}))
+
+		// then
+		util.AssertErrorNotNil(t, errMethod)
+		util.AssertErrorNotNil(t, errPath)
+		util.AssertErrorNotNil(t, errHandler)
+	})
+
+	t.Run("ensure routes are documented at their full path", func(t *testing.T) {
+		// given
+		d := openapi.CreateNewDocument("test", "1")
+		doc := func(d *openapi.Document) *openapi.Operation { return &openapi.Operation{OperationID: "getAlert"} }
+
+		// when
+		service.DocumentRoutes(d, givenEndpoint(service.Route{Method: "GET", Version: "v1", Path: "/alerts/:id", Handler: givenStatusHandler(200), Doc: doc}))
+
+		// then
+		util.AssertTrue(t, d.Operation("GET", "/v1/alerts/{id}") != nil)
+	})
+}
+
+func TestServerRoutes(t *testing.T) {
+	t.Run("ensure every route is listed at /routes", func(t *testing.T) {
+		// given
+		doc := func(d *openapi.Document) *openapi.Operation { return &openapi.Operation{OperationID: "getTest", Summary: "Test"} }
+		baseURL := givenRunningServer(t, givenEndpoint(service.Route{Method: "GET", Version: "v1", Path: "/test", Handler: givenStatusHandler(200), Doc: doc}))
+
+		// when
+		resp, err := http.Get(baseURL + "/routes")
+
+		// then
+		util.AssertErrorNil(t, err)
+		body := api.RouteListResponse{}
+		util.AssertErrorNil(t, json.NewDecoder(resp.Body).Decode(&body))
+		util.AssertTrue(t, len(body.Routes) == 3)
+		util.AssertTrue(t, body.Routes[0].Path == "/openapi.json")
+		util.AssertTrue(t, body.Routes[1].Path == "/routes")
+		util.AssertTrue(t, body.Routes[2] == api.RouteResponse{Method: "GET", Path: "/v1/test", Version: "v1", OperationID: "getTest", Summary: "Test"})
+	})
+
+	t.Run("ensure duplicate routes stop the server from running", func(t *testing.T) {
+		// given
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://127.0.0.1:0")
+		server.Register(givenEndpoint(service.Route{Method: "GET", Path: "/routes", Handler: givenStatusHandler(200)}))
+
+		// when
+		err := server.Run(context.Background())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+}
+
+type testEndpoint struct {
+	routes []service.Route
+}
+
+func (e *testEndpoint) Routes() []service.Route {
+	return e.routes
+}
+
+func givenEndpoint(routes ...service.Route) *testEndpoint {
+	return &testEndpoint{routes}
+}
+
+func givenStatusHandler(status int) gin.HandlerFunc {
+	return func(c *gin.Context) { c.Status(status) }
+}
+
+func givenMountedRequest(router *gin.Engine, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func givenRunningServer(t *testing.T, endpoints ...service.Endpoint) string {
+	t.Helper()
+
+	server := service.CreateNewServer()
+	server.ListenOn("tcp://127.0.0.1:0")
+	server.Register(endpoints...)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	<-server.Ready()
+	if len(server.Addrs()) == 0 {
+		t.Fatalf("cannot run server: %s", <-done)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return "http://" + server.Addrs()[0].String()
+}
@@ -2,79 +2,225 @@ package service
 
 import (
 	"context"
-	"fmt"
+	"crypto/tls"
 	"log"
+	"net"
 	"net/http"
-	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 	"time"
 
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
 	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
 )
 
-type Endpoint interface {
-	PerformRequest(r *gin.Engine)
-}
-
 type exchangeServer struct {
-	endpoints map[string]Endpoint
-	srv       *http.Server
+	endpoints   []Endpoint
+	middlewares []gin.HandlerFunc
+	rateLimit   gin.HandlerFunc
+	tlsConfig   *tls.Config
+	listenSpecs []string
+	ready       chan struct{}
+	readyOnce   sync.Once
+	mu          sync.Mutex
+	addrs       []net.Addr
+	srv         *http.Server
 }
 
+// ShutdownTimeout - How long in flight requests are given to
+//					 finish once the server is stopped
+const ShutdownTimeout = 5 * time.Second
+
 // CreateNewServer - Creates a new server that will
 //					 respond to requests.
 func CreateNewServer() *exchangeServer {
-	return &exchangeServer{make(map[string]Endpoint), nil}
+	return &exchangeServer{listenSpecs: []string{DefaultListenSpec},
+		ready: make(chan struct{})}
+}
+
+// Use - Add middleware that runs before every endpoint,
+//		 after the request ID has been set
+func (s *exchangeServer) Use(m ...gin.HandlerFunc) {
+	s.middlewares = append(s.middlewares, m...)
 }
 
 // Register - Register the end points so the server
-//			  can perform the requests against the
-//			  registered requests.
-func (s *exchangeServer) Register(name string, e Endpoint) {
-	s.endpoints[name] = e
+//			  can serve the routes that they declare.
+//			  Duplicate routes are reported by Run.
+func (s *exchangeServer) Register(e ...Endpoint) {
+	s.endpoints = append(s.endpoints, e...)
 }
 
-func (s *exchangeServer) Start() {
-	gin.SetMode(gin.ReleaseMode)
-	router := gin.Default()
+// LimitRate - Limit the requests of every client and of the
+//			   server as a whole. The limits are checked before
+//			   any other middleware. The limiter is returned so
+//			   that other transports can share its limits.
+func (s *exchangeServer) LimitRate(config RateLimitConfig, clock util.Clock) *rateLimiter {
+	limiter := CreateNewRateLimiter(config, clock)
+	s.rateLimit = limiter.Middleware()
+	return limiter
+}
+
+// UseTLS - Serve HTTPS with the config, see TLSConfig
+func (s *exchangeServer) UseTLS(config *tls.Config) {
+	s.tlsConfig = config
+}
+
+// ListenOn - Serve on every listen spec instead of the default
+//			  `tcp://:8080`, see Listen for the specs
+func (s *exchangeServer) ListenOn(specs ...string) {
+	s.listenSpecs = specs
+}
+
+// Document - Build the OpenAPI document from the registered
+//			  endpoints
+func (s *exchangeServer) Document() *openapi.Document {
+	d := openapi.CreateNewDocument("Exchange Rate Server", "1")
+	DocumentRoutes(d, append(s.endpoints, &serverEndpoint{})...)
+	return d
+}
+
+// Start - Run the server until SIGINT or SIGTERM is received.
+//			Returns an error if the server cannot listen or
+//			stops serving.
+func (s *exchangeServer) Start() error {
+	// kill (no param) default send syscall.SIGTERM
+	// kill -2 is syscall.SIGINT
+	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	return s.Run(ctx)
+}
 
-	for name, e := range s.endpoints {
-		fmt.Println("Key:", name, "Value:", e)
-		e.PerformRequest(router)
+// Run - Serve on every listen spec until ctx is done, then shut
+//		 down gracefully. Returns an error if any spec cannot be
+//		 listened on or a listener stops serving. A server can
+//		 only be run once.
+func (s *exchangeServer) Run(ctx context.Context) error {
+	// Nothing waiting on Ready is left waiting when Run cannot
+	// listen
+	defer s.markReady()
+
+	router, err := s.createRouter()
+	if err != nil {
+		return err
+	}
+
+	listeners := []net.Listener{}
+	for _, spec := range s.listenSpecs {
+		l, err := Listen(spec)
+		if err != nil {
+			closeListeners(listeners)
+			return err
+		}
+		listeners = append(listeners, l...)
 	}
 
+	s.mu.Lock()
 	s.srv = &http.Server{
-		Addr:    ":8080",
-		Handler: router,
+		Handler:     router,
+		TLSConfig:   s.tlsConfig,
+		ConnContext: withUnixPeer,
+	}
+	withShutdown(s.srv)
+	for _, l := range listeners {
+		s.addrs = append(s.addrs, l.Addr())
 	}
+	s.mu.Unlock()
+	s.markReady()
 
-	go func() {
-		// service connections
-		if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			// log.Fatalf("listen: %s\n", err)
+	errs := make(chan error, len(listeners))
+	for _, l := range listeners {
+		log.Printf("Listening on %s %s\n", l.Addr().Network(), l.Addr())
+		go func(l net.Listener) {
+			// service connections
+			errs <- s.serve(l)
+		}(l)
+	}
+
+	select {
+	case <-ctx.Done():
+		return s.Stop(ShutdownTimeout)
+	case err := <-errs:
+		if err == http.ErrServerClosed {
+			// Stop was called
+			return nil
 		}
-	}()
+		s.Stop(ShutdownTimeout)
+		return errors.Wrap(err, "Server stopped serving")
+	}
+}
 
-	// Wait for interrupt signal to gracefully shutdown the server with
-	// a timeout of 5 seconds.
-	quit := make(chan os.Signal, 1)
-	// kill (no param) default send syscall.SIGTERM
-	// kill -2 is syscall.SIGINT
-	// kill -9 is syscall.SIGKILL but can't be catch, so don't need add it
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
-	<-quit
-	s.Stop(5 * time.Second)
+// Ready - Closed once Run is listening on every spec, or once it
+//		   returns. Addrs is empty when it could not listen.
+func (s *exchangeServer) Ready() <-chan struct{} {
+	return s.ready
 }
 
-func (s *exchangeServer) Stop(timeout time.Duration) {
+func (s *exchangeServer) markReady() {
+	s.readyOnce.Do(func() { close(s.ready) })
+}
+
+// Addrs - The addresses being listened on, which is how the
+//		   port of a `tcp://127.0.0.1:0` spec can be found once
+//		   the server is Ready. Empty when Run could not listen.
+func (s *exchangeServer) Addrs() []net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]net.Addr{}, s.addrs...)
+}
+
+func (s *exchangeServer) createRouter() (*gin.Engine, error) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.Default()
+	router.Use(RequestID())
+	if s.rateLimit != nil {
+		router.Use(s.rateLimit)
+	}
+	router.Use(s.middlewares...)
+
+	builtin := &serverEndpoint{doc: s.Document()}
+	endpoints := append(append([]Endpoint{}, s.endpoints...), builtin)
+	builtin.routes = describeRoutes(builtin.doc, endpoints)
+
+	if err := Mount(router, endpoints...); err != nil {
+		return nil, err
+	}
+
+	return router, nil
+}
+
+func (s *exchangeServer) serve(l net.Listener) error {
+	if s.tlsConfig != nil {
+		// The certificate comes from TLSConfig.GetCertificate
+		return s.srv.ServeTLS(l, "", "")
+	}
+	return s.srv.Serve(l)
+}
+
+// Stop - Shut down gracefully, waiting up to timeout for in
+//		  flight requests
+func (s *exchangeServer) Stop(timeout time.Duration) error {
+	s.mu.Lock()
+	srv := s.srv
+	s.mu.Unlock()
+	if srv == nil {
+		return nil
+	}
+
 	log.Println("Shutdown Server ...")
 
 	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
-	if err := s.srv.Shutdown(ctx); err != nil {
-		log.Fatal("Server Shutdown: ", err)
+	if err := srv.Shutdown(ctx); err != nil {
+		return errors.Wrap(err, "Server Shutdown")
 	}
 
 	log.Println("Server exiting")
+	return nil
 }
@@ -0,0 +1,106 @@
+package service_test
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestServerRun(t *testing.T) {
+	t.Run("ensure the server is served on an ephemeral port until cancelled", func(t *testing.T) {
+		// given
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://127.0.0.1:0")
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- server.Run(ctx) }()
+		<-server.Ready()
+
+		// when
+		resp, err := http.Get("http://" + server.Addrs()[0].String() + "/openapi.json")
+		cancel()
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 200)
+		select {
+		case err := <-done:
+			util.AssertErrorNil(t, err)
+		case <-time.After(time.Second * 5):
+			t.Fatal("server did not stop when cancelled")
+		}
+	})
+
+	t.Run("ensure an address in use is returned as an error", func(t *testing.T) {
+		// given
+		taken, err := net.Listen("tcp", "127.0.0.1:0")
+		util.AssertErrorNil(t, err)
+		defer taken.Close()
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://" + taken.Addr().String())
+
+		// when
+		err = server.Run(context.Background())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure Ready is closed without addresses when a spec cannot be listened on", func(t *testing.T) {
+		// given
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://127.0.0.1:0", "udp://:53")
+
+		// when
+		err := server.Run(context.Background())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		select {
+		case <-server.Ready():
+		case <-time.After(time.Second):
+			t.Fatal("Ready was not closed")
+		}
+		util.AssertTrue(t, len(server.Addrs()) == 0)
+	})
+
+	t.Run("ensure listeners are closed when a later spec fails", func(t *testing.T) {
+		// given
+		free, err := net.Listen("tcp", "127.0.0.1:0")
+		util.AssertErrorNil(t, err)
+		addr := free.Addr().String()
+		free.Close()
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://"+addr, "udp://:53")
+
+		// when
+		err = server.Run(context.Background())
+
+		// then
+		util.AssertErrorNotNil(t, err)
+		again, err := net.Listen("tcp", addr)
+		util.AssertErrorNil(t, err)
+		again.Close()
+	})
+
+	t.Run("ensure Stop ends Run without an error", func(t *testing.T) {
+		// given
+		server := service.CreateNewServer()
+		server.ListenOn("tcp://127.0.0.1:0")
+		done := make(chan error, 1)
+		go func() { done <- server.Run(context.Background()) }()
+		<-server.Ready()
+
+		// when
+		err := server.Stop(time.Second)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertErrorNil(t, <-done)
+	})
+}
@@ -0,0 +1,31 @@
+package service
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"sync"
+)
+
+type shutdownKey struct{}
+
+// withShutdown - Makes the BaseContext of srv carry a channel that
+//				  is closed once srv starts to shut down
+func withShutdown(srv *http.Server) {
+	shutdown := make(chan struct{})
+	var once sync.Once
+	srv.RegisterOnShutdown(func() { once.Do(func() { close(shutdown) }) })
+
+	base := context.WithValue(context.Background(), shutdownKey{}, shutdown)
+	srv.BaseContext = func(net.Listener) context.Context { return base }
+}
+
+// ShuttingDown - Closed once the server serving the request of ctx
+//				  starts to shut down. Handlers that stream until
+//				  their client goes away end on it, as the server
+//				  waits for them otherwise. Nil, so never closed,
+//				  when ctx was not served by a server.
+func ShuttingDown(ctx context.Context) <-chan struct{} {
+	shutdown, _ := ctx.Value(shutdownKey{}).(chan struct{})
+	return shutdown
+}
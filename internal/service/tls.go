@@ -0,0 +1,171 @@
+package service
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig - Certificate files and settings to serve TLS with.
+//			   The certificate and key are reloaded when either
+//			   file changes. Client certificates are verified
+//			   against ClientCAFile when it is set, and required
+//			   when RequireClientCert is also set.
+type TLSConfig struct {
+	CertFile          string
+	KeyFile           string
+	ClientCAFile      string
+	RequireClientCert bool
+	MinVersion        uint16
+	CipherSuites      []uint16
+}
+
+type certReloader struct {
+	certFile string
+	keyFile  string
+	mu       sync.Mutex
+	cert     *tls.Certificate
+	modTime  time.Time
+}
+
+// ServerConfig - Build the tls.Config to serve with
+func (c TLSConfig) ServerConfig() (*tls.Config, error) {
+	if c.CertFile == "" || c.KeyFile == "" {
+		return nil, errors.New("Both a certificate and a key file are needed for TLS")
+	}
+
+	reloader := &certReloader{certFile: c.CertFile, keyFile: c.KeyFile}
+	if _, err := reloader.getCertificate(nil); err != nil {
+		return nil, err
+	}
+
+	config := &tls.Config{
+		GetCertificate: reloader.getCertificate,
+		MinVersion:     c.MinVersion,
+		CipherSuites:   c.CipherSuites,
+	}
+	if config.MinVersion == 0 {
+		config.MinVersion = tls.VersionTLS12
+	}
+
+	if c.ClientCAFile != "" {
+		pem, err := ioutil.ReadFile(c.ClientCAFile)
+		if err != nil {
+			return nil, errors.Wrap(err, fmt.Sprintf("Cannot read client CA bundle '%s'", c.ClientCAFile))
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New(fmt.Sprintf("No certificates found in client CA bundle '%s'", c.ClientCAFile))
+		}
+		config.ClientCAs = pool
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+		if c.RequireClientCert {
+			config.ClientAuth = tls.RequireAndVerifyClientCert
+		}
+	} else if c.RequireClientCert {
+		return nil, errors.New("A client CA bundle is needed to require client certificates")
+	}
+
+	return config, nil
+}
+
+// ParseTLSVersion - Parse a TLS version such as `1.2`
+func ParseTLSVersion(version string) (uint16, error) {
+	switch version {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	case "1.0", "1.1":
+		return 0, errors.New(fmt.Sprintf("TLS %s is insecure and not supported", version))
+	}
+	return 0, errors.New(fmt.Sprintf("Unknown TLS version '%s'", version))
+}
+
+// ParseCipherSuites - Parse a comma separated list of cipher
+//					   suite names such as
+//					   `TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256`. Only
+//					   secure suites are accepted. An empty list
+//					   keeps Go's defaults.
+func ParseCipherSuites(list string) ([]uint16, error) {
+	known := make(map[string]uint16)
+	for _, s := range tls.CipherSuites() {
+		known[s.Name] = s.ID
+	}
+
+	suites := []uint16{}
+	for _, name := range strings.Split(list, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		id, exists := known[name]
+		if !exists {
+			return nil, errors.New(fmt.Sprintf("Unknown or insecure cipher suite '%s'", name))
+		}
+		suites = append(suites, id)
+	}
+
+	if len(suites) == 0 {
+		return nil, nil
+	}
+	return suites, nil
+}
+
+// getCertificate - Reload the certificate when the certificate
+//					or key file has been modified since it was
+//					last loaded
+func (r *certReloader) getCertificate(_ *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	modTime, err := latestModTime(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			// Keep serving the old certificate while the files
+			// are being replaced
+			log.Printf("TLS certificate not reloaded: %s\n", err)
+			return r.cert, nil
+		}
+		return nil, err
+	}
+
+	if r.cert != nil && modTime.Equal(r.modTime) {
+		return r.cert, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		if r.cert != nil {
+			log.Printf("TLS certificate not reloaded: %s\n", err)
+			return r.cert, nil
+		}
+		return nil, errors.Wrap(err, "Cannot load TLS certificate")
+	}
+
+	r.cert = &cert
+	r.modTime = modTime
+	return r.cert, nil
+}
+
+func latestModTime(files ...string) (time.Time, error) {
+	latest := time.Time{}
+	for _, f := range files {
+		info, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, errors.Wrap(err, fmt.Sprintf("Cannot stat '%s'", f))
+		}
+		if info.ModTime().After(latest) {
+			latest = info.ModTime()
+		}
+	}
+	return latest, nil
+}
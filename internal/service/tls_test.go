@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestTLSConfig(t *testing.T) {
+	t.Run("ensure HTTPS is served with the certificate", func(t *testing.T) {
+		// given
+		ca := givenCA(t)
+		dir := givenCertFiles(t, ca, "server-1")
+		addr := givenTLSServer(t, service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")})
+
+		// when
+		resp, err := givenTLSClient(ca, nil).Get("https://" + addr)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 200)
+		util.AssertTrue(t, resp.TLS.PeerCertificates[0].Subject.CommonName == "server-1")
+	})
+
+	t.Run("ensure a rotated certificate is reloaded", func(t *testing.T) {
+		// given
+		ca := givenCA(t)
+		dir := givenCertFiles(t, ca, "server-1")
+		addr := givenTLSServer(t, service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem")})
+		givenTLSClient(ca, nil).Get("https://" + addr)
+		writeCertFiles(t, dir, ca, "server-2")
+		later := time.Now().Add(time.Minute)
+		os.Chtimes(filepath.Join(dir, "cert.pem"), later, later)
+
+		// when
+		resp, err := givenTLSClient(ca, nil).Get("https://" + addr)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.TLS.PeerCertificates[0].Subject.CommonName == "server-2")
+	})
+
+	t.Run("ensure client certificates are required with mTLS", func(t *testing.T) {
+		// given
+		ca := givenCA(t)
+		dir := givenCertFiles(t, ca, "server")
+		config := service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"),
+			KeyFile:           filepath.Join(dir, "key.pem"),
+			ClientCAFile:      filepath.Join(dir, "ca.pem"),
+			RequireClientCert: true}
+		addr := givenTLSServer(t, config)
+		clientCert := ca.issue(t, "internal-caller")
+		otherCert := givenCA(t).issue(t, "outsider")
+
+		// when
+		_, errNoCert := givenTLSClient(ca, nil).Get("https://" + addr)
+		_, errOtherCert := givenTLSClient(ca, &otherCert).Get("https://" + addr)
+		resp, err := givenTLSClient(ca, &clientCert).Get("https://" + addr)
+
+		// then
+		util.AssertErrorNotNil(t, errNoCert)
+		util.AssertErrorNotNil(t, errOtherCert)
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 200)
+	})
+
+	t.Run("ensure the minimum TLS version is enforced", func(t *testing.T) {
+		// given
+		ca := givenCA(t)
+		dir := givenCertFiles(t, ca, "server")
+		addr := givenTLSServer(t, service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem"), MinVersion: tls.VersionTLS13})
+		client := givenTLSClient(ca, nil)
+		client.Transport.(*http.Transport).TLSClientConfig.MaxVersion = tls.VersionTLS12
+
+		// when
+		_, err := client.Get("https://" + addr)
+
+		// then
+		util.AssertErrorNotNil(t, err)
+	})
+
+	t.Run("ensure invalid configs are rejected", func(t *testing.T) {
+		// given
+		ca := givenCA(t)
+		dir := givenCertFiles(t, ca, "server")
+
+		// when
+		_, errMissing := service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem")}.ServerConfig()
+		_, errNoCA := service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem"), RequireClientCert: true}.ServerConfig()
+		_, errBadCA := service.TLSConfig{CertFile: filepath.Join(dir, "cert.pem"), KeyFile: filepath.Join(dir, "key.pem"), ClientCAFile: filepath.Join(dir, "key.pem")}.ServerConfig()
+
+		// then
+		util.AssertErrorNotNil(t, errMissing)
+		util.AssertErrorNotNil(t, errNoCA)
+		util.AssertErrorNotNil(t, errBadCA)
+	})
+}
+
+func TestParseTLSSettings(t *testing.T) {
+	t.Run("ensure TLS versions are parsed", func(t *testing.T) {
+		// when
+		v12, err12 := service.ParseTLSVersion("1.2")
+		v13, err13 := service.ParseTLSVersion("1.3")
+		_, err10 := service.ParseTLSVersion("1.0")
+
+		// then
+		util.AssertErrorNil(t, err12)
+		util.AssertErrorNil(t, err13)
+		util.AssertTrue(t, v12 == tls.VersionTLS12)
+		util.AssertTrue(t, v13 == tls.VersionTLS13)
+		util.AssertErrorNotNil(t, err10)
+	})
+
+	t.Run("ensure cipher suites are parsed", func(t *testing.T) {
+		// when
+		suites, err := service.ParseCipherSuites("TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384")
+		none, errNone := service.ParseCipherSuites("")
+		_, errInsecure := service.ParseCipherSuites("TLS_RSA_WITH_RC4_128_SHA")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, len(suites) == 2)
+		util.AssertTrue(t, suites[0] == tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256)
+		util.AssertErrorNil(t, errNone)
+		util.AssertTrue(t, none == nil)
+		util.AssertErrorNotNil(t, errInsecure)
+	})
+}
+
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func givenCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	util.AssertErrorNil(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	util.AssertErrorNil(t, err)
+	cert, err := x509.ParseCertificate(der)
+	util.AssertErrorNil(t, err)
+	return &testCA{cert, key, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue - A certificate for 127.0.0.1 that can be used by both
+//		   servers and clients
+func (ca *testCA) issue(t *testing.T, name string) tls.Certificate {
+	certPEM, keyPEM := ca.issuePEM(t, name)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	util.AssertErrorNil(t, err)
+	return cert
+}
+
+func (ca *testCA) issuePEM(t *testing.T, name string) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	util.AssertErrorNil(t, err)
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	util.AssertErrorNil(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	util.AssertErrorNil(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func givenCertFiles(t *testing.T, ca *testCA, name string) string {
+	dir := t.TempDir()
+	writeCertFiles(t, dir, ca, name)
+	util.AssertErrorNil(t, ioutil.WriteFile(filepath.Join(dir, "ca.pem"), ca.pem, 0600))
+	return dir
+}
+
+func writeCertFiles(t *testing.T, dir string, ca *testCA, name string) {
+	certPEM, keyPEM := ca.issuePEM(t, name)
+	util.AssertErrorNil(t, ioutil.WriteFile(filepath.Join(dir, "cert.pem"), certPEM, 0600))
+	util.AssertErrorNil(t, ioutil.WriteFile(filepath.Join(dir, "key.pem"), keyPEM, 0600))
+}
+
+func givenTLSServer(t *testing.T, config service.TLSConfig) string {
+	tlsConfig, err := config.ServerConfig()
+	util.AssertErrorNil(t, err)
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	util.AssertErrorNil(t, err)
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}), TLSConfig: tlsConfig}
+	go srv.ServeTLS(lis, "", "")
+	t.Cleanup(func() { srv.Close() })
+	return lis.Addr().String()
+}
+
+func givenTLSClient(ca *testCA, cert *tls.Certificate) *http.Client {
+	pool := x509.NewCertPool()
+	pool.AddCert(ca.cert)
+	config := &tls.Config{RootCAs: pool}
+	if cert != nil {
+		config.Certificates = []tls.Certificate{*cert}
+	}
+	// A new transport per client so that connections are not reused
+	return &http.Client{Timeout: time.Second * 5, Transport: &http.Transport{TLSClientConfig: config}}
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateLayout - Layout of the date of a historical request
+const DateLayout = "2006-01-02"
+
+// EarliestDate - The first day that rates were published
+var EarliestDate = time.Date(1999, 1, 4, 0, 0, 0, 0, time.UTC)
+
+// ValidationError - Returned when the currencies of a request
+//					 are not valid. Every API maps it to its own
+//					 "bad request" error.
+type ValidationError struct {
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return e.Reason
+}
+
+// ValidateCurrency - Check that currency is one of the valid
+//					  currencies
+func ValidateCurrency(validCurrencies map[string]bool, currency string) error {
+	if !validCurrencies[currency] {
+		return &ValidationError{fmt.Sprintf("%s is not a valid currency", currency)}
+	}
+	return nil
+}
+
+// ValidatePair - Check that from and to are valid currencies
+//				  and that they are different
+func ValidatePair(validCurrencies map[string]bool, from, to string) error {
+	if err := ValidateCurrency(validCurrencies, from); err != nil {
+		return err
+	}
+
+	if err := ValidateCurrency(validCurrencies, to); err != nil {
+		return err
+	}
+
+	if from == to {
+		return &ValidationError{fmt.Sprintf("from '%s' and to are the same, they need to be different", from)}
+	}
+
+	return nil
+}
+
+// ValidateDate - Parse a date such as 2019-10-14 and check that
+//				  it is between EarliestDate and the day before now.
+//				  The rate of the day of now can still change, so
+//				  it is not a historical rate.
+func ValidateDate(date string, now time.Time) (time.Time, error) {
+	d, err := time.Parse(DateLayout, date)
+	if err != nil {
+		return time.Time{}, &ValidationError{fmt.Sprintf("date '%s' is not in the format %s", date, DateLayout)}
+	}
+
+	if d.Before(EarliestDate) {
+		return time.Time{}, &ValidationError{fmt.Sprintf("date '%s' is before the first rates on %s", date, EarliestDate.Format(DateLayout))}
+	}
+
+	y, m, day := now.UTC().Date()
+	if !d.Before(time.Date(y, m, day, 0, 0, 0, 0, time.UTC)) {
+		return time.Time{}, &ValidationError{fmt.Sprintf("date '%s' is not before today", date)}
+	}
+
+	return d, nil
+}
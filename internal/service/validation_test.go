@@ -0,0 +1,44 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestValidateDate(t *testing.T) {
+	now := time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+
+	t.Run("ensure a past date is parsed", func(t *testing.T) {
+		// when
+		d, err := service.ValidateDate("2019-10-01", now)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, d.Equal(time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("ensure the first day and the day before now are valid", func(t *testing.T) {
+		// when
+		_, firstErr := service.ValidateDate("1999-01-04", now)
+		_, yesterdayErr := service.ValidateDate("2019-10-13", now)
+
+		// then
+		util.AssertErrorNil(t, firstErr)
+		util.AssertErrorNil(t, yesterdayErr)
+	})
+
+	t.Run("ensure invalid dates are rejected", func(t *testing.T) {
+		for _, date := range []string{"14-10-2019", "2019-13-01", "", "1999-01-03", "2019-10-14", "2019-10-15"} {
+			// when
+			_, err := service.ValidateDate(date, now)
+
+			// then
+			if _, ok := err.(*service.ValidationError); !ok {
+				t.Fatalf("expected a validation error for '%s' but actual is %v", date, err)
+			}
+		}
+	})
+}
@@ -2,16 +2,55 @@ package util
 
 import "time"
 
-// Clock - wrapper around built in time
-type Clock struct {
+// Clock - Tells the time and waits for it to pass. The system
+//		   clock is used when serving, a FakeClock in tests.
+type Clock interface {
+	Now() time.Time
+	After(d time.Duration) <-chan time.Time
+	NewTimer(d time.Duration) Timer
 }
 
-// CreateNewClock - Create a new instance of Clock
-func CreateNewClock() *Clock {
-	return &Clock{}
+// Timer - Fires once on C after a duration unless it is stopped.
+//		   Stop a timer that may not be waited for, as a
+//		   FakeClock counts it as waiting until then.
+type Timer interface {
+	C() <-chan time.Time
+	Stop() bool
+}
+
+type systemClock struct {
+}
+
+// CreateNewClock - Create a new instance of the system Clock
+func CreateNewClock() *systemClock {
+	return &systemClock{}
 }
 
 // Now - uses built in time.Now()
-func (c *Clock) Now() time.Time {
+func (c *systemClock) Now() time.Time {
 	return time.Now()
 }
+
+// After - uses built in time.After()
+func (c *systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// NewTimer - uses built in time.NewTimer()
+func (c *systemClock) NewTimer(d time.Duration) Timer {
+	return &systemTimer{timer: time.NewTimer(d)}
+}
+
+type systemTimer struct {
+	timer *time.Timer
+}
+
+// C - Fires once the duration has passed
+func (t *systemTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+// Stop - Prevent the timer from firing, false if it already has
+func (t *systemTimer) Stop() bool {
+	return t.timer.Stop()
+}
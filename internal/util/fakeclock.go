@@ -0,0 +1,132 @@
+package util
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock - A Clock that only moves when it is told to, so that
+//			   expiry and timeouts are deterministic in tests
+type FakeClock struct {
+	mu      sync.Mutex
+	waiting *sync.Cond
+	now     time.Time
+	timers  []*fakeTimer
+}
+
+type fakeTimer struct {
+	clock  *FakeClock
+	fireAt time.Time
+	c      chan time.Time
+}
+
+// CreateNewFakeClock - Create a FakeClock that starts at now
+func CreateNewFakeClock(now time.Time) *FakeClock {
+	f := &FakeClock{now: now}
+	f.waiting = sync.NewCond(&f.mu)
+	return f
+}
+
+// Now - The time the clock has been set or advanced to
+func (f *FakeClock) Now() time.Time {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.now
+}
+
+// After - Fires once the clock has been advanced by d. It is
+//		   counted as waiting until then, so a wait that may be
+//		   given up should use NewTimer instead.
+func (f *FakeClock) After(d time.Duration) <-chan time.Time {
+	return f.NewTimer(d).C()
+}
+
+// NewTimer - After that is no longer waiting once it is stopped
+func (f *FakeClock) NewTimer(d time.Duration) Timer {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	t := &fakeTimer{clock: f, fireAt: f.now.Add(d), c: make(chan time.Time, 1)}
+	if d <= 0 {
+		t.c <- f.now
+		return t
+	}
+
+	f.timers = append(f.timers, t)
+	f.waiting.Broadcast()
+	return t
+}
+
+// Advance - Move the clock forward by d, firing every timer that
+//			 is due
+func (f *FakeClock) Advance(d time.Duration) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.set(f.now.Add(d))
+}
+
+// Set - Move the clock to now, firing every timer that is due.
+//		 Moving it backwards fires nothing.
+func (f *FakeClock) Set(now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.set(now)
+}
+
+// Timers - How many After calls and timers have not fired or
+//			been stopped yet
+func (f *FakeClock) Timers() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return len(f.timers)
+}
+
+// BlockUntil - Wait until n timers are waiting to fire, so
+//				that a test can advance the clock once the code
+//				under test is waiting for it
+func (f *FakeClock) BlockUntil(n int) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for len(f.timers) < n {
+		f.waiting.Wait()
+	}
+}
+
+func (f *FakeClock) set(now time.Time) {
+	f.now = now
+
+	pending := []*fakeTimer{}
+	for _, t := range f.timers {
+		if t.fireAt.After(now) {
+			pending = append(pending, t)
+			continue
+		}
+		t.c <- now
+	}
+	f.timers = pending
+}
+
+// C - Fires once the clock has been advanced past the timer
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.c
+}
+
+// Stop - Prevent the timer from firing, false if it already has
+func (t *fakeTimer) Stop() bool {
+	f := t.clock
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	for i, waiting := range f.timers {
+		if waiting == t {
+			f.timers = append(f.timers[:i:i], f.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
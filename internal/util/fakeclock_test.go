@@ -0,0 +1,89 @@
+package util_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+)
+
+func TestFakeClock(t *testing.T) {
+	now := time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+
+	t.Run("ensure time only moves when advanced or set", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(now)
+
+		// when
+		before := clock.Now()
+		clock.Advance(time.Minute)
+		advanced := clock.Now()
+		clock.Set(now.Add(time.Hour))
+
+		// then
+		util.AssertTrue(t, before.Equal(now))
+		util.AssertTrue(t, advanced.Equal(now.Add(time.Minute)))
+		util.AssertTrue(t, clock.Now().Equal(now.Add(time.Hour)))
+	})
+
+	t.Run("ensure After fires once the duration has passed", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(now)
+		c := clock.After(time.Second)
+
+		// when
+		clock.Advance(time.Millisecond * 999)
+		fired := len(c) == 1
+		clock.Advance(time.Millisecond)
+
+		// then
+		util.AssertFalse(t, fired)
+		util.AssertTrue(t, (<-c).Equal(now.Add(time.Second)))
+		util.AssertTrue(t, clock.Timers() == 0)
+	})
+
+	t.Run("ensure After of zero fires immediately", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(now)
+
+		// when
+		c := clock.After(0)
+
+		// then
+		util.AssertTrue(t, len(c) == 1)
+		util.AssertTrue(t, clock.Timers() == 0)
+	})
+
+	t.Run("ensure BlockUntil waits for After to be called", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(now)
+		fired := make(chan time.Time)
+		go func() { fired <- <-clock.After(time.Second) }()
+
+		// when
+		clock.BlockUntil(1)
+		clock.Set(now.Add(time.Minute))
+
+		// then
+		util.AssertTrue(t, (<-fired).Equal(now.Add(time.Minute)))
+	})
+
+	t.Run("ensure a stopped timer is no longer waiting and never fires", func(t *testing.T) {
+		// given
+		clock := util.CreateNewFakeClock(now)
+		timer := clock.NewTimer(time.Second)
+		kept := clock.NewTimer(time.Second)
+
+		// when
+		stopped := timer.Stop()
+		waiting := clock.Timers()
+		clock.Advance(time.Second)
+
+		// then
+		util.AssertTrue(t, stopped)
+		util.AssertTrue(t, waiting == 1)
+		util.AssertTrue(t, len(timer.C()) == 0)
+		util.AssertTrue(t, len(kept.C()) == 1)
+		util.AssertFalse(t, kept.Stop())
+	})
+}
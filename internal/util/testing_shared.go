@@ -13,9 +13,11 @@ const margin float32 = 0.0001
 type ClientMock struct {
 	Timeout  time.Duration
 	Response http.Response
+	Request  *http.Request
 }
 
 func (c *ClientMock) Do(req *http.Request) (*http.Response, error) {
+	c.Request = req
 	return &c.Response, nil
 }
 
@@ -0,0 +1,238 @@
+package v1endpoint
+
+import (
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+type v1Alerts struct {
+	alertService alert.AlertService
+}
+
+// CreateNewV1Alerts - Create a new endpoint for `/v1/alerts`
+func CreateNewV1Alerts(alertService alert.AlertService) *v1Alerts {
+	return &v1Alerts{alertService: alertService}
+}
+
+// Routes - `/v1/alerts`
+func (v *v1Alerts) Routes() []service.Route {
+	return []service.Route{
+		{Method: "POST", Version: "v1", Path: "/alerts", Handler: v.createAlert, Doc: v.documentCreateAlert},
+		{Method: "GET", Version: "v1", Path: "/alerts", Handler: v.listAlerts, Doc: v.documentListAlerts},
+		{Method: "GET", Version: "v1", Path: "/alerts/:id", Handler: v.getAlert, Doc: v.documentGetAlert},
+		{Method: "PUT", Version: "v1", Path: "/alerts/:id", Handler: v.updateAlert, Doc: v.documentUpdateAlert},
+		{Method: "DELETE", Version: "v1", Path: "/alerts/:id", Handler: v.deleteAlert, Doc: v.documentDeleteAlert},
+		{Method: "GET", Version: "v1", Path: "/alerts/:id/deliveries", Handler: v.listAlertDeliveries, Doc: v.documentListAlertDeliveries},
+	}
+}
+
+func (v *v1Alerts) createAlert(c *gin.Context) {
+	a, ok := v.bindAlert(c)
+	if !ok {
+		return
+	}
+
+	created, err := v.alertService.Create(a)
+	if err != nil {
+		v.createErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(201, toAlertResponse(created))
+}
+
+func (v *v1Alerts) listAlerts(c *gin.Context) {
+	alerts := []api.AlertResponse{}
+	for _, a := range v.alertService.List() {
+		alerts = append(alerts, toAlertResponse(a))
+	}
+	c.JSON(200, api.AlertListResponse{Alerts: alerts})
+}
+
+func (v *v1Alerts) getAlert(c *gin.Context) {
+	a, err := v.alertService.Get(c.Param("id"))
+	if err != nil {
+		v.createErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, toAlertResponse(a))
+}
+
+func (v *v1Alerts) updateAlert(c *gin.Context) {
+	a, ok := v.bindAlert(c)
+	if !ok {
+		return
+	}
+
+	updated, err := v.alertService.Update(c.Param("id"), a)
+	if err != nil {
+		v.createErrorResponse(c, err)
+		return
+	}
+
+	c.JSON(200, toAlertResponse(updated))
+}
+
+func (v *v1Alerts) deleteAlert(c *gin.Context) {
+	if err := v.alertService.Delete(c.Param("id")); err != nil {
+		v.createErrorResponse(c, err)
+		return
+	}
+
+	c.Status(204)
+}
+
+func (v *v1Alerts) listAlertDeliveries(c *gin.Context) {
+	logged, err := v.alertService.Deliveries(c.Param("id"))
+	if err != nil {
+		v.createErrorResponse(c, err)
+		return
+	}
+
+	deliveries := []api.DeliveryResponse{}
+	for _, d := range logged {
+		deliveries = append(deliveries, api.DeliveryResponse{
+			ID:          d.ID,
+			AlertID:     d.AlertID,
+			Attempt:     d.Attempt,
+			StatusCode:  d.StatusCode,
+			Error:       d.Error,
+			Success:     d.Success,
+			AttemptedAt: d.AttemptedAt.Format(endpoint.DataDateTimeLayout),
+		})
+	}
+	c.JSON(200, api.DeliveryListResponse{Deliveries: deliveries})
+}
+
+// documentCreateAlert - Describe `POST /v1/alerts` in the OpenAPI
+//						 document
+func (v *v1Alerts) documentCreateAlert(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Create an alert that calls a webhook when its condition becomes true",
+		OperationID: "createAlert",
+		RequestBody: alertRequestBody(d),
+		Responses: map[string]*openapi.Response{
+			"201": {Description: "Created alert", Content: d.JSONContent(api.AlertResponse{})},
+			"400": endpoint.DocumentErrorResponse(d, "Alert is invalid"),
+		},
+	}
+}
+
+// documentListAlerts - Describe `GET /v1/alerts` in the OpenAPI
+//						document
+func (v *v1Alerts) documentListAlerts(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "List every alert",
+		OperationID: "listAlerts",
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Alerts, oldest first", Content: d.JSONContent(api.AlertListResponse{})},
+		},
+	}
+}
+
+// documentGetAlert - Describe `GET /v1/alerts/{id}` in the OpenAPI
+//					  document
+func (v *v1Alerts) documentGetAlert(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get an alert",
+		OperationID: "getAlert",
+		Parameters:  []openapi.Parameter{alertIDParameter()},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Alert", Content: d.JSONContent(api.AlertResponse{})},
+			"404": endpoint.DocumentErrorResponse(d, "Alert does not exist"),
+		},
+	}
+}
+
+// documentUpdateAlert - Describe `PUT /v1/alerts/{id}` in the
+//						 OpenAPI document
+func (v *v1Alerts) documentUpdateAlert(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Replace an alert",
+		OperationID: "updateAlert",
+		Parameters:  []openapi.Parameter{alertIDParameter()},
+		RequestBody: alertRequestBody(d),
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Updated alert", Content: d.JSONContent(api.AlertResponse{})},
+			"400": endpoint.DocumentErrorResponse(d, "Alert is invalid"),
+			"404": endpoint.DocumentErrorResponse(d, "Alert does not exist"),
+		},
+	}
+}
+
+// documentDeleteAlert - Describe `DELETE /v1/alerts/{id}` in the
+//						 OpenAPI document
+func (v *v1Alerts) documentDeleteAlert(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Delete an alert and its delivery log",
+		OperationID: "deleteAlert",
+		Parameters:  []openapi.Parameter{alertIDParameter()},
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "Deleted"},
+			"404": endpoint.DocumentErrorResponse(d, "Alert does not exist"),
+		},
+	}
+}
+
+// documentListAlertDeliveries - Describe
+//								 `GET /v1/alerts/{id}/deliveries`
+//								 in the OpenAPI document
+func (v *v1Alerts) documentListAlertDeliveries(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get the webhook delivery attempts of an alert, oldest first",
+		OperationID: "listAlertDeliveries",
+		Parameters:  []openapi.Parameter{alertIDParameter()},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Delivery attempts", Content: d.JSONContent(api.DeliveryListResponse{})},
+			"404": endpoint.DocumentErrorResponse(d, "Alert does not exist"),
+		},
+	}
+}
+
+func alertIDParameter() openapi.Parameter {
+	return openapi.PathParameter("id", "ID of the alert")
+}
+
+func alertRequestBody(d *openapi.Document) *openapi.RequestBody {
+	return &openapi.RequestBody{Required: true, Content: d.JSONContent(api.AlertRequest{})}
+}
+
+// bindAlert - Reads the alert from the request body, writing a
+//			   bad request response if it cannot be read
+func (v *v1Alerts) bindAlert(c *gin.Context) (*alert.Alert, bool) {
+	req := api.AlertRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, "body is invalid.", err.Error())
+		return nil, false
+	}
+
+	return &alert.Alert{From: req.From,
+		To:         req.To,
+		Condition:  req.Condition,
+		Threshold:  req.Threshold,
+		WebhookURL: req.WebhookURL,
+		Secret:     req.Secret}, true
+}
+
+func (v *v1Alerts) createErrorResponse(c *gin.Context, err error) {
+	if err == alert.ErrNotFound {
+		endpoint.CreateErrorResponse(c, 404, api.ErrorCodeNotFound, "alert not found")
+		return
+	}
+	endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, "body is invalid.", err.Error())
+}
+
+func toAlertResponse(a *alert.Alert) api.AlertResponse {
+	return api.AlertResponse{ID: a.ID,
+		From:       a.From,
+		To:         a.To,
+		Condition:  a.Condition,
+		Threshold:  a.Threshold,
+		WebhookURL: a.WebhookURL,
+		CreatedAt:  a.CreatedAt.Format(endpoint.DataDateTimeLayout)}
+}
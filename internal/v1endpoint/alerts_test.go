@@ -0,0 +1,122 @@
+package v1endpoint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/alert"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestAlertsEndpoint(t *testing.T) {
+	t.Run("ensure alerts can be created, read, updated and deleted", func(t *testing.T) {
+		// given
+		server := givenAlertsServer()
+		defer server.Close()
+
+		// when
+		created := api.AlertResponse{}
+		performAlertsRequest(t, "POST", server.URL+"/v1/alerts", givenAlertRequest(1.15), 201, &created)
+		found := api.AlertResponse{}
+		performAlertsRequest(t, "GET", server.URL+"/v1/alerts/"+created.ID, "", 200, &found)
+		updated := api.AlertResponse{}
+		performAlertsRequest(t, "PUT", server.URL+"/v1/alerts/"+created.ID, givenAlertRequest(1.2), 200, &updated)
+		list := api.AlertListResponse{}
+		performAlertsRequest(t, "GET", server.URL+"/v1/alerts", "", 200, &list)
+		performAlertsRequest(t, "DELETE", server.URL+"/v1/alerts/"+created.ID, "", 204, nil)
+		performAlertsRequest(t, "GET", server.URL+"/v1/alerts/"+created.ID, "", 404, nil)
+
+		// then
+		util.AssertFalse(t, created.ID == "")
+		util.AssertTrue(t, found.ID == created.ID)
+		util.AssertEquals(t, 1.15, found.Threshold)
+		util.AssertEquals(t, 1.2, updated.Threshold)
+		util.AssertTrue(t, len(list.Alerts) == 1)
+		util.AssertEquals(t, 1.2, list.Alerts[0].Threshold)
+	})
+
+	t.Run("ensure delivery log of an alert is returned", func(t *testing.T) {
+		// given
+		server := givenAlertsServer()
+		defer server.Close()
+		created := api.AlertResponse{}
+		performAlertsRequest(t, "POST", server.URL+"/v1/alerts", givenAlertRequest(1.15), 201, &created)
+
+		// when
+		deliveries := api.DeliveryListResponse{}
+		performAlertsRequest(t, "GET", server.URL+"/v1/alerts/"+created.ID+"/deliveries", "", 200, &deliveries)
+
+		// then
+		util.AssertTrue(t, len(deliveries.Deliveries) == 0)
+	})
+
+	t.Run("ensure 400 response when the alert is invalid", func(t *testing.T) {
+		// given
+		server := givenAlertsServer()
+		defer server.Close()
+
+		// when
+		data := api.ExchangeErrorResponse{}
+		performAlertsRequest(t, "POST", server.URL+"/v1/alerts", givenAlertRequest(0), 400, &data)
+
+		// then
+		util.AssertTrue(t, data.Code == api.ErrorCodeInvalidBody)
+		util.AssertTrue(t, data.Details[0] == "threshold must be greater than zero")
+	})
+}
+
+func givenAlertService() alert.AlertService {
+	clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+	deliverer := alert.CreateNewWebhookDeliverer(http.DefaultClient, 0, time.Millisecond, clock)
+	return alert.CreateNewAlertService(alert.CreateNewMemstore(), deliverer, givenValidCuirrenciesList(), clock)
+}
+
+func givenAlertsServer() *httptest.Server {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	service.Mount(router, v1endpoint.CreateNewV1Alerts(givenAlertService()))
+	return httptest.NewServer(router)
+}
+
+func givenAlertRequest(threshold float32) string {
+	body, _ := json.Marshal(api.AlertRequest{From: "GBP",
+		To:         "EUR",
+		Condition:  api.AlertConditionAbove,
+		Threshold:  threshold,
+		WebhookURL: "http://localhost/hook",
+		Secret:     "0123456789abcdef"})
+	return string(body)
+}
+
+func performAlertsRequest(t *testing.T, method, url, body string, expectStatus int, v interface{}) {
+	t.Helper()
+
+	req, _ := http.NewRequest(method, url, bytes.NewBufferString(body))
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Cannot perform %s '%s'", method, url)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectStatus {
+		t.Fatalf("Received %d when performing %s '%s'", resp.StatusCode, method, url)
+	}
+
+	if v == nil {
+		return
+	}
+
+	data, _ := ioutil.ReadAll(resp.Body)
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("Cannot unmarshall body of %s '%s'", method, url)
+	}
+}
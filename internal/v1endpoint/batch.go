@@ -0,0 +1,128 @@
+package v1endpoint
+
+import (
+	"fmt"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxBatchPairs - The most pairs that can be requested in
+//				   a single call to `/v1/exchange/batch`
+const MaxBatchPairs = 50
+
+type v1ExchangeBatch struct {
+	exchangeService service.ExchangeRateService
+	validCurrencies map[string]bool
+}
+
+// CreateNewV1ExchangeBatch - Create a new endpoint for
+//							  `/v1/exchange/batch`
+func CreateNewV1ExchangeBatch(exchangeService service.ExchangeRateService, validCurrencies map[string]bool) *v1ExchangeBatch {
+	return &v1ExchangeBatch{exchangeService: exchangeService, validCurrencies: validCurrencies}
+}
+
+// Routes - `/v1/exchange/batch`
+func (v *v1ExchangeBatch) Routes() []service.Route {
+	return []service.Route{{Method: "POST", Version: "v1", Path: "/exchange/batch", Handler: v.postExchangeBatch, Doc: v.documentPostExchangeBatch}}
+}
+
+func (v *v1ExchangeBatch) postExchangeBatch(c *gin.Context) {
+	req := api.ExchangeBatchRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.Pairs) == 0 || len(req.Pairs) > MaxBatchPairs {
+		v.createBadRequestResponse(c)
+		return
+	}
+
+	quotes := make([]api.ExchangeBatchQuote, len(req.Pairs))
+	results := v.performGroupedRequests(req.Pairs)
+	for i, p := range req.Pairs {
+		if err := v.validatePair(p); err != nil {
+			quotes[i] = v.createFailedQuote(p, api.ErrorCodeInvalidPair, err)
+			continue
+		}
+
+		r := results[p.From][p.To]
+		if r.Err != nil {
+			quotes[i] = v.createFailedQuote(p, api.ErrorCodeInternal, r.Err)
+			continue
+		}
+
+		quotes[i] = v.createQuote(p, r.Response)
+	}
+
+	c.JSON(200, api.ExchangeBatchResponse{Quotes: quotes})
+}
+
+// documentPostExchangeBatch - Describe `/v1/exchange/batch` in the OpenAPI document
+func (v *v1ExchangeBatch) documentPostExchangeBatch(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get the exchange rates of many pairs of currencies",
+		OperationID: "postExchangeBatch",
+		RequestBody: &openapi.RequestBody{Required: true, Content: d.JSONContent(api.ExchangeBatchRequest{})},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "A quote for every requested pair, in the same order", Content: d.JSONContent(api.ExchangeBatchResponse{})},
+			"400": endpoint.DocumentErrorResponse(d, "Body is invalid"),
+		},
+	}
+}
+
+// performGroupedRequests - Groups the valid pairs by their base
+//							currency so that each base is only
+//							requested once from the service.
+func (v *v1ExchangeBatch) performGroupedRequests(pairs []api.ExchangePair) map[string]map[string]*service.ExchangeRateBatchResponse {
+	bases := []string{}
+	grouped := make(map[string][]string)
+	seen := make(map[string]bool)
+	for _, p := range pairs {
+		if v.validatePair(p) != nil || seen[p.From+p.To] {
+			continue
+		}
+		seen[p.From+p.To] = true
+
+		if _, exists := grouped[p.From]; !exists {
+			bases = append(bases, p.From)
+		}
+		grouped[p.From] = append(grouped[p.From], p.To)
+	}
+
+	results := make(map[string]map[string]*service.ExchangeRateBatchResponse)
+	for _, from := range bases {
+		results[from] = make(map[string]*service.ExchangeRateBatchResponse)
+		for _, r := range v.exchangeService.PerformBatchRequest(from, grouped[from]) {
+			results[from][r.To] = r
+		}
+	}
+
+	return results
+}
+
+func (v *v1ExchangeBatch) validatePair(p api.ExchangePair) error {
+	return service.ValidatePair(v.validCurrencies, p.From, p.To)
+}
+
+func (v *v1ExchangeBatch) createBadRequestResponse(c *gin.Context) {
+	endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, fmt.Sprintf("body is invalid. Between 1 and %d pairs are required.", MaxBatchPairs))
+}
+
+func (v *v1ExchangeBatch) createFailedQuote(p api.ExchangePair, code string, err error) api.ExchangeBatchQuote {
+	return api.ExchangeBatchQuote{
+		From:   p.From,
+		To:     p.To,
+		Code:   code,
+		Reason: err.Error(),
+	}
+}
+
+func (v *v1ExchangeBatch) createQuote(p api.ExchangePair, r *service.ExchangeRateServiceResponse) api.ExchangeBatchQuote {
+	return api.ExchangeBatchQuote{
+		From:           p.From,
+		To:             p.To,
+		SingleUnit:     r.OneUnit,
+		ShouldExchange: r.ShouldExchange,
+		DataDateTime:   r.DataDateTime.Format(endpoint.DataDateTimeLayout),
+	}
+}
@@ -0,0 +1,133 @@
+package v1endpoint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/pkg/errors"
+)
+
+func TestExchangeBatchEndpoint(t *testing.T) {
+	t.Run("ensure 200 response and a quote for every pair", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		endpoint := v1endpoint.CreateNewV1ExchangeBatch(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performBatchRequest(t, baseURL, `{"pairs":[{"from":"EUR","to":"GBP"},{"from":"EUR","to":"USD"},{"from":"GBP","to":"USD"}]}`, 200)
+		data := unmarshalBatch(t, body)
+
+		// then
+		util.AssertTrue(t, len(data.Quotes) == 3)
+		util.AssertTrue(t, data.Quotes[1].From == "EUR" && data.Quotes[1].To == "USD")
+		util.AssertTrue(t, data.Quotes[2].SingleUnit == 0.8)
+	})
+
+	t.Run("ensure a rate of 0 is returned rather than left out", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		eService.resp.OneUnit = 0
+		endpoint := v1endpoint.CreateNewV1ExchangeBatch(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performBatchRequest(t, baseURL, `{"pairs":[{"from":"EUR","to":"GBP"}]}`, 200)
+
+		// then
+		util.AssertTrue(t, bytes.Contains(body, []byte(`"singleUnit":0,`)))
+		util.AssertTrue(t, unmarshalBatch(t, body).Quotes[0].Code == "")
+	})
+
+	t.Run("ensure invalid pairs fail individually", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		endpoint := v1endpoint.CreateNewV1ExchangeBatch(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performBatchRequest(t, baseURL, `{"pairs":[{"from":"EUR","to":"FOO"},{"from":"EUR","to":"GBP"}]}`, 200)
+		data := unmarshalBatch(t, body)
+
+		// then
+		util.AssertTrue(t, data.Quotes[0].Reason == "FOO is not a valid currency")
+		util.AssertTrue(t, data.Quotes[1].Reason == "")
+		util.AssertTrue(t, data.Quotes[1].SingleUnit == 0.8)
+	})
+
+	t.Run("ensure service errors are reported per pair", func(t *testing.T) {
+		// given
+		eService := mockExchangeService{nil, errors.New("Network service down")}
+		endpoint := v1endpoint.CreateNewV1ExchangeBatch(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performBatchRequest(t, baseURL, `{"pairs":[{"from":"EUR","to":"GBP"}]}`, 200)
+		data := unmarshalBatch(t, body)
+
+		// then
+		util.AssertTrue(t, data.Quotes[0].Reason == "Network service down")
+	})
+
+	t.Run("ensure 400 response when no pairs are passed", func(t *testing.T) {
+		// given
+		eService := givenValidExchangeService()
+		endpoint := v1endpoint.CreateNewV1ExchangeBatch(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performBatchRequest(t, baseURL, `{"pairs":[]}`, 400)
+		data := unmarshalFail(t, "", "", body)
+
+		// then
+		util.AssertTrue(t, data.Code == api.ErrorCodeInvalidBody)
+	})
+}
+
+func performBatchRequest(t *testing.T, baseURL, reqBody string, expectStatus int) []byte {
+	t.Helper()
+
+	timeout := time.Duration(5 * time.Second)
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	resp, err := client.Post(baseURL+"/v1/exchange/batch", "application/json", bytes.NewBufferString(reqBody))
+	if err != nil {
+		t.Fatal("Cannot get batch exchange rates")
+	}
+
+	if resp.StatusCode != expectStatus {
+		t.Fatal(fmt.Sprintf("Received %d when getting batch exchange rates", resp.StatusCode))
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal("Cannot read body for batch exchange rate request")
+	}
+
+	return body
+}
+
+func unmarshalBatch(t *testing.T, body []byte) *api.ExchangeBatchResponse {
+	t.Helper()
+
+	data := api.ExchangeBatchResponse{}
+
+	err := json.Unmarshal(body, &data)
+	if err != nil {
+		t.Fatal("Cannot unmarshall body for batch exchange rate request")
+	}
+
+	return &data
+}
@@ -0,0 +1,91 @@
+package v1endpoint
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/gin-gonic/gin"
+)
+
+// rateETag - Strong ETag of a rate response. The body is built
+//			  only from these values so equal values give an
+//			  equal body.
+func rateETag(from, to string, oneUnit float32, shouldExchange bool, dataDateTime time.Time) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s|%s|%v|%t|%d", from, to, oneUnit, shouldExchange, dataDateTime.UnixNano())))
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// immutableCacheControl - Cache-Control of a historical rate, which
+//							never changes
+const immutableCacheControl = "public, max-age=31536000, immutable"
+
+// maxAge - Cache-Control of a rate that is refreshed after validFor
+func maxAge(validFor time.Duration) string {
+	return fmt.Sprintf("max-age=%d", int(validFor.Seconds()))
+}
+
+// writeCacheHeaders - Sets ETag, Last-Modified and Cache-Control.
+//					   Writes a 304 and returns true when the client
+//					   already has the response.
+func writeCacheHeaders(c *gin.Context, etag string, lastModified time.Time, cacheControl string) bool {
+	if auth.GetKey(c) != nil {
+		// A shared cache would otherwise serve the response without
+		// checking the key, or counting it against the quota
+		cacheControl = "private, " + strings.TrimPrefix(cacheControl, "public, ")
+		c.Header("Vary", auth.KeyHeader)
+	}
+	c.Header("ETag", etag)
+	c.Header("Last-Modified", lastModified.UTC().Format(http.TimeFormat))
+	c.Header("Cache-Control", cacheControl)
+
+	if !notModified(c.Request, etag, lastModified) {
+		return false
+	}
+
+	c.Status(304)
+	// gin only writes the headers of a response with no body
+	// once something forces it to
+	c.Writer.WriteHeaderNow()
+	return true
+}
+
+// notModified - If-None-Match takes precedence over
+//				 If-Modified-Since, as in RFC 7232
+func notModified(r *http.Request, etag string, lastModified time.Time) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, tag := range strings.Split(match, ",") {
+			tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+			if tag == "*" || tag == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	if since := r.Header.Get("If-Modified-Since"); since != "" {
+		t, err := http.ParseTime(since)
+		if err != nil {
+			return false
+		}
+		// Last-Modified only has second precision
+		return !lastModified.Truncate(time.Second).After(t)
+	}
+
+	return false
+}
+
+// cacheHeaders - Documents the headers set by writeCacheHeaders
+func cacheHeaders() map[string]*openapi.Header {
+	return map[string]*openapi.Header{
+		"ETag":          openapi.StringHeader("Changes whenever the rate or its dataDateTime changes"),
+		"Last-Modified": openapi.StringHeader("dataDateTime of the rate"),
+		"Cache-Control": openapi.StringHeader("max-age is the seconds until the rate is refreshed, historical rates are immutable. private when an API key is required"),
+		"Vary":          openapi.StringHeader("X-API-Key when an API key is required"),
+	}
+}
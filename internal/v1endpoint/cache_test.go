@@ -0,0 +1,209 @@
+package v1endpoint_test
+
+import (
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/gin-gonic/gin"
+)
+
+func TestV1ExchangeCaching(t *testing.T) {
+	t.Run("ensure cache headers are set from the stored rate", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+
+		// when
+		rec := performCachedRequest(router, "EUR", "GBP", nil)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, rec.Header().Get("ETag") != "")
+		util.AssertTrue(t, rec.Header().Get("Last-Modified") == "Mon, 14 Oct 2019 19:21:48 GMT")
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "max-age=3")
+	})
+
+	t.Run("ensure the ETag changes with the pair", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+
+		// when
+		gbp := performCachedRequest(router, "EUR", "GBP", nil)
+		usd := performCachedRequest(router, "EUR", "USD", nil)
+
+		// then
+		util.AssertTrue(t, gbp.Header().Get("ETag") != usd.Header().Get("ETag"))
+	})
+
+	t.Run("ensure a matching If-None-Match is not modified", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+		etag := performCachedRequest(router, "EUR", "GBP", nil).Header().Get("ETag")
+
+		// when
+		rec := performCachedRequest(router, "EUR", "GBP", map[string]string{"If-None-Match": `"other", W/` + etag})
+
+		// then
+		util.AssertTrue(t, rec.Code == 304)
+		util.AssertTrue(t, rec.Body.Len() == 0)
+		util.AssertTrue(t, rec.Header().Get("ETag") == etag)
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "max-age=3")
+	})
+
+	t.Run("ensure a different If-None-Match is modified even if the date matches", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+
+		// when
+		rec := performCachedRequest(router, "EUR", "GBP", map[string]string{
+			"If-None-Match":     `"other"`,
+			"If-Modified-Since": "Mon, 14 Oct 2019 19:21:48 GMT"})
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+	})
+
+	t.Run("ensure If-Modified-Since is honoured", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+
+		// when
+		same := performCachedRequest(router, "EUR", "GBP", map[string]string{"If-Modified-Since": "Mon, 14 Oct 2019 19:21:48 GMT"})
+		older := performCachedRequest(router, "EUR", "GBP", map[string]string{"If-Modified-Since": "Mon, 14 Oct 2019 19:21:47 GMT"})
+
+		// then
+		util.AssertTrue(t, same.Code == 304)
+		util.AssertTrue(t, older.Code == 200)
+	})
+
+	t.Run("ensure expired rates are not cached", func(t *testing.T) {
+		// given
+		eService := givenCachedExchangeService()
+		eService.resp.ValidFor = 0
+		router := givenCachingRouter(eService)
+
+		// when
+		rec := performCachedRequest(router, "EUR", "GBP", nil)
+
+		// then
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "max-age=0")
+	})
+
+	t.Run("ensure responses to an API key are private to it", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService(), givenAuthenticator())
+
+		// when
+		rec := performCachedRequest(router, "EUR", "GBP", map[string]string{auth.KeyHeader: "reader-secret"})
+		dated := performDatedRequest(router, "2019-10-01", map[string]string{auth.KeyHeader: "reader-secret"})
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "private, max-age=3")
+		util.AssertTrue(t, rec.Header().Get("Vary") == auth.KeyHeader)
+		util.AssertTrue(t, dated.Header().Get("Cache-Control") == "private, max-age=31536000, immutable")
+		util.AssertTrue(t, dated.Header().Get("Vary") == auth.KeyHeader)
+	})
+}
+
+func TestV1ExchangeAsOfDate(t *testing.T) {
+	t.Run("ensure the rate of the date is returned and cached forever", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+
+		// when
+		rec := performDatedRequest(router, "2019-10-01", nil)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "public, max-age=31536000, immutable")
+		util.AssertTrue(t, rec.Header().Get("ETag") != "")
+	})
+
+	t.Run("ensure a matching If-None-Match of a dated rate is not modified", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenCachedExchangeService())
+		etag := performDatedRequest(router, "2019-10-01", nil).Header().Get("ETag")
+		req := httptest.NewRequest("GET", "/v1/exchange?from=EUR&to=GBP&date=2019-10-01", nil)
+		req.Header.Set("If-None-Match", etag)
+		rec := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rec, req)
+
+		// then
+		util.AssertTrue(t, rec.Code == 304)
+	})
+
+	t.Run("ensure 400 response when the date is outside of the available range", func(t *testing.T) {
+		for _, date := range []string{"01-10-2019", "1999-01-03", "2019-10-15"} {
+			// given
+			router := givenCachingRouter(givenCachedExchangeService())
+
+			// when
+			rec := performDatedRequest(router, date, nil)
+
+			// then
+			if rec.Code != 400 {
+				t.Fatalf("expected 400 for '%s' but actual is %d", date, rec.Code)
+			}
+		}
+	})
+
+	t.Run("ensure 500 response when the rate of the date cannot be retrieved", func(t *testing.T) {
+		// given
+		router := givenCachingRouter(givenFailingExchangeService())
+
+		// when
+		rec := performDatedRequest(router, "2019-10-01", nil)
+
+		// then
+		util.AssertTrue(t, rec.Code == 500)
+		util.AssertTrue(t, rec.Header().Get("Cache-Control") == "")
+	})
+}
+
+func givenCachedExchangeService() *mockExchangeService {
+	dataDateTime := time.Date(2019, 10, 14, 19, 21, 48, 115878940, time.UTC)
+	resp := &service.ExchangeRateServiceResponse{OneUnit: 0.8, ShouldExchange: true, DataDateTime: dataDateTime, ValidFor: 3500 * time.Millisecond}
+	return &mockExchangeService{resp, nil}
+}
+
+func givenCachingRouter(eService service.ExchangeRateService, middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(middlewares...)
+	clock := util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))
+	service.Mount(router, v1endpoint.CreateNewV1Exchange(eService, givenValidCuirrenciesList(), clock))
+	return router
+}
+
+func performCachedRequest(router *gin.Engine, from, to string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/v1/exchange?from="+from+"&to="+to, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+
+func performDatedRequest(router *gin.Engine, date string, headers map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", "/v1/exchange?from=EUR&to=GBP&date="+date, nil)
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func givenAuthenticator() gin.HandlerFunc {
+	keys := auth.CreateNewMemKeyStore([]*auth.Key{{ID: "reader", Key: "reader-secret", Scopes: []auth.Scope{auth.ScopeReadRates}}})
+	return auth.CreateNewAuthenticator(keys, nil, auth.ScopeReadRates, util.CreateNewClock()).Middleware()
+}
@@ -0,0 +1,162 @@
+package v1endpoint
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+type v1Chaos struct {
+	controller dao.ChaosController
+}
+
+// CreateNewV1Chaos - Create a new endpoint for `/v1/admin/chaos`
+func CreateNewV1Chaos(controller dao.ChaosController) *v1Chaos {
+	return &v1Chaos{controller: controller}
+}
+
+// Routes - `/v1/admin/chaos`
+func (v *v1Chaos) Routes() []service.Route {
+	return []service.Route{
+		{Method: "GET", Version: "v1", Path: "/admin/chaos", Handler: v.getChaos, Doc: v.documentGetChaos},
+		{Method: "PUT", Version: "v1", Path: "/admin/chaos", Handler: v.setChaos, Doc: v.documentSetChaos},
+		{Method: "DELETE", Version: "v1", Path: "/admin/chaos", Handler: v.deleteChaos, Doc: v.documentDeleteChaos},
+	}
+}
+
+func (v *v1Chaos) getChaos(c *gin.Context) {
+	v.createSuccessResponse(c)
+}
+
+func (v *v1Chaos) setChaos(c *gin.Context) {
+	req := api.ChaosRequest{}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, "body is invalid.", err.Error())
+		return
+	}
+
+	faults := []dao.Fault{}
+	for i, f := range req.Faults {
+		fault, err := toFault(f)
+		if err != nil {
+			endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, "body is invalid.", errors.Wrap(err, fmt.Sprintf("Fault %d is invalid", i)).Error())
+			return
+		}
+		faults = append(faults, fault)
+	}
+
+	if err := v.controller.SetFaults(faults); err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidBody, "body is invalid.", err.Error())
+		return
+	}
+
+	v.createSuccessResponse(c)
+}
+
+func (v *v1Chaos) deleteChaos(c *gin.Context) {
+	v.controller.SetFaults(nil)
+	c.Status(204)
+}
+
+// documentGetChaos - Describe `GET /v1/admin/chaos` in the OpenAPI
+//					  document
+func (v *v1Chaos) documentGetChaos(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get the faults being injected into calls of the provider",
+		OperationID: "getChaos",
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Faults and how many have been injected", Content: d.JSONContent(api.ChaosResponse{})},
+			"401": endpoint.DocumentErrorResponse(d, "API key is missing or unknown"),
+			"403": endpoint.DocumentErrorResponse(d, "API key does not have the admin scope"),
+		},
+	}
+}
+
+// documentSetChaos - Describe `PUT /v1/admin/chaos` in the OpenAPI
+//					  document
+func (v *v1Chaos) documentSetChaos(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Replace the faults being injected into calls of the provider",
+		OperationID: "setChaos",
+		RequestBody: &openapi.RequestBody{Required: true, Content: d.JSONContent(api.ChaosRequest{})},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Faults and how many have been injected", Content: d.JSONContent(api.ChaosResponse{})},
+			"400": endpoint.DocumentErrorResponse(d, "Faults are invalid"),
+			"401": endpoint.DocumentErrorResponse(d, "API key is missing or unknown"),
+			"403": endpoint.DocumentErrorResponse(d, "API key does not have the admin scope"),
+		},
+	}
+}
+
+// documentDeleteChaos - Describe `DELETE /v1/admin/chaos` in the
+//						 OpenAPI document
+func (v *v1Chaos) documentDeleteChaos(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Stop injecting faults into calls of the provider",
+		OperationID: "deleteChaos",
+		Responses: map[string]*openapi.Response{
+			"204": {Description: "No faults are injected"},
+			"401": endpoint.DocumentErrorResponse(d, "API key is missing or unknown"),
+			"403": endpoint.DocumentErrorResponse(d, "API key does not have the admin scope"),
+		},
+	}
+}
+
+func (v *v1Chaos) createSuccessResponse(c *gin.Context) {
+	faults := []api.ChaosFault{}
+	for _, f := range v.controller.Faults() {
+		faults = append(faults, toChaosFault(f))
+	}
+	c.JSON(200, api.ChaosResponse{Faults: faults, Injected: v.controller.Injected()})
+}
+
+func toFault(f api.ChaosFault) (dao.Fault, error) {
+	fault := dao.Fault{Kind: f.Kind,
+		Call:        f.Call,
+		Probability: f.Probability,
+		Every:       f.Every,
+		Status:      f.Status}
+
+	var err error
+	if f.Duration != "" {
+		if fault.Duration, err = time.ParseDuration(f.Duration); err != nil {
+			return fault, errors.Wrap(err, "Duration must be such as 250ms")
+		}
+	}
+	if f.Start != "" {
+		if fault.Start, err = time.Parse(time.RFC3339, f.Start); err != nil {
+			return fault, errors.Wrap(err, "Start must be an RFC 3339 time")
+		}
+	}
+	if f.End != "" {
+		if fault.End, err = time.Parse(time.RFC3339, f.End); err != nil {
+			return fault, errors.Wrap(err, "End must be an RFC 3339 time")
+		}
+	}
+	return fault, nil
+}
+
+func toChaosFault(f dao.Fault) api.ChaosFault {
+	fault := api.ChaosFault{Kind: f.Kind,
+		Call:        f.Call,
+		Probability: f.Probability,
+		Every:       f.Every,
+		Status:      f.Status}
+	if f.Duration > 0 {
+		fault.Duration = f.Duration.String()
+	}
+	if !f.Start.IsZero() {
+		fault.Start = f.Start.Format(time.RFC3339)
+	}
+	if !f.End.IsZero() {
+		fault.End = f.End.Format(time.RFC3339)
+	}
+	return fault
+}
@@ -0,0 +1,120 @@
+package v1endpoint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/dao"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestV1Chaos(t *testing.T) {
+	t.Run("ensure faults are replaced and returned", func(t *testing.T) {
+		// given
+		controller := givenChaosController()
+		router := givenChaosRouter(controller)
+		body := `{"faults":[{"kind":"latency","duration":"250ms","call":"latest"},{"kind":"status","status":503,"probability":0.5,"start":"2019-10-14T20:00:00Z"}]}`
+		rec := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rec, httptest.NewRequest("PUT", "/v1/admin/chaos", bytes.NewBufferString(body)))
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		resp := api.ChaosResponse{}
+		util.AssertErrorNil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		util.AssertTrue(t, len(resp.Faults) == 2)
+		util.AssertTrue(t, resp.Faults[0].Duration == "250ms")
+		util.AssertTrue(t, resp.Faults[1].Start == "2019-10-14T20:00:00Z")
+		faults := controller.Faults()
+		util.AssertTrue(t, faults[0].Duration == 250*time.Millisecond)
+		util.AssertTrue(t, faults[1].Status == 503)
+	})
+
+	t.Run("ensure the injected faults are counted", func(t *testing.T) {
+		// given
+		controller := givenChaosController()
+		controller.SetFaults([]dao.Fault{{Kind: dao.FaultMalformed}})
+		controller.(dao.NetworkDAO).GetExchangeRateForNow("EUR", "GBP")
+		router := givenChaosRouter(controller)
+		rec := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/admin/chaos", nil))
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		resp := api.ChaosResponse{}
+		util.AssertErrorNil(t, json.Unmarshal(rec.Body.Bytes(), &resp))
+		util.AssertTrue(t, resp.Injected[dao.FaultMalformed] == 1)
+	})
+
+	t.Run("ensure every fault is removed", func(t *testing.T) {
+		// given
+		controller := givenChaosController()
+		controller.SetFaults([]dao.Fault{{Kind: dao.FaultMalformed}})
+		router := givenChaosRouter(controller)
+		rec := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rec, httptest.NewRequest("DELETE", "/v1/admin/chaos", nil))
+
+		// then
+		util.AssertTrue(t, rec.Code == 204)
+		util.AssertTrue(t, len(controller.Faults()) == 0)
+	})
+
+	t.Run("ensure a bad request for invalid faults", func(t *testing.T) {
+		bodies := []string{
+			`{"faults":[{"kind":"explode"}]}`,
+			`{"faults":[{"kind":"latency","duration":"soon"}]}`,
+			`{"faults":[{"kind":"status","status":503,"start":"tomorrow"}]}`,
+			`{"faults":`,
+		}
+
+		for _, body := range bodies {
+			// given
+			controller := givenChaosController()
+			router := givenChaosRouter(controller)
+			rec := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(rec, httptest.NewRequest("PUT", "/v1/admin/chaos", bytes.NewBufferString(body)))
+
+			// then
+			util.AssertTrue(t, rec.Code == 400)
+			util.AssertTrue(t, len(controller.Faults()) == 0)
+		}
+	})
+}
+
+func givenChaosRouter(controller dao.ChaosController) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	service.Mount(router, v1endpoint.CreateNewV1Chaos(controller))
+	return router
+}
+
+func givenChaosController() dao.ChaosController {
+	network := &stubNetworkDAO{&dao.ExchangeRateResponse{Base: "EUR", Rates: map[string]float32{"GBP": 0.8}}}
+	return dao.CreateNewChaosNetwork(network, util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)), 1)
+}
+
+type stubNetworkDAO struct {
+	resp *dao.ExchangeRateResponse
+}
+
+func (s *stubNetworkDAO) GetExchangeRateForNow(from string, to ...string) (*dao.ExchangeRateResponse, error) {
+	return s.resp, nil
+}
+
+func (s *stubNetworkDAO) GetExchangeRateFromPast(from string, date time.Time, to ...string) (*dao.ExchangeRateResponse, error) {
+	return s.resp, nil
+}
@@ -0,0 +1,217 @@
+package v1endpoint_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"io/ioutil"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v2endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+)
+
+var update = flag.Bool("update", false, "update the golden files in testdata")
+
+// TestContract - Compares every response body with the golden file
+//				  in testdata so that changes to the shape of a
+//				  response are caught. Run with -update to accept
+//				  a deliberate change.
+func TestContract(t *testing.T) {
+	tests := []struct {
+		golden  string
+		service service.ExchangeRateService
+		method  string
+		path    string
+		body    string
+		accept  string
+		status  int
+	}{
+		{"exchange_200.golden", givenFixedExchangeService(), "GET", "/v1/exchange?from=EUR&to=GBP", "", "", 200},
+		{"exchange_400.golden", givenFixedExchangeService(), "GET", "/v1/exchange?from=EUR&to=FOO", "", "", 400},
+		{"exchange_500.golden", givenFailingExchangeService(), "GET", "/v1/exchange?from=EUR&to=GBP", "", "", 500},
+		{"batch_200.golden", givenFixedExchangeService(), "POST", "/v1/exchange/batch", `{"pairs":[{"from":"EUR","to":"GBP"},{"from":"EUR","to":"FOO"}]}`, "", 200},
+		{"batch_200_failed.golden", givenFailingExchangeService(), "POST", "/v1/exchange/batch", `{"pairs":[{"from":"EUR","to":"GBP"}]}`, "", 200},
+		{"batch_400.golden", givenFixedExchangeService(), "POST", "/v1/exchange/batch", `{"pairs":[]}`, "", 400},
+		{"matrix_200.golden", givenFixedExchangeService(), "GET", "/v1/rates/matrix?currencies=EUR,GBP", "", "", 200},
+		{"matrix_200_csv.golden", givenFixedExchangeService(), "GET", "/v1/rates/matrix?currencies=EUR,GBP&format=csv", "", "", 200},
+		{"matrix_400.golden", givenFixedExchangeService(), "GET", "/v1/rates/matrix?currencies=EUR", "", "", 400},
+		{"matrix_500.golden", givenFailingExchangeService(), "GET", "/v1/rates/matrix?currencies=EUR,GBP", "", "", 500},
+		{"stream_400.golden", givenFixedExchangeService(), "GET", "/v1/stream?pairs=EUR/FOO", "", "", 400},
+		{"alerts_200_empty.golden", givenFixedExchangeService(), "GET", "/v1/alerts", "", "", 200},
+		{"alerts_400.golden", givenFixedExchangeService(), "POST", "/v1/alerts", `{"from":"GBP","to":"EUR","condition":"sideways","threshold":1.15,"webhookUrl":"http://localhost/hook","secret":"0123456789abcdef"}`, "", 400},
+		{"alerts_404.golden", givenFixedExchangeService(), "GET", "/v1/alerts/missing", "", "", 404},
+		{"usage_200.golden", givenFixedExchangeService(), "GET", "/v1/admin/usage", "", "", 200},
+		{"chaos_200.golden", givenFixedExchangeService(), "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"status","status":503,"every":2}]}`, "", 200},
+		{"chaos_400.golden", givenFixedExchangeService(), "PUT", "/v1/admin/chaos", `{"faults":[{"kind":"explode"}]}`, "", 400},
+		{"exchange_v2_200.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP,USD&amount=10", "", "", 200},
+		{"exchange_v2_200_csv.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP,USD&amount=10", "", "text/csv", 200},
+		{"exchange_v2_200_xml.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP,USD&amount=10", "", "application/xml", 200},
+		{"exchange_v2_200_msgpack.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP,USD&amount=10", "", "application/msgpack", 200},
+		{"exchange_v2_200_format.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP&format=xml", "", "application/json", 200},
+		{"exchange_v2_200_date.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP&date=2019-10-01&strategy=none", "", "", 200},
+		{"exchange_v2_200_failed.golden", givenFailingExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP", "", "", 200},
+		{"exchange_v2_400.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=FOO", "", "", 400},
+		{"exchange_v2_406.golden", givenFixedExchangeService(), "GET", "/v2/exchange?from=EUR&to=GBP", "", "image/png", 406},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			// given
+			router, doc := givenContractRouter(tt.service)
+			req := httptest.NewRequest(tt.method, tt.path, bytes.NewBufferString(tt.body))
+			req.Header.Set(service.RequestIDHeader, "contract-test")
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+
+			// when
+			router.ServeHTTP(rec, req)
+
+			// then
+			if rec.Code != tt.status {
+				t.Fatalf("expected status %d but actual is %d", tt.status, rec.Code)
+			}
+			assertGolden(t, tt.golden, rec.Body.Bytes())
+			path := strings.Split(tt.path, "?")[0]
+			if strings.HasPrefix(path, "/v1/alerts/") {
+				path = "/v1/alerts/{id}"
+			}
+			if err := doc.ValidateResponse(tt.method, path, rec.Code, rec.Header().Get("Content-Type"), rec.Body.Bytes()); err != nil {
+				t.Fatalf("response does not match the OpenAPI document: %s", err)
+			}
+			if body := asJSON(t, rec.Header().Get("Content-Type"), rec.Body.Bytes()); body != nil {
+				if err := doc.ValidateResponse(tt.method, path, rec.Code, "application/json", body); err != nil {
+					t.Fatalf("response does not match the OpenAPI document: %s", err)
+				}
+			}
+		})
+	}
+
+	t.Run("openapi.golden", func(t *testing.T) {
+		// given
+		_, doc := givenContractRouter(givenFixedExchangeService())
+
+		// when
+		body, err := json.Marshal(doc)
+
+		// then
+		util.AssertErrorNil(t, err)
+		assertGolden(t, "openapi.golden", body)
+	})
+
+	t.Run("ensure every route is in the OpenAPI document", func(t *testing.T) {
+		// given
+		router, doc := givenContractRouter(givenFixedExchangeService())
+
+		// when
+		routes := router.Routes()
+
+		// then
+		for _, r := range routes {
+			if doc.Operation(r.Method, openapi.Path(r.Path)) == nil {
+				t.Fatalf("%s %s is not in the OpenAPI document", r.Method, r.Path)
+			}
+		}
+	})
+}
+
+func givenContractRouter(eService service.ExchangeRateService) (*gin.Engine, *openapi.Document) {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(service.RequestID())
+	doc := openapi.CreateNewDocument("Exchange Rate Server", "1")
+	endpoints := []service.Endpoint{
+		v1endpoint.CreateNewV1Exchange(eService, givenValidCuirrenciesList(), util.CreateNewClock()),
+		v1endpoint.CreateNewV1ExchangeBatch(eService, givenValidCuirrenciesList()),
+		v1endpoint.CreateNewV1RatesMatrix(eService, givenValidCuirrenciesList()),
+		v1endpoint.CreateNewV1Stream(eService, pubsub.CreateNewBroker(), givenValidCuirrenciesList(), time.Second, 1),
+		v1endpoint.CreateNewV1Alerts(givenAlertService()),
+		v1endpoint.CreateNewV1Usage(givenUsageReporter()),
+		v1endpoint.CreateNewV1Chaos(givenChaosController()),
+		v2endpoint.CreateNewV2Exchange(eService, givenValidCuirrenciesList(), util.CreateNewFakeClock(time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC))),
+	}
+	if err := service.Mount(router, endpoints...); err != nil {
+		panic(err)
+	}
+	service.DocumentRoutes(doc, endpoints...)
+	return router, doc
+}
+
+// asJSON - ValidateResponse only checks the schema of JSON, so the
+//			XML and MessagePack of /v2/exchange are decoded and
+//			checked as the JSON of the same response
+func asJSON(t *testing.T, contentType string, body []byte) []byte {
+	t.Helper()
+
+	resp := api.ExchangeV2Response{}
+	var err error
+	switch strings.Split(contentType, ";")[0] {
+	case "application/xml":
+		err = xml.Unmarshal(body, &resp)
+	case "application/msgpack":
+		err = codec.NewDecoderBytes(body, &codec.MsgpackHandle{}).Decode(&resp)
+	default:
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("cannot decode '%s' response: %s", contentType, err)
+	}
+
+	body, err = json.Marshal(resp)
+	if err != nil {
+		t.Fatalf("cannot marshal response: %s", err)
+	}
+	return body
+}
+
+func givenFixedExchangeService() *mockExchangeService {
+	dataDateTime := time.Date(2019, 10, 14, 19, 21, 48, 115878940, time.UTC)
+	resp := &service.ExchangeRateServiceResponse{OneUnit: 0.8, ShouldExchange: true, DataDateTime: dataDateTime}
+	return &mockExchangeService{resp, nil}
+}
+
+func givenFailingExchangeService() *mockExchangeService {
+	return &mockExchangeService{nil, errors.New("Network service down")}
+}
+
+func assertGolden(t *testing.T, golden string, actual []byte) {
+	t.Helper()
+
+	// JSON is indented so that the golden files are readable
+	indented := bytes.Buffer{}
+	if json.Indent(&indented, actual, "", "  ") == nil {
+		actual = append(indented.Bytes(), '\n')
+	}
+
+	path := filepath.Join("testdata", golden)
+	if *update {
+		if err := ioutil.WriteFile(path, actual, 0644); err != nil {
+			t.Fatalf("cannot update golden file '%s': %s", path, err)
+		}
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("cannot read golden file '%s': %s", path, err)
+	}
+
+	if !bytes.Equal(expected, actual) {
+		t.Fatalf("response does not match golden file '%s'\nexpected:\n%s\nactual:\n%s", path, expected, actual)
+	}
+}
+
@@ -1,81 +1,138 @@
 package v1endpoint
 
 import (
-	"errors"
-	"fmt"
+	"time"
 
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
 	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
 	"github.com/gin-gonic/gin"
 )
 
 type v1Exchange struct {
 	exchangeService service.ExchangeRateService
 	validCurrencies map[string]bool
+	clock           util.Clock
 }
 
 // CreateNewV1Exchange - Create a new endpoint for
 //						 `/v1/exchange`
-func CreateNewV1Exchange(exchangeService service.ExchangeRateService, validCurrencies map[string]bool) *v1Exchange {
-	return &v1Exchange{exchangeService: exchangeService, validCurrencies: validCurrencies}
+func CreateNewV1Exchange(exchangeService service.ExchangeRateService, validCurrencies map[string]bool, clock util.Clock) *v1Exchange {
+	return &v1Exchange{exchangeService: exchangeService, validCurrencies: validCurrencies, clock: clock}
 }
 
-func (v *v1Exchange) PerformRequest(r *gin.Engine) {
-	r.GET("/v1/exchange", func(c *gin.Context) {
-		from, to, err := v.getQueryParams(c)
-		if err != nil {
-			v.createBadRequestResponse(c)
-			return
-		}
-
-		resp, err := v.exchangeService.PerformRequest(from, to)
-		if err != nil {
-			v.createServerErrorResponse(c, err)
-			return
-		}
-
-		v.createSuccessResponse(c, from, to, resp)
-	})
+// Routes - `/v1/exchange`
+func (v *v1Exchange) Routes() []service.Route {
+	return []service.Route{{Method: "GET", Version: "v1", Path: "/exchange", Handler: v.getExchange, Doc: v.documentGetExchange}}
+}
+
+func (v *v1Exchange) getExchange(c *gin.Context) {
+	from, to, err := v.getQueryParams(c)
+	if err != nil {
+		v.createBadRequestResponse(c, err)
+		return
+	}
+
+	date, err := v.getDate(c)
+	if err != nil {
+		v.createBadDateResponse(c, err)
+		return
+	}
+
+	if !date.IsZero() {
+		v.getHistoricalExchange(c, from, to, date)
+		return
+	}
+
+	resp, err := v.exchangeService.PerformRequest(from, to)
+	if err != nil {
+		v.createServerErrorResponse(c, err)
+		return
+	}
+
+	v.createSuccessResponse(c, from, to, resp, maxAge(resp.ValidFor))
+}
+
+// getHistoricalExchange - The rate as of a past day, which never
+//						   changes so it can be cached forever
+func (v *v1Exchange) getHistoricalExchange(c *gin.Context, from, to string, date time.Time) {
+	resp, err := v.exchangeService.PerformHistoricalRequest(from, to, date)
+	if err != nil {
+		v.createServerErrorResponse(c, err)
+		return
+	}
+
+	v.createSuccessResponse(c, from, to, resp, immutableCacheControl)
+}
+
+// documentGetExchange - Describe `/v1/exchange` in the OpenAPI document
+func (v *v1Exchange) documentGetExchange(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get the exchange rate between two currencies and whether it's a good time to exchange",
+		OperationID: "getExchange",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("from", "Currency to exchange from", true, endpoint.Currencies(v.validCurrencies)...),
+			openapi.QueryParameter("to", "Currency to exchange to", true, endpoint.Currencies(v.validCurrencies)...),
+			openapi.QueryParameter("date", "Get the rate as of this day, such as 2019-10-01, instead of now", false),
+			openapi.HeaderParameter("If-None-Match", "ETag of a previous response"),
+			openapi.HeaderParameter("If-Modified-Since", "Last-Modified of a previous response"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Exchange rate", Headers: cacheHeaders(), Content: d.JSONContent(api.ExchangeResponse{})},
+			"304": {Description: "Exchange rate has not changed since the ETag or date in the request", Headers: cacheHeaders()},
+			"400": endpoint.DocumentErrorResponse(d, "Query params are invalid"),
+			"500": endpoint.DocumentErrorResponse(d, "Exchange rate could not be retrieved"),
+		},
+	}
 }
 
 func (v *v1Exchange) getQueryParams(c *gin.Context) (string, string, error) {
 	from := c.Query("from")
 	to := c.Query("to")
 
-	_, exists := v.validCurrencies[from]
-	if exists == false {
-		return "", "", errors.New(fmt.Sprintf("%s is not a valid currency", from))
+	if err := service.ValidatePair(v.validCurrencies, from, to); err != nil {
+		return "", "", err
 	}
 
-	_, exists = v.validCurrencies[to]
-	if exists == false {
-		return "", "", errors.New(fmt.Sprintf("%s is not a valid currency", to))
-	}
+	return from, to, nil
+}
 
-	if from == to {
-		return "", "", errors.New(fmt.Sprintf("from '%s' and to are the same, they need to be different", from))
+// getDate - The optional `date` query param, zero when it is
+//			 missing
+func (v *v1Exchange) getDate(c *gin.Context) (time.Time, error) {
+	date := c.Query("date")
+	if date == "" {
+		return time.Time{}, nil
 	}
 
-	return from, to, nil
+	return service.ValidateDate(date, v.clock.Now())
 }
 
-func (v *v1Exchange) createBadRequestResponse(c *gin.Context) {
-	c.JSON(400, gin.H{
-		"reason": "query params are invalid. EUR, USD and GBP are valid.",
-	})
+func (v *v1Exchange) createBadRequestResponse(c *gin.Context, err error) {
+	endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, "query params are invalid. EUR, USD and GBP are valid.", err.Error())
+}
+
+func (v *v1Exchange) createBadDateResponse(c *gin.Context, err error) {
+	endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, "query params are invalid. date must be a day such as 2019-10-01 from 1999-01-04 until yesterday.", err.Error())
 }
 
 func (v *v1Exchange) createServerErrorResponse(c *gin.Context, err error) {
-	c.JSON(500, gin.H{
-		"reason": err,
-	})
+	endpoint.CreateErrorResponse(c, 500, api.ErrorCodeInternal, err.Error())
 }
 
-func (v *v1Exchange) createSuccessResponse(c *gin.Context, from, to string, r *service.ExchangeRateServiceResponse) {
-	c.JSON(200, gin.H{
-		"from":           from,
-		"to":             to,
-		"singleUnit":     r.OneUnit,
-		"shouldExchange": r.ShouldExchange,
-		"dataDateTime":   r.DataDateTime,
+func (v *v1Exchange) createSuccessResponse(c *gin.Context, from, to string, r *service.ExchangeRateServiceResponse, cacheControl string) {
+	etag := rateETag(from, to, r.OneUnit, r.ShouldExchange, r.DataDateTime)
+	if writeCacheHeaders(c, etag, r.DataDateTime, cacheControl) {
+		return
+	}
+
+	c.JSON(200, api.ExchangeResponse{
+		From:           from,
+		To:             to,
+		SingleUnit:     r.OneUnit,
+		ShouldExchange: r.ShouldExchange,
+		DataDateTime:   r.DataDateTime.Format(endpoint.DataDateTimeLayout),
 	})
 }
@@ -1,6 +1,7 @@
 package v1endpoint_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -18,60 +19,42 @@ func TestExchangeEndpoint(t *testing.T) {
 	t.Run("ensure 200 response and valid body when successful operations", func(t *testing.T) {
 		// given
 		eService := givenValidExchangeService()
-		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList())
-		server := service.CreateNewServer()
-		server.Register("GET /v1/exchange", endpoint)
-		go server.Start()
-		time.Sleep(time.Millisecond * 500)
+		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList(), util.CreateNewClock())
+		baseURL := givenRunningServer(t, endpoint)
 
 		// when
-		body := performGetRequest(t, "EUR", "GBP", 200)
+		body := performGetRequest(t, baseURL, "EUR", "GBP", 200)
 		data := unmarshalSuccess(t, "EUR", "GBP", body)
 
-		time.Sleep(time.Millisecond * 500)
-
 		// then
-		server.Stop(time.Duration(time.Second))
 		util.AssertTrue(t, data.SingleUnit == 0.8)
 	})
 
 	t.Run("ensure 400 response when bad queries passed", func(t *testing.T) {
 		// given
 		eService := givenValidExchangeService()
-		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList())
-		server := service.CreateNewServer()
-		server.Register("GET /v1/exchange", endpoint)
-		go server.Start()
-		time.Sleep(time.Millisecond * 500)
+		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList(), util.CreateNewClock())
+		baseURL := givenRunningServer(t, endpoint)
 
 		// when
-		body := performGetRequest(t, "FOO", "BAR", 400)
+		body := performGetRequest(t, baseURL, "FOO", "BAR", 400)
 		data := unmarshalFail(t, "FOO", "BAR", body)
 
-		time.Sleep(time.Millisecond * 500)
-
 		// then
-		server.Stop(time.Duration(time.Second))
 		util.AssertTrue(t, data.Reason == "query params are invalid. EUR, USD and GBP are valid.")
 	})
 
 	t.Run("ensure 400 response when from and to are the same", func(t *testing.T) {
 		// given
 		eService := givenValidExchangeService()
-		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList())
-		server := service.CreateNewServer()
-		server.Register("GET /v1/exchange", endpoint)
-		go server.Start()
-		time.Sleep(time.Millisecond * 500)
+		endpoint := v1endpoint.CreateNewV1Exchange(&eService, givenValidCuirrenciesList(), util.CreateNewClock())
+		baseURL := givenRunningServer(t, endpoint)
 
 		// when
-		body := performGetRequest(t, "EUR", "EUR", 400)
+		body := performGetRequest(t, baseURL, "EUR", "EUR", 400)
 		data := unmarshalFail(t, "EUR", "EUR", body)
 
-		time.Sleep(time.Millisecond * 500)
-
 		// then
-		server.Stop(time.Duration(time.Second))
 		util.AssertTrue(t, data.Reason == "query params are invalid. EUR, USD and GBP are valid.")
 	})
 }
@@ -85,6 +68,18 @@ func (m *mockExchangeService) PerformRequest(from, to string) (*service.Exchange
 	return m.resp, m.err
 }
 
+func (m *mockExchangeService) PerformBatchRequest(from string, to []string) []*service.ExchangeRateBatchResponse {
+	resp := make([]*service.ExchangeRateBatchResponse, len(to))
+	for i, t := range to {
+		resp[i] = &service.ExchangeRateBatchResponse{To: t, Response: m.resp, Err: m.err}
+	}
+	return resp
+}
+
+func (m *mockExchangeService) PerformHistoricalRequest(from, to string, date time.Time) (*service.ExchangeRateServiceResponse, error) {
+	return m.resp, m.err
+}
+
 func givenValidCuirrenciesList() map[string]bool {
 	return map[string]bool{"EUR": true, "USD": true, "GBP": true}
 }
@@ -94,7 +89,34 @@ func givenValidExchangeService() mockExchangeService {
 	return mockExchangeService{resp, nil}
 }
 
-func performGetRequest(t *testing.T, from, to string, expectStatus int) []byte {
+// givenRunningServer - Runs a server with the endpoint on an
+//						ephemeral port until the test ends
+func givenRunningServer(t *testing.T, endpoint service.Endpoint) string {
+	t.Helper()
+
+	server := service.CreateNewServer()
+	server.ListenOn("tcp://127.0.0.1:0")
+	server.Register(endpoint)
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- server.Run(ctx) }()
+
+	<-server.Ready()
+	if len(server.Addrs()) == 0 {
+		t.Fatalf("cannot run server: %s", <-done)
+	}
+
+	t.Cleanup(func() {
+		cancel()
+		if err := <-done; err != nil {
+			t.Errorf("server did not stop cleanly: %s", err)
+		}
+	})
+
+	return "http://" + server.Addrs()[0].String()
+}
+
+func performGetRequest(t *testing.T, baseURL, from, to string, expectStatus int) []byte {
 	t.Helper()
 
 	timeout := time.Duration(5 * time.Second)
@@ -102,7 +124,7 @@ func performGetRequest(t *testing.T, from, to string, expectStatus int) []byte {
 		Timeout: timeout,
 	}
 
-	req, err := http.NewRequest("GET", "http://0.0.0.0:8080/v1/exchange", nil)
+	req, err := http.NewRequest("GET", baseURL+"/v1/exchange", nil)
 	q := req.URL.Query()
 	q.Add("from", from)
 	q.Add("to", to)
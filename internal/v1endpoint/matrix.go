@@ -0,0 +1,177 @@
+package v1endpoint
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+type v1RatesMatrix struct {
+	exchangeService service.ExchangeRateService
+	validCurrencies map[string]bool
+}
+
+type ratesMatrix struct {
+	base         string
+	currencies   []string
+	rates        map[string]map[string]float64
+	dataDateTime time.Time
+}
+
+// CreateNewV1RatesMatrix - Create a new endpoint for
+//							`/v1/rates/matrix`
+func CreateNewV1RatesMatrix(exchangeService service.ExchangeRateService, validCurrencies map[string]bool) *v1RatesMatrix {
+	return &v1RatesMatrix{exchangeService: exchangeService, validCurrencies: validCurrencies}
+}
+
+// Routes - `/v1/rates/matrix`
+func (v *v1RatesMatrix) Routes() []service.Route {
+	return []service.Route{{Method: "GET", Version: "v1", Path: "/rates/matrix", Handler: v.getRatesMatrix, Doc: v.documentGetRatesMatrix}}
+}
+
+func (v *v1RatesMatrix) getRatesMatrix(c *gin.Context) {
+	currencies, err := v.getCurrencies(c)
+	if err != nil {
+		v.createBadRequestResponse(c, err)
+		return
+	}
+
+	m, err := v.buildMatrix(currencies)
+	if err != nil {
+		v.createServerErrorResponse(c, err)
+		return
+	}
+
+	if c.Query("format") == "csv" || c.GetHeader("Accept") == "text/csv" {
+		v.createCSVResponse(c, m)
+		return
+	}
+
+	v.createSuccessResponse(c, m)
+}
+
+// documentGetRatesMatrix - Describe `/v1/rates/matrix` in the OpenAPI document
+func (v *v1RatesMatrix) documentGetRatesMatrix(d *openapi.Document) *openapi.Operation {
+	content := d.JSONContent(api.RatesMatrixResponse{})
+	content["text/csv"] = &openapi.MediaType{Schema: &openapi.Schema{Type: "string"}}
+
+	return &openapi.Operation{
+		Summary:     "Get the cross rates between currencies",
+		OperationID: "getRatesMatrix",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("currencies", "Comma separated currencies, the first one is the base. Defaults to all valid currencies", false),
+			openapi.QueryParameter("format", "Return the matrix as CSV", false, "csv"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Cross rates", Content: content},
+			"400": endpoint.DocumentErrorResponse(d, "Query params are invalid"),
+			"500": endpoint.DocumentErrorResponse(d, "Exchange rates could not be retrieved"),
+		},
+	}
+}
+
+// getCurrencies - Reads the comma separated `currencies` query
+//				   param. All valid currencies are used if it
+//				   is missing.
+func (v *v1RatesMatrix) getCurrencies(c *gin.Context) ([]string, error) {
+	query := c.Query("currencies")
+	if query == "" {
+		return endpoint.Currencies(v.validCurrencies), nil
+	}
+
+	requested := strings.Split(query, ",")
+	seen := make(map[string]bool)
+	for _, currency := range requested {
+		if err := service.ValidateCurrency(v.validCurrencies, currency); err != nil {
+			return nil, err
+		}
+		if seen[currency] {
+			return nil, errors.New(fmt.Sprintf("%s is requested more than once", currency))
+		}
+		seen[currency] = true
+	}
+
+	if len(requested) < 2 {
+		return nil, errors.New("at least two currencies are required")
+	}
+
+	return requested, nil
+}
+
+// buildMatrix - Derives every cross rate from a single table of
+//				 rates against the first currency, so that the
+//				 rates in the matrix are consistent with each
+//				 other.
+func (v *v1RatesMatrix) buildMatrix(currencies []string) (*ratesMatrix, error) {
+	base := currencies[0]
+	baseRates := map[string]float64{base: 1}
+
+	var dataDateTime time.Time
+	for _, r := range v.exchangeService.PerformBatchRequest(base, currencies[1:]) {
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		if r.Response.OneUnit == 0 {
+			return nil, errors.New(fmt.Sprintf("Rate from '%s' to '%s' is zero", base, r.To))
+		}
+		baseRates[r.To] = endpoint.ToFloat64(r.Response.OneUnit)
+		if dataDateTime.IsZero() || r.Response.DataDateTime.Before(dataDateTime) {
+			dataDateTime = r.Response.DataDateTime
+		}
+	}
+
+	rates := make(map[string]map[string]float64)
+	for _, from := range currencies {
+		rates[from] = make(map[string]float64)
+		for _, to := range currencies {
+			rates[from][to] = baseRates[to] / baseRates[from]
+		}
+	}
+
+	return &ratesMatrix{base: base, currencies: currencies, rates: rates, dataDateTime: dataDateTime}, nil
+}
+
+func (v *v1RatesMatrix) createBadRequestResponse(c *gin.Context, err error) {
+	endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, fmt.Sprintf("query params are invalid. %s.", err.Error()), err.Error())
+}
+
+func (v *v1RatesMatrix) createServerErrorResponse(c *gin.Context, err error) {
+	endpoint.CreateErrorResponse(c, 500, api.ErrorCodeInternal, err.Error())
+}
+
+func (v *v1RatesMatrix) createSuccessResponse(c *gin.Context, m *ratesMatrix) {
+	c.JSON(200, api.RatesMatrixResponse{
+		Base:         m.base,
+		Currencies:   m.currencies,
+		Rates:        m.rates,
+		DataDateTime: m.dataDateTime.Format(endpoint.DataDateTimeLayout),
+	})
+}
+
+func (v *v1RatesMatrix) createCSVResponse(c *gin.Context, m *ratesMatrix) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	w.Write(append([]string{""}, m.currencies...))
+	for _, from := range m.currencies {
+		row := []string{from}
+		for _, to := range m.currencies {
+			row = append(row, strconv.FormatFloat(m.rates[from][to], 'f', -1, 64))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+
+	c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+}
+
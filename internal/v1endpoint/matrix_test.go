@@ -0,0 +1,122 @@
+package v1endpoint_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+)
+
+func TestRatesMatrixEndpoint(t *testing.T) {
+	t.Run("ensure 200 response and consistent cross rates", func(t *testing.T) {
+		// given
+		eService := givenEURBaseRatesExchangeService()
+		endpoint := v1endpoint.CreateNewV1RatesMatrix(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performMatrixRequest(t, baseURL, "currencies=EUR,USD,GBP", 200)
+		data := api.RatesMatrixResponse{}
+		err := json.Unmarshal(body, &data)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, data.Base == "EUR")
+		util.AssertEquals(t, 1, float32(data.Rates["USD"]["USD"]))
+		util.AssertEquals(t, 1.1, float32(data.Rates["EUR"]["USD"]))
+		cross := data.Rates["EUR"]["USD"] * data.Rates["USD"]["GBP"]
+		util.AssertTrue(t, math.Abs(cross-data.Rates["EUR"]["GBP"]) < 1e-9)
+		util.AssertTrue(t, math.Abs(data.Rates["GBP"]["USD"]*data.Rates["USD"]["GBP"]-1) < 1e-9)
+	})
+
+	t.Run("ensure matrix can be returned as CSV", func(t *testing.T) {
+		// given
+		eService := givenEURBaseRatesExchangeService()
+		endpoint := v1endpoint.CreateNewV1RatesMatrix(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performMatrixRequest(t, baseURL, "currencies=EUR,GBP&format=csv", 200)
+		lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+
+		// then
+		util.AssertTrue(t, len(lines) == 3)
+		util.AssertTrue(t, lines[0] == ",EUR,GBP")
+		util.AssertTrue(t, lines[1] == "EUR,1,0.8")
+	})
+
+	t.Run("ensure 400 response when an invalid currency is passed", func(t *testing.T) {
+		// given
+		eService := givenEURBaseRatesExchangeService()
+		endpoint := v1endpoint.CreateNewV1RatesMatrix(&eService, givenValidCuirrenciesList())
+		baseURL := givenRunningServer(t, endpoint)
+
+		// when
+		body := performMatrixRequest(t, baseURL, "currencies=EUR,FOO", 400)
+		data := unmarshalFail(t, "EUR", "FOO", body)
+
+		// then
+		util.AssertTrue(t, data.Reason == "query params are invalid. FOO is not a valid currency.")
+	})
+}
+
+type mockRatesExchangeService struct {
+	rates map[string]float32
+}
+
+func (m *mockRatesExchangeService) PerformRequest(from, to string) (*service.ExchangeRateServiceResponse, error) {
+	return &service.ExchangeRateServiceResponse{OneUnit: m.rates[to], DataDateTime: time.Now()}, nil
+}
+
+func (m *mockRatesExchangeService) PerformBatchRequest(from string, to []string) []*service.ExchangeRateBatchResponse {
+	resp := make([]*service.ExchangeRateBatchResponse, len(to))
+	for i, t := range to {
+		r, _ := m.PerformRequest(from, t)
+		resp[i] = &service.ExchangeRateBatchResponse{To: t, Response: r}
+	}
+	return resp
+}
+
+func (m *mockRatesExchangeService) PerformHistoricalRequest(from, to string, date time.Time) (*service.ExchangeRateServiceResponse, error) {
+	return m.PerformRequest(from, to)
+}
+
+func givenEURBaseRatesExchangeService() mockRatesExchangeService {
+	return mockRatesExchangeService{map[string]float32{"USD": 1.1, "GBP": 0.8}}
+}
+
+func performMatrixRequest(t *testing.T, baseURL, query string, expectStatus int) []byte {
+	t.Helper()
+
+	timeout := time.Duration(5 * time.Second)
+	client := &http.Client{
+		Timeout: timeout,
+	}
+
+	resp, err := client.Get(baseURL + "/v1/rates/matrix?" + query)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot get rates matrix for '%s'", query))
+	}
+
+	if resp.StatusCode != expectStatus {
+		t.Fatal(fmt.Sprintf("Received %d when getting rates matrix for '%s'", resp.StatusCode, query))
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(fmt.Sprintf("Cannot read body for rates matrix request for '%s'", query))
+	}
+
+	return body
+}
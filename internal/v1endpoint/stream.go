@@ -0,0 +1,168 @@
+package v1endpoint
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+// MaxStreamPairs - The most pairs that can be subscribed to on
+//					a single connection to `/v1/stream`
+const MaxStreamPairs = 20
+
+// streamBuffer - Updates held for a slow connection before the
+//				  oldest are dropped
+const streamBuffer = 16
+
+type v1Stream struct {
+	exchangeService service.ExchangeRateService
+	broker          *pubsub.Broker
+	validCurrencies map[string]bool
+	heartbeat       time.Duration
+	maxConnections  int64
+	connections     int64
+}
+
+// CreateNewV1Stream - Create a new endpoint for `/v1/stream`.
+//					   A heartbeat is sent every heartbeat and
+//					   at most maxConnections can be open at
+//					   the same time.
+func CreateNewV1Stream(exchangeService service.ExchangeRateService, broker *pubsub.Broker, validCurrencies map[string]bool, heartbeat time.Duration, maxConnections int) *v1Stream {
+	return &v1Stream{exchangeService: exchangeService,
+		broker:          broker,
+		validCurrencies: validCurrencies,
+		heartbeat:       heartbeat,
+		maxConnections:  int64(maxConnections)}
+}
+
+// Routes - `/v1/stream`
+func (v *v1Stream) Routes() []service.Route {
+	return []service.Route{{Method: "GET", Version: "v1", Path: "/stream", Handler: v.getStream, Doc: v.documentGetStream}}
+}
+
+func (v *v1Stream) getStream(c *gin.Context) {
+	pairs, err := v.getPairs(c)
+	if err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, fmt.Sprintf("query params are invalid. Between 1 and %d pairs such as EUR/USD are required.", MaxStreamPairs), err.Error())
+		return
+	}
+
+	if atomic.AddInt64(&v.connections, 1) > v.maxConnections {
+		atomic.AddInt64(&v.connections, -1)
+		endpoint.CreateErrorResponse(c, 503, api.ErrorCodeUnavailable, "too many open streams, try again later")
+		return
+	}
+	defer atomic.AddInt64(&v.connections, -1)
+
+	v.stream(c, pairs)
+}
+
+// documentGetStream - Describe `/v1/stream` in the OpenAPI document
+func (v *v1Stream) documentGetStream(d *openapi.Document) *openapi.Operation {
+	// The data of every `rate` event
+	d.Schema(api.ExchangeResponse{})
+
+	return &openapi.Operation{
+		Summary:     "Stream server-sent `rate` events whenever a new rate is retrieved for a pair",
+		OperationID: "getStream",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("pairs", "Comma separated pairs such as EUR/USD,GBP/EUR", true),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Event stream, every `rate` event's data is an ExchangeResponse",
+				Content: map[string]*openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}}},
+			"400": endpoint.DocumentErrorResponse(d, "Query params are invalid"),
+			"503": endpoint.DocumentErrorResponse(d, "Too many open streams"),
+		},
+	}
+}
+
+func (v *v1Stream) getPairs(c *gin.Context) ([]api.ExchangePair, error) {
+	query := strings.Split(c.Query("pairs"), ",")
+	if len(query) > MaxStreamPairs {
+		return nil, &service.ValidationError{Reason: fmt.Sprintf("at most %d pairs can be streamed", MaxStreamPairs)}
+	}
+
+	pairs := []api.ExchangePair{}
+	for _, q := range query {
+		currencies := strings.Split(q, "/")
+		if len(currencies) != 2 {
+			return nil, &service.ValidationError{Reason: fmt.Sprintf("'%s' is not a pair such as EUR/USD", q)}
+		}
+
+		p := api.ExchangePair{From: currencies[0], To: currencies[1]}
+		if err := service.ValidatePair(v.validCurrencies, p.From, p.To); err != nil {
+			return nil, err
+		}
+		pairs = append(pairs, p)
+	}
+
+	return pairs, nil
+}
+
+// stream - Sends the current rate of every pair followed by
+//			every newly stored rate until the client goes away
+//			or the server shuts down
+func (v *v1Stream) stream(c *gin.Context, pairs []api.ExchangePair) {
+	keys := []string{}
+	for _, p := range pairs {
+		keys = append(keys, p.From+p.To)
+	}
+	sub := v.broker.Subscribe(keys, streamBuffer)
+	defer v.broker.Unsubscribe(sub)
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Status(200)
+
+	for _, p := range pairs {
+		resp, err := v.exchangeService.PerformRequest(p.From, p.To)
+		if err != nil {
+			continue
+		}
+		v.writeRate(c, p.From, p.To, resp.OneUnit, resp.ShouldExchange, resp.DataDateTime)
+	}
+	c.Writer.Flush()
+
+	heartbeat := time.NewTicker(v.heartbeat)
+	defer heartbeat.Stop()
+	shutdown := service.ShuttingDown(c.Request.Context())
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-shutdown:
+			return
+		case u, open := <-sub.C:
+			if !open {
+				return
+			}
+			v.writeRate(c, u.From, u.To, u.OneUnit, u.ShouldExchange, u.DataDateTime)
+		case <-heartbeat.C:
+			fmt.Fprint(c.Writer, ": heartbeat\n\n")
+		}
+		c.Writer.Flush()
+	}
+}
+
+func (v *v1Stream) writeRate(c *gin.Context, from, to string, oneUnit float32, shouldExchange bool, dataDateTime time.Time) {
+	data, _ := json.Marshal(api.ExchangeResponse{
+		From:           from,
+		To:             to,
+		SingleUnit:     oneUnit,
+		ShouldExchange: shouldExchange,
+		DataDateTime:   dataDateTime.Format(endpoint.DataDateTimeLayout),
+	})
+	fmt.Fprintf(c.Writer, "event: rate\ndata: %s\n\n", data)
+}
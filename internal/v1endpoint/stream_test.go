@@ -0,0 +1,177 @@
+package v1endpoint_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/pubsub"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestStreamEndpoint(t *testing.T) {
+	t.Run("ensure current rate and stored rates are streamed", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		server := givenStreamServer(broker, time.Hour, 10)
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// when
+		events := openStream(t, ctx, server.URL+"/v1/stream?pairs=EUR/GBP", 200)
+		first := <-events
+		waitForSubscribers(t, broker, 1)
+		broker.Publish(pubsub.RateUpdate{From: "EUR", To: "USD", OneUnit: 1.1})
+		broker.Publish(pubsub.RateUpdate{From: "EUR", To: "GBP", OneUnit: 0.9})
+		second := <-events
+
+		// then
+		util.AssertTrue(t, strings.HasPrefix(first, "event: rate\ndata: "))
+		util.AssertEquals(t, 0.8, unmarshalEvent(t, first).SingleUnit)
+		util.AssertEquals(t, 0.9, unmarshalEvent(t, second).SingleUnit)
+		util.AssertTrue(t, unmarshalEvent(t, second).To == "GBP")
+	})
+
+	t.Run("ensure heartbeats are sent", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		server := givenStreamServer(broker, time.Millisecond*50, 10)
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// when
+		events := openStream(t, ctx, server.URL+"/v1/stream?pairs=EUR/GBP", 200)
+		<-events
+		heartbeat := <-events
+
+		// then
+		util.AssertTrue(t, heartbeat == ": heartbeat")
+	})
+
+	t.Run("ensure subscription ends when the client goes away", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		server := givenStreamServer(broker, time.Millisecond*50, 10)
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		events := openStream(t, ctx, server.URL+"/v1/stream?pairs=EUR/GBP", 200)
+		<-events
+		waitForSubscribers(t, broker, 1)
+
+		// when
+		cancel()
+
+		// then
+		waitForSubscribers(t, broker, 0)
+	})
+
+	t.Run("ensure 503 response when too many streams are open", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		server := givenStreamServer(broker, time.Hour, 1)
+		defer server.Close()
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		events := openStream(t, ctx, server.URL+"/v1/stream?pairs=EUR/GBP", 200)
+		<-events
+
+		// when
+		resp, err := http.Get(server.URL + "/v1/stream?pairs=EUR/USD")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.StatusCode == 503)
+	})
+
+	t.Run("ensure 400 response when pairs are invalid", func(t *testing.T) {
+		// given
+		broker := pubsub.CreateNewBroker()
+		server := givenStreamServer(broker, time.Hour, 10)
+		defer server.Close()
+
+		// when
+		respInvalid, errInvalid := http.Get(server.URL + "/v1/stream?pairs=EUR/FOO")
+		respMalformed, errMalformed := http.Get(server.URL + "/v1/stream?pairs=EURGBP")
+
+		// then
+		util.AssertErrorNil(t, errInvalid)
+		util.AssertErrorNil(t, errMalformed)
+		util.AssertTrue(t, respInvalid.StatusCode == 400)
+		util.AssertTrue(t, respMalformed.StatusCode == 400)
+	})
+}
+
+func givenStreamServer(broker *pubsub.Broker, heartbeat time.Duration, maxConnections int) *httptest.Server {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	eService := givenValidExchangeService()
+	service.Mount(router, v1endpoint.CreateNewV1Stream(&eService, broker, givenValidCuirrenciesList(), heartbeat, maxConnections))
+	return httptest.NewServer(router)
+}
+
+// openStream - Opens the stream and sends every event, without
+//				its trailing blank line, on the returned channel
+func openStream(t *testing.T, ctx context.Context, url string, expectStatus int) <-chan string {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", url, nil)
+	resp, err := http.DefaultClient.Do(req.WithContext(ctx))
+	if err != nil {
+		t.Fatalf("Cannot open stream '%s'", url)
+	}
+
+	if resp.StatusCode != expectStatus {
+		t.Fatalf("Received %d when opening stream '%s'", resp.StatusCode, url)
+	}
+
+	events := make(chan string, 10)
+	go func() {
+		defer resp.Body.Close()
+		scanner := bufio.NewScanner(resp.Body)
+		event := []string{}
+		for scanner.Scan() {
+			if scanner.Text() != "" {
+				event = append(event, scanner.Text())
+				continue
+			}
+			events <- strings.Join(event, "\n")
+			event = []string{}
+		}
+	}()
+
+	return events
+}
+
+func unmarshalEvent(t *testing.T, event string) *api.ExchangeResponse {
+	t.Helper()
+
+	data := api.ExchangeResponse{}
+	if err := json.Unmarshal([]byte(strings.SplitN(event, "data: ", 2)[1]), &data); err != nil {
+		t.Fatalf("Cannot unmarshall event '%s'", event)
+	}
+
+	return &data
+}
+
+func waitForSubscribers(t *testing.T, broker *pubsub.Broker, expected int) {
+	t.Helper()
+
+	for i := 0; i < 100; i++ {
+		if broker.Subscribers() == expected {
+			return
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	t.Fatalf("expected %d subscribers but actual is %d", expected, broker.Subscribers())
+}
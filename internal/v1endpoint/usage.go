@@ -0,0 +1,50 @@
+package v1endpoint
+
+import (
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+type v1Usage struct {
+	reporter auth.UsageReporter
+}
+
+// CreateNewV1Usage - Create a new endpoint for `/v1/admin/usage`
+func CreateNewV1Usage(reporter auth.UsageReporter) *v1Usage {
+	return &v1Usage{reporter: reporter}
+}
+
+// Routes - `/v1/admin/usage`
+func (v *v1Usage) Routes() []service.Route {
+	return []service.Route{{Method: "GET", Version: "v1", Path: "/admin/usage", Handler: v.getUsage, Doc: v.documentGetUsage}}
+}
+
+func (v *v1Usage) getUsage(c *gin.Context) {
+	keys := []api.KeyUsageResponse{}
+	for _, u := range v.reporter.Usage() {
+		keys = append(keys, api.KeyUsageResponse{KeyID: u.Key.ID,
+			Name:         u.Key.Name,
+			Month:        u.Month,
+			Requests:     u.Requests,
+			RateLimited:  u.RateLimited,
+			MonthlyQuota: u.Key.MonthlyQuota})
+	}
+	c.JSON(200, api.UsageResponse{Keys: keys})
+}
+
+// documentGetUsage - Describe `/v1/admin/usage` in the OpenAPI document
+func (v *v1Usage) documentGetUsage(d *openapi.Document) *openapi.Operation {
+	return &openapi.Operation{
+		Summary:     "Get the requests made with every API key this month",
+		OperationID: "getUsage",
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "Usage per API key", Content: d.JSONContent(api.UsageResponse{})},
+			"401": endpoint.DocumentErrorResponse(d, "API key is missing or unknown"),
+			"403": endpoint.DocumentErrorResponse(d, "API key does not have the admin scope"),
+		},
+	}
+}
@@ -0,0 +1,51 @@
+package v1endpoint_test
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/auth"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+)
+
+func TestV1Usage(t *testing.T) {
+	t.Run("ensure usage of every key is returned", func(t *testing.T) {
+		// given
+		gin.SetMode(gin.ReleaseMode)
+		router := gin.New()
+		service.Mount(router, v1endpoint.CreateNewV1Usage(givenUsageReporter()))
+		rec := httptest.NewRecorder()
+
+		// when
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/v1/admin/usage", nil))
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		body := api.UsageResponse{}
+		util.AssertErrorNil(t, json.Unmarshal(rec.Body.Bytes(), &body))
+		util.AssertTrue(t, len(body.Keys) == 2)
+		util.AssertTrue(t, body.Keys[1].KeyID == "reader")
+		util.AssertTrue(t, body.Keys[1].Requests == 42)
+		util.AssertTrue(t, body.Keys[1].MonthlyQuota == 1000)
+	})
+}
+
+type mockUsageReporter struct {
+	usage []*auth.Usage
+}
+
+func (m *mockUsageReporter) Usage() []*auth.Usage {
+	return m.usage
+}
+
+func givenUsageReporter() *mockUsageReporter {
+	return &mockUsageReporter{[]*auth.Usage{
+		{Key: &auth.Key{ID: "admin", Name: "Admin"}, Month: "2019-10"},
+		{Key: &auth.Key{ID: "reader", Name: "Reader", MonthlyQuota: 1000}, Month: "2019-10", Requests: 42, RateLimited: 3},
+	}}
+}
@@ -0,0 +1,262 @@
+package v2endpoint
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/openapi"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
+)
+
+// MaxTargets - The most currencies that can be converted to in
+//				a single call to `/v2/exchange`
+const MaxTargets = 20
+
+type v2Exchange struct {
+	exchangeService service.ExchangeRateService
+	validCurrencies map[string]bool
+	clock           util.Clock
+}
+
+type exchangeQuery struct {
+	from     string
+	to       []string
+	amount   float64
+	date     time.Time
+	strategy string
+}
+
+// CreateNewV2Exchange - Create a new endpoint for
+//						 `/v2/exchange`
+func CreateNewV2Exchange(exchangeService service.ExchangeRateService, validCurrencies map[string]bool, clock util.Clock) *v2Exchange {
+	return &v2Exchange{exchangeService: exchangeService, validCurrencies: validCurrencies, clock: clock}
+}
+
+// Routes - `/v2/exchange`
+func (v *v2Exchange) Routes() []service.Route {
+	return []service.Route{{Method: "GET", Version: "v2", Path: "/exchange", Handler: v.getExchange, Doc: v.documentGetExchange}}
+}
+
+func (v *v2Exchange) getExchange(c *gin.Context) {
+	mediaType, err := v.getMediaType(c)
+	if err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, "query params are invalid. format must be json, csv, xml or msgpack.", err.Error())
+		return
+	}
+	if mediaType == "" {
+		endpoint.CreateErrorResponse(c, 406, api.ErrorCodeNotAcceptable, "none of the accepted media types can be returned. application/json, text/csv, application/xml and application/msgpack are available.")
+		return
+	}
+
+	q, err := v.getQueryParams(c)
+	if err != nil {
+		endpoint.CreateErrorResponse(c, 400, api.ErrorCodeInvalidQuery, fmt.Sprintf("query params are invalid. %s.", err.Error()), err.Error())
+		return
+	}
+
+	resp := api.ExchangeV2Response{From: q.from, Amount: q.amount, Strategy: q.strategy, Quotes: v.quote(q)}
+	if !q.date.IsZero() {
+		resp.Date = q.date.Format(service.DateLayout)
+	}
+
+	v.createSuccessResponse(c, mediaType, resp)
+}
+
+// documentGetExchange - Describe `/v2/exchange` in the OpenAPI
+//						 document
+func (v *v2Exchange) documentGetExchange(d *openapi.Document) *openapi.Operation {
+	schema := d.Schema(api.ExchangeV2Response{})
+	content := map[string]*openapi.MediaType{
+		mediaTypeJSON:    {Schema: schema},
+		mediaTypeXML:     {Schema: schema},
+		mediaTypeMsgPack: {Schema: schema},
+		mediaTypeCSV:     {Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	return &openapi.Operation{
+		Summary:     "Convert an amount from one currency to many, now or on a past date",
+		OperationID: "getExchangeV2",
+		Parameters: []openapi.Parameter{
+			openapi.QueryParameter("from", "Currency to exchange from", true, endpoint.Currencies(v.validCurrencies)...),
+			openapi.QueryParameter("to", fmt.Sprintf("Comma separated currencies to exchange to, at most %d", MaxTargets), true),
+			openapi.QueryParameter("amount", "Amount of from to convert, defaults to 1", false),
+			openapi.QueryParameter("date", "Convert at the rates of this day, such as 2019-10-14, instead of now", false),
+			openapi.QueryParameter("strategy", "How shouldExchange is decided, comparing with a week or a month before, defaults to week", false,
+				api.StrategyWeek, api.StrategyMonth, api.StrategyNone),
+			openapi.QueryParameter("format", "Overrides the Accept header", false, "json", "csv", "xml", "msgpack"),
+		},
+		Responses: map[string]*openapi.Response{
+			"200": {Description: "A quote for every target, in the same order", Content: content},
+			"400": endpoint.DocumentErrorResponse(d, "Query params are invalid"),
+			"406": endpoint.DocumentErrorResponse(d, "None of the accepted media types are available"),
+		},
+	}
+}
+
+// getMediaType - The `format` query param, or else the media
+//				  type negotiated from the Accept header
+func (v *v2Exchange) getMediaType(c *gin.Context) (string, error) {
+	if format := c.Query("format"); format != "" {
+		mediaType, exists := formats[format]
+		if !exists {
+			return "", &service.ValidationError{Reason: fmt.Sprintf("%s is not a valid format", format)}
+		}
+		return mediaType, nil
+	}
+
+	return negotiate(c.GetHeader("Accept"), mediaTypeJSON, mediaTypeCSV, mediaTypeXML, mediaTypeMsgPack), nil
+}
+
+func (v *v2Exchange) getQueryParams(c *gin.Context) (*exchangeQuery, error) {
+	q := &exchangeQuery{from: c.Query("from"), amount: 1, strategy: c.DefaultQuery("strategy", api.StrategyWeek)}
+
+	seen := make(map[string]bool)
+	for _, to := range strings.Split(c.Query("to"), ",") {
+		if err := service.ValidatePair(v.validCurrencies, q.from, to); err != nil {
+			return nil, err
+		}
+		if !seen[to] {
+			seen[to] = true
+			q.to = append(q.to, to)
+		}
+	}
+	if len(q.to) > MaxTargets {
+		return nil, &service.ValidationError{Reason: fmt.Sprintf("at most %d currencies can be exchanged to", MaxTargets)}
+	}
+
+	if amount := c.Query("amount"); amount != "" {
+		a, err := strconv.ParseFloat(amount, 64)
+		if err != nil || a <= 0 || math.IsInf(a, 0) || math.IsNaN(a) {
+			return nil, &service.ValidationError{Reason: fmt.Sprintf("amount '%s' must be a number greater than 0", amount)}
+		}
+		q.amount = a
+	}
+
+	if date := c.Query("date"); date != "" {
+		d, err := service.ValidateDate(date, v.clock.Now())
+		if err != nil {
+			return nil, err
+		}
+		q.date = d
+	}
+
+	switch q.strategy {
+	case api.StrategyWeek, api.StrategyMonth, api.StrategyNone:
+	default:
+		return nil, &service.ValidationError{Reason: fmt.Sprintf("strategy must be %s, %s or %s", api.StrategyWeek, api.StrategyMonth, api.StrategyNone)}
+	}
+
+	return q, nil
+}
+
+// quote - Convert to every target. The latest rates are fetched
+//		   in a single batch, rates on a date one target at a time.
+func (v *v2Exchange) quote(q *exchangeQuery) []api.ExchangeV2Quote {
+	results := []*service.ExchangeRateBatchResponse{}
+	if q.date.IsZero() {
+		results = v.exchangeService.PerformBatchRequest(q.from, q.to)
+	} else {
+		for _, to := range q.to {
+			resp, err := v.exchangeService.PerformHistoricalRequest(q.from, to, q.date)
+			results = append(results, &service.ExchangeRateBatchResponse{To: to, Response: resp, Err: err})
+		}
+	}
+
+	quotes := make([]api.ExchangeV2Quote, len(results))
+	for i, r := range results {
+		if r.Err != nil {
+			quotes[i] = api.ExchangeV2Quote{To: r.To, Code: api.ErrorCodeInternal, Reason: r.Err.Error()}
+			continue
+		}
+
+		shouldExchange, err := v.shouldExchange(q, r.To, r.Response)
+		if err != nil {
+			quotes[i] = api.ExchangeV2Quote{To: r.To, Code: api.ErrorCodeInternal, Reason: err.Error()}
+			continue
+		}
+
+		quotes[i] = api.ExchangeV2Quote{To: r.To,
+			SingleUnit:     r.Response.OneUnit,
+			Converted:      math.Round(q.amount*endpoint.ToFloat64(r.Response.OneUnit)*1e6) / 1e6,
+			ShouldExchange: shouldExchange,
+			DataDateTime:   r.Response.DataDateTime.Format(endpoint.DataDateTimeLayout)}
+	}
+	return quotes
+}
+
+// shouldExchange - Decide with the strategy. The service already
+//					compares with a week before, a month before is
+//					fetched as a historical rate, or the first
+//					rates when there were none a month before.
+func (v *v2Exchange) shouldExchange(q *exchangeQuery, to string, r *service.ExchangeRateServiceResponse) (*bool, error) {
+	switch q.strategy {
+	case api.StrategyNone:
+		return nil, nil
+	case api.StrategyMonth:
+		day := q.date
+		if day.IsZero() {
+			day = v.clock.Now()
+		}
+		compareWith := day.AddDate(0, -1, 0)
+		if compareWith.Before(service.EarliestDate) {
+			// Nothing was published before the first day
+			compareWith = service.EarliestDate
+		}
+		monthBefore, err := v.exchangeService.PerformHistoricalRequest(q.from, to, compareWith)
+		if err != nil {
+			return nil, err
+		}
+		shouldExchange := monthBefore.OneUnit < r.OneUnit
+		return &shouldExchange, nil
+	}
+
+	shouldExchange := r.ShouldExchange
+	return &shouldExchange, nil
+}
+
+func (v *v2Exchange) createSuccessResponse(c *gin.Context, mediaType string, resp api.ExchangeV2Response) {
+	c.Header("Vary", "Accept")
+
+	switch mediaType {
+	case mediaTypeCSV:
+		v.createCSVResponse(c, resp)
+	case mediaTypeXML:
+		c.XML(200, resp)
+	case mediaTypeMsgPack:
+		c.Render(200, render.MsgPack{Data: resp})
+	default:
+		c.JSON(200, resp)
+	}
+}
+
+func (v *v2Exchange) createCSVResponse(c *gin.Context, resp api.ExchangeV2Response) {
+	buf := &bytes.Buffer{}
+	w := csv.NewWriter(buf)
+
+	w.Write([]string{"from", "to", "amount", "singleUnit", "converted", "shouldExchange", "dataDateTime", "code", "reason"})
+	for _, q := range resp.Quotes {
+		row := []string{resp.From, q.To, strconv.FormatFloat(resp.Amount, 'f', -1, 64), "", "", "", q.DataDateTime, q.Code, q.Reason}
+		if q.Code == "" {
+			row[3] = strconv.FormatFloat(float64(q.SingleUnit), 'f', -1, 32)
+			row[4] = strconv.FormatFloat(q.Converted, 'f', -1, 64)
+		}
+		if q.ShouldExchange != nil {
+			row[5] = strconv.FormatBool(*q.ShouldExchange)
+		}
+		w.Write(row)
+	}
+	w.Flush()
+
+	c.Data(200, "text/csv; charset=utf-8", buf.Bytes())
+}
+
@@ -0,0 +1,384 @@
+package v2endpoint_test
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v2endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+	"github.com/ugorji/go/codec"
+)
+
+func TestExchangeEndpoint(t *testing.T) {
+	t.Run("ensure every target is converted with the amount", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP,USD&amount=250", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, rec.Header().Get("Vary") == "Accept")
+		util.AssertTrue(t, data.From == "EUR")
+		util.AssertTrue(t, data.Amount == 250)
+		util.AssertTrue(t, data.Strategy == api.StrategyWeek)
+		util.AssertTrue(t, len(data.Quotes) == 2)
+		util.AssertTrue(t, data.Quotes[0].To == "GBP")
+		util.AssertTrue(t, data.Quotes[0].Converted == 200)
+		util.AssertTrue(t, *data.Quotes[0].ShouldExchange)
+		util.AssertTrue(t, data.Quotes[1].To == "USD")
+	})
+
+	t.Run("ensure amount defaults to 1 and duplicate targets are quoted once", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP,GBP", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Amount == 1)
+		util.AssertTrue(t, len(data.Quotes) == 1)
+		util.AssertTrue(t, data.Quotes[0].Converted == 0.8)
+	})
+
+	t.Run("ensure the rates on the date are used", func(t *testing.T) {
+		// given
+		eService := givenExchangeService()
+		router := givenRouter(eService)
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP&date=2019-10-01", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Date == "2019-10-01")
+		util.AssertTrue(t, data.Quotes[0].SingleUnit == 0.9)
+		util.AssertTrue(t, eService.dates[0].Equal(time.Date(2019, 10, 1, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("ensure the month strategy compares with a month before", func(t *testing.T) {
+		// given
+		eService := givenExchangeService()
+		router := givenRouter(eService)
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP&strategy=month", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Strategy == api.StrategyMonth)
+		util.AssertFalse(t, *data.Quotes[0].ShouldExchange)
+		util.AssertTrue(t, eService.dates[0].Equal(givenNow().AddDate(0, -1, 0)))
+	})
+
+	t.Run("ensure the month strategy compares with the first rates in the first month", func(t *testing.T) {
+		// given
+		eService := givenExchangeService()
+		router := givenRouter(eService)
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP&date=1999-01-20&strategy=month", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Quotes[0].Code == "")
+		util.AssertTrue(t, data.Quotes[0].ShouldExchange != nil)
+		util.AssertTrue(t, eService.dates[1].Equal(service.EarliestDate))
+	})
+
+	t.Run("ensure the none strategy leaves out shouldExchange", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP&strategy=none", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Quotes[0].ShouldExchange == nil)
+	})
+
+	t.Run("ensure a failed target does not fail the others", func(t *testing.T) {
+		// given
+		eService := givenExchangeService()
+		eService.failing = "USD"
+		router := givenRouter(eService)
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP,USD", "")
+		data := unmarshalJSON(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 200)
+		util.AssertTrue(t, data.Quotes[0].Code == "")
+		util.AssertTrue(t, data.Quotes[1].Code == api.ErrorCodeInternal)
+		util.AssertTrue(t, data.Quotes[1].Reason == "Network service down")
+	})
+
+	t.Run("ensure 400 response when bad queries passed", func(t *testing.T) {
+		for _, query := range []string{
+			"from=EUR&to=FOO",
+			"from=EUR&to=EUR",
+			"from=EUR&to=GBP&amount=0",
+			"from=EUR&to=GBP&amount=-1",
+			"from=EUR&to=GBP&amount=Inf",
+			"from=EUR&to=GBP&amount=ten",
+			"from=EUR&to=GBP&date=14-10-2019",
+			"from=EUR&to=GBP&date=1999-01-01",
+			"from=EUR&to=GBP&date=2019-10-15",
+			"from=EUR&to=GBP&strategy=year",
+			"from=EUR&to=GBP&format=yaml",
+		} {
+			// given
+			router := givenRouter(givenExchangeService())
+
+			// when
+			rec := performRequest(router, "/v2/exchange?"+query, "")
+			data := unmarshalError(t, rec)
+
+			// then
+			if rec.Code != 400 || data.Code != api.ErrorCodeInvalidQuery {
+				t.Fatalf("expected 400 %s for '%s' but actual is %d %s", api.ErrorCodeInvalidQuery, query, rec.Code, data.Code)
+			}
+		}
+	})
+
+	t.Run("ensure 400 response when too many targets", func(t *testing.T) {
+		// given
+		currencies := map[string]bool{"EUR": true}
+		to := ""
+		for i := 0; i <= v2endpoint.MaxTargets; i++ {
+			currency := string([]byte{'A', 'A' + byte(i/26), 'A' + byte(i%26)})
+			currencies[currency] = true
+			if to != "" {
+				to += ","
+			}
+			to += currency
+		}
+		router := gin.New()
+		service.Mount(router, v2endpoint.CreateNewV2Exchange(givenExchangeService(), currencies, util.CreateNewFakeClock(givenNow())))
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to="+to, "")
+
+		// then
+		util.AssertTrue(t, rec.Code == 400)
+	})
+}
+
+func TestExchangeEndpointFormats(t *testing.T) {
+	t.Run("ensure JSON is returned without an Accept header", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP", "")
+
+		// then
+		util.AssertTrue(t, rec.Header().Get("Content-Type") == "application/json; charset=utf-8")
+	})
+
+	t.Run("ensure CSV is returned when accepted", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP,USD&amount=2", "text/csv")
+		rows, err := csv.NewReader(rec.Body).ReadAll()
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, rec.Header().Get("Content-Type") == "text/csv; charset=utf-8")
+		util.AssertTrue(t, len(rows) == 3)
+		util.AssertTrue(t, rows[0][0] == "from" && rows[0][4] == "converted")
+		util.AssertTrue(t, rows[1][1] == "GBP" && rows[1][2] == "2" && rows[1][3] == "0.8" && rows[1][4] == "1.6" && rows[1][5] == "true")
+	})
+
+	t.Run("ensure XML is returned when accepted", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+		data := api.ExchangeV2Response{}
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP", "application/xml")
+		err := xml.Unmarshal(rec.Body.Bytes(), &data)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, rec.Header().Get("Content-Type") == "application/xml; charset=utf-8")
+		util.AssertTrue(t, data.From == "EUR")
+		util.AssertTrue(t, data.Quotes[0].To == "GBP")
+		util.AssertTrue(t, data.Quotes[0].Converted == 0.8)
+	})
+
+	t.Run("ensure MessagePack is returned when accepted", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+		data := api.ExchangeV2Response{}
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP", "application/msgpack")
+		err := codec.NewDecoderBytes(rec.Body.Bytes(), &codec.MsgpackHandle{}).Decode(&data)
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, rec.Header().Get("Content-Type") == "application/msgpack; charset=utf-8")
+		util.AssertTrue(t, data.From == "EUR")
+		util.AssertTrue(t, data.Quotes[0].To == "GBP")
+		util.AssertTrue(t, data.Quotes[0].Converted == 0.8)
+	})
+
+	t.Run("ensure the format query param overrides the Accept header", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP&format=csv", "application/json")
+
+		// then
+		util.AssertTrue(t, rec.Header().Get("Content-Type") == "text/csv; charset=utf-8")
+	})
+
+	t.Run("ensure 406 response when nothing offered is accepted", func(t *testing.T) {
+		// given
+		router := givenRouter(givenExchangeService())
+
+		// when
+		rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP", "text/html")
+		data := unmarshalError(t, rec)
+
+		// then
+		util.AssertTrue(t, rec.Code == 406)
+		util.AssertTrue(t, data.Code == api.ErrorCodeNotAcceptable)
+	})
+}
+
+func TestExchangeEndpointNegotiation(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		status      int
+	}{
+		{"*/*", "application/json", 200},
+		{"TEXT/CSV; charset=utf-8", "text/csv", 200},
+		{"text/*", "text/csv", 200},
+		{"application/xml;q=0.5, application/msgpack", "application/msgpack", 200},
+		{"text/html, application/xml;q=0.9, */*;q=0.1", "application/xml", 200},
+		{"*/*, application/json;q=0", "text/csv", 200},
+		{"application/json;q=0", "application/json", 406},
+		{"application/xm", "application/json", 406},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			// given
+			router := givenRouter(givenExchangeService())
+
+			// when
+			rec := performRequest(router, "/v2/exchange?from=EUR&to=GBP", tt.accept)
+
+			// then
+			contentType := strings.Split(rec.Header().Get("Content-Type"), ";")[0]
+			if rec.Code != tt.status || contentType != tt.contentType {
+				t.Fatalf("expected %d %s but actual is %d %s", tt.status, tt.contentType, rec.Code, contentType)
+			}
+		})
+	}
+}
+
+type mockExchangeService struct {
+	failing string
+	dates   []time.Time
+}
+
+func (m *mockExchangeService) PerformRequest(from, to string) (*service.ExchangeRateServiceResponse, error) {
+	return m.response(to, 0.8)
+}
+
+func (m *mockExchangeService) PerformBatchRequest(from string, to []string) []*service.ExchangeRateBatchResponse {
+	resp := make([]*service.ExchangeRateBatchResponse, len(to))
+	for i, t := range to {
+		r, err := m.response(t, 0.8)
+		resp[i] = &service.ExchangeRateBatchResponse{To: t, Response: r, Err: err}
+	}
+	return resp
+}
+
+func (m *mockExchangeService) PerformHistoricalRequest(from, to string, date time.Time) (*service.ExchangeRateServiceResponse, error) {
+	m.dates = append(m.dates, date)
+	return m.response(to, 0.9)
+}
+
+func (m *mockExchangeService) response(to string, oneUnit float32) (*service.ExchangeRateServiceResponse, error) {
+	if to == m.failing {
+		return nil, errors.New("Network service down")
+	}
+	return &service.ExchangeRateServiceResponse{OneUnit: oneUnit, ShouldExchange: true, DataDateTime: givenNow()}, nil
+}
+
+func givenExchangeService() *mockExchangeService {
+	return &mockExchangeService{}
+}
+
+func givenNow() time.Time {
+	return time.Date(2019, 10, 14, 19, 21, 48, 0, time.UTC)
+}
+
+func givenRouter(eService service.ExchangeRateService) *gin.Engine {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	router.Use(service.RequestID())
+	currencies := map[string]bool{"EUR": true, "USD": true, "GBP": true}
+	if err := service.Mount(router, v2endpoint.CreateNewV2Exchange(eService, currencies, util.CreateNewFakeClock(givenNow()))); err != nil {
+		panic(err)
+	}
+	return router
+}
+
+func performRequest(router *gin.Engine, path, accept string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("GET", path, nil)
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func unmarshalJSON(t *testing.T, rec *httptest.ResponseRecorder) api.ExchangeV2Response {
+	t.Helper()
+
+	data := api.ExchangeV2Response{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("cannot unmarshal '%s': %s", rec.Body.String(), err)
+	}
+	return data
+}
+
+func unmarshalError(t *testing.T, rec *httptest.ResponseRecorder) api.ExchangeErrorResponse {
+	t.Helper()
+
+	data := api.ExchangeErrorResponse{}
+	if err := json.Unmarshal(rec.Body.Bytes(), &data); err != nil {
+		t.Fatalf("cannot unmarshal '%s': %s", rec.Body.String(), err)
+	}
+	return data
+}
@@ -0,0 +1,97 @@
+package v2endpoint
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Media types that /v2 responses can be rendered as
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeCSV     = "text/csv"
+	mediaTypeXML     = "application/xml"
+	mediaTypeMsgPack = "application/msgpack"
+)
+
+// formats - The `format` query param values that override the
+//			 Accept header
+var formats = map[string]string{
+	"json":    mediaTypeJSON,
+	"csv":     mediaTypeCSV,
+	"xml":     mediaTypeXML,
+	"msgpack": mediaTypeMsgPack,
+}
+
+type acceptedType struct {
+	mediaType string
+	q         float64
+}
+
+// negotiate - The offered media type that the Accept header
+//			   prefers, honouring q values and wildcards. The
+//			   first offer is used when there is no Accept
+//			   header, and "" is returned when nothing offered
+//			   is acceptable.
+func negotiate(accept string, offered ...string) string {
+	if strings.TrimSpace(accept) == "" {
+		return offered[0]
+	}
+
+	accepted := parseAccept(accept)
+	for _, a := range accepted {
+		if a.q <= 0 {
+			continue
+		}
+		for _, o := range offered {
+			if mediaTypeMatches(a.mediaType, o) && !rejected(accepted, o) {
+				return o
+			}
+		}
+	}
+	return ""
+}
+
+// parseAccept - Accepted media types, most preferred first
+func parseAccept(accept string) []acceptedType {
+	accepted := []acceptedType{}
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		a := acceptedType{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+		for _, p := range params[1:] {
+			p = strings.TrimSpace(p)
+			if strings.HasPrefix(p, "q=") {
+				if q, err := strconv.ParseFloat(strings.TrimPrefix(p, "q="), 64); err == nil {
+					a.q = q
+				}
+			}
+		}
+		if a.mediaType != "" {
+			accepted = append(accepted, a)
+		}
+	}
+
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].q > accepted[j].q })
+	return accepted
+}
+
+// rejected - Whether the media type is explicitly refused with
+//			  q=0, such as `*/*, text/csv;q=0`
+func rejected(accepted []acceptedType, mediaType string) bool {
+	for _, a := range accepted {
+		if a.q <= 0 && a.mediaType == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+func mediaTypeMatches(accepted, offered string) bool {
+	if accepted == "*/*" || accepted == offered {
+		return true
+	}
+	if strings.HasSuffix(accepted, "/*") {
+		return strings.HasPrefix(offered, strings.TrimSuffix(accepted, "*"))
+	}
+	return false
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DefaultRetryWait - Time waited before the first retry. It is
+//					  doubled for every following retry.
+const DefaultRetryWait = 100 * time.Millisecond
+
+// BadRequestError - Returned by the Client when the server
+//					 rejects the request with a 400
+type BadRequestError struct {
+	Code      string
+	Reason    string
+	Details   []string
+	RequestID string
+}
+
+func (e *BadRequestError) Error() string {
+	return fmt.Sprintf("bad request (%s): %s", e.Code, e.Reason)
+}
+
+// ServerError - Returned by the Client when the server fails
+//				 to serve the request with a 5xx
+type ServerError struct {
+	StatusCode int
+	Code       string
+	Reason     string
+	RequestID  string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("server error %d (%s): %s", e.StatusCode, e.Code, e.Reason)
+}
+
+// Client - Typed client for the exchange server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	retries    int
+	retryWait  time.Duration
+}
+
+// CreateNewClient - Create a new Client for the exchange server
+//					 at baseURL (e.g. http://localhost:8080).
+//					 Requests failing with a network error or a
+//					 5xx are retried up to retries times.
+func CreateNewClient(baseURL string, timeout time.Duration, retries int) *Client {
+	return &Client{baseURL: strings.TrimRight(baseURL, "/"),
+		httpClient: &http.Client{Timeout: timeout},
+		retries:    retries,
+		retryWait:  DefaultRetryWait}
+}
+
+// Exchange - Get the exchange rate from {from} to {to} and
+//			  whether it's a good time to exchange
+func (c *Client) Exchange(ctx context.Context, from, to string) (*ExchangeResponse, error) {
+	q := url.Values{}
+	q.Add("from", from)
+	q.Add("to", to)
+
+	data := ExchangeResponse{}
+	if err := c.get(ctx, "/v1/exchange?"+q.Encode(), &data); err != nil {
+		return nil, err
+	}
+
+	return &data, nil
+}
+
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	wait := c.retryWait
+
+	var err error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(wait):
+			}
+			wait *= 2
+		}
+
+		var retry bool
+		retry, err = c.performGetRequest(ctx, path, v)
+		if !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// performGetRequest - Performs a single request. The returned
+//					   bool is true if the request can be retried.
+func (c *Client) performGetRequest(ctx context.Context, path string, v interface{}) (bool, error) {
+	req, err := http.NewRequest("GET", c.baseURL+path, nil)
+	if err != nil {
+		return false, errors.Wrap(err, fmt.Sprintf("Cannot create request for '%s'", path))
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		return true, errors.Wrap(err, fmt.Sprintf("Cannot perform request for '%s'", path))
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return true, errors.Wrap(err, fmt.Sprintf("Cannot read body for '%s'", path))
+	}
+
+	switch {
+	case resp.StatusCode == 200:
+		if err := json.Unmarshal(body, v); err != nil {
+			return false, errors.Wrap(err, fmt.Sprintf("Cannot unmarshall body for '%s'", path))
+		}
+		return false, nil
+	case resp.StatusCode >= 500:
+		e := c.unmarshallError(body)
+		return true, &ServerError{StatusCode: resp.StatusCode, Code: e.Code, Reason: e.Message, RequestID: e.RequestID}
+	case resp.StatusCode == 400:
+		e := c.unmarshallError(body)
+		return false, &BadRequestError{Code: e.Code, Reason: e.Message, Details: e.Details, RequestID: e.RequestID}
+	default:
+		return false, errors.New(fmt.Sprintf("Received %d for '%s'", resp.StatusCode, path))
+	}
+}
+
+// unmarshallError - Best effort read of an error response body
+func (c *Client) unmarshallError(body []byte) *ExchangeErrorResponse {
+	data := ExchangeErrorResponse{}
+	json.Unmarshal(body, &data)
+	return &data
+}
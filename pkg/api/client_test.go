@@ -0,0 +1,135 @@
+package api_test
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/service"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/util"
+	"github.com/ankur22/ankur-curve-euro-exchange/internal/v1endpoint"
+	"github.com/ankur22/ankur-curve-euro-exchange/pkg/api"
+	"github.com/gin-gonic/gin"
+	"github.com/pkg/errors"
+)
+
+func TestClient(t *testing.T) {
+	t.Run("ensure exchange response is returned when successful", func(t *testing.T) {
+		// given
+		eService := &mockExchangeService{}
+		server := givenExchangeServer(eService)
+		defer server.Close()
+		client := api.CreateNewClient(server.URL, time.Second, 0)
+
+		// when
+		resp, err := client.Exchange(context.Background(), "EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertTrue(t, resp.From == "EUR")
+		util.AssertTrue(t, resp.To == "GBP")
+		util.AssertEquals(t, 0.8, resp.SingleUnit)
+		util.AssertTrue(t, resp.ShouldExchange)
+	})
+
+	t.Run("ensure bad request error is returned for invalid currencies", func(t *testing.T) {
+		// given
+		eService := &mockExchangeService{}
+		server := givenExchangeServer(eService)
+		defer server.Close()
+		client := api.CreateNewClient(server.URL, time.Second, 3)
+
+		// when
+		_, err := client.Exchange(context.Background(), "EUR", "FOO")
+
+		// then
+		badRequest, ok := err.(*api.BadRequestError)
+		util.AssertTrue(t, ok)
+		util.AssertTrue(t, badRequest.Code == api.ErrorCodeInvalidQuery)
+		util.AssertTrue(t, badRequest.Reason == "query params are invalid. EUR, USD and GBP are valid.")
+		util.AssertTrue(t, badRequest.Details[0] == "FOO is not a valid currency")
+		util.AssertFalse(t, badRequest.RequestID == "")
+		util.AssertTrue(t, eService.calls == 0)
+	})
+
+	t.Run("ensure server error is returned once retries are exhausted", func(t *testing.T) {
+		// given
+		eService := &mockExchangeService{failures: 10}
+		server := givenExchangeServer(eService)
+		defer server.Close()
+		client := api.CreateNewClient(server.URL, time.Second, 2)
+
+		// when
+		_, err := client.Exchange(context.Background(), "EUR", "GBP")
+
+		// then
+		serverError, ok := err.(*api.ServerError)
+		util.AssertTrue(t, ok)
+		util.AssertTrue(t, serverError.StatusCode == 500)
+		util.AssertTrue(t, serverError.Reason == "Network service down")
+		util.AssertTrue(t, eService.calls == 3)
+	})
+
+	t.Run("ensure request is retried after a server error", func(t *testing.T) {
+		// given
+		eService := &mockExchangeService{failures: 1}
+		server := givenExchangeServer(eService)
+		defer server.Close()
+		client := api.CreateNewClient(server.URL, time.Second, 2)
+
+		// when
+		resp, err := client.Exchange(context.Background(), "EUR", "GBP")
+
+		// then
+		util.AssertErrorNil(t, err)
+		util.AssertEquals(t, 0.8, resp.SingleUnit)
+		util.AssertTrue(t, eService.calls == 2)
+	})
+
+	t.Run("ensure retries stop when the context is cancelled", func(t *testing.T) {
+		// given
+		eService := &mockExchangeService{failures: 10}
+		server := givenExchangeServer(eService)
+		defer server.Close()
+		client := api.CreateNewClient(server.URL, time.Second, 10)
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+		defer cancel()
+
+		// when
+		_, err := client.Exchange(ctx, "EUR", "GBP")
+
+		// then
+		util.AssertTrue(t, err == context.DeadlineExceeded)
+		util.AssertTrue(t, eService.calls < 10)
+	})
+}
+
+type mockExchangeService struct {
+	failures int
+	calls    int
+}
+
+func (m *mockExchangeService) PerformRequest(from, to string) (*service.ExchangeRateServiceResponse, error) {
+	m.calls++
+	if m.calls <= m.failures {
+		return nil, errors.New("Network service down")
+	}
+	return &service.ExchangeRateServiceResponse{OneUnit: 0.8, ShouldExchange: true, DataDateTime: time.Now()}, nil
+}
+
+func (m *mockExchangeService) PerformBatchRequest(from string, to []string) []*service.ExchangeRateBatchResponse {
+	return nil
+}
+
+func (m *mockExchangeService) PerformHistoricalRequest(from, to string, date time.Time) (*service.ExchangeRateServiceResponse, error) {
+	return m.PerformRequest(from, to)
+}
+
+func givenExchangeServer(eService service.ExchangeRateService) *httptest.Server {
+	gin.SetMode(gin.ReleaseMode)
+	router := gin.New()
+	endpoint := v1endpoint.CreateNewV1Exchange(eService, map[string]bool{"EUR": true, "USD": true, "GBP": true}, util.CreateNewClock())
+	service.Mount(router, endpoint)
+	return httptest.NewServer(router)
+}
@@ -1,15 +1,198 @@
 package api
 
+// Error codes returned in ExchangeErrorResponse.Code
+const (
+	ErrorCodeInvalidQuery  = "invalid_query"
+	ErrorCodeInvalidBody   = "invalid_body"
+	ErrorCodeInvalidPair   = "invalid_pair"
+	ErrorCodeInternal      = "internal_error"
+	ErrorCodeUnavailable   = "unavailable"
+	ErrorCodeNotFound      = "not_found"
+	ErrorCodeUnauthorized  = "unauthorized"
+	ErrorCodeForbidden     = "forbidden"
+	ErrorCodeRateLimited   = "rate_limited"
+	ErrorCodeQuotaExceed   = "quota_exceeded"
+	ErrorCodeNotAcceptable = "not_acceptable"
+)
+
 // ExchangeResponse - Reponse model of /v1/exchange
 type ExchangeResponse struct {
-	From           string
-	To             string
-	SingleUnit     float32
-	ShouldExchange bool
-	DataDateTime   string
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	SingleUnit     float32 `json:"singleUnit"`
+	ShouldExchange bool    `json:"shouldExchange"`
+	DataDateTime   string  `json:"dataDateTime"`
 }
 
-// ExchangeErrorResponse - Error reponse model of /v1/exchange
+// ExchangeErrorResponse - Error reponse model of every endpoint.
+//						   Reason is kept for clients written
+//						   against the original response and is
+//						   always the same as Message.
 type ExchangeErrorResponse struct {
-	Reason string
+	Code      string   `json:"code"`
+	Message   string   `json:"message"`
+	Details   []string `json:"details,omitempty"`
+	RequestID string   `json:"requestId"`
+	Reason    string   `json:"reason"`
+}
+
+// ExchangePair - A single from/to pair of currencies
+type ExchangePair struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// ExchangeBatchRequest - Request model of /v1/exchange/batch
+type ExchangeBatchRequest struct {
+	Pairs []ExchangePair `json:"pairs"`
+}
+
+// ExchangeBatchQuote - A single quote within the response of
+//						/v1/exchange/batch. Code and Reason are
+//						only set when the quote could not be
+//						served, and SingleUnit is then 0.
+type ExchangeBatchQuote struct {
+	From           string  `json:"from"`
+	To             string  `json:"to"`
+	SingleUnit     float32 `json:"singleUnit"`
+	ShouldExchange bool    `json:"shouldExchange"`
+	DataDateTime   string  `json:"dataDateTime,omitempty"`
+	Code           string  `json:"code,omitempty"`
+	Reason         string  `json:"reason,omitempty"`
+}
+
+// ExchangeBatchResponse - Reponse model of /v1/exchange/batch
+type ExchangeBatchResponse struct {
+	Quotes []ExchangeBatchQuote `json:"quotes"`
+}
+
+// RatesMatrixResponse - Reponse model of /v1/rates/matrix. Rates
+//						 holds the rate of one unit of the row
+//						 currency in the column currency.
+type RatesMatrixResponse struct {
+	Base         string                        `json:"base"`
+	Currencies   []string                      `json:"currencies"`
+	Rates        map[string]map[string]float64 `json:"rates"`
+	DataDateTime string                        `json:"dataDateTime"`
+}
+
+// Alert conditions of AlertRequest.Condition
+const (
+	AlertConditionAbove  = "above"
+	AlertConditionBelow  = "below"
+	AlertConditionChange = "change"
+)
+
+// AlertRequest - Request model of POST and PUT /v1/alerts.
+//				  Threshold is a rate for the above and below
+//				  conditions and a percentage for change, which
+//				  compares with the rate a day before.
+type AlertRequest struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Condition  string  `json:"condition"`
+	Threshold  float32 `json:"threshold"`
+	WebhookURL string  `json:"webhookUrl"`
+	Secret     string  `json:"secret"`
+}
+
+// AlertResponse - Reponse model of an alert. The secret is
+//				   never returned.
+type AlertResponse struct {
+	ID         string  `json:"id"`
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Condition  string  `json:"condition"`
+	Threshold  float32 `json:"threshold"`
+	WebhookURL string  `json:"webhookUrl"`
+	CreatedAt  string  `json:"createdAt"`
+}
+
+// AlertListResponse - Reponse model of GET /v1/alerts
+type AlertListResponse struct {
+	Alerts []AlertResponse `json:"alerts"`
+}
+
+// AlertEvent - Body POSTed to the webhook of a triggered alert
+type AlertEvent struct {
+	AlertID      string  `json:"alertId"`
+	From         string  `json:"from"`
+	To           string  `json:"to"`
+	Condition    string  `json:"condition"`
+	Threshold    float32 `json:"threshold"`
+	SingleUnit   float32 `json:"singleUnit"`
+	PreviousUnit float32 `json:"previousUnit"`
+	DataDateTime string  `json:"dataDateTime"`
+}
+
+// DeliveryResponse - A single attempt to deliver an AlertEvent
+type DeliveryResponse struct {
+	ID          string `json:"id"`
+	AlertID     string `json:"alertId"`
+	Attempt     int    `json:"attempt"`
+	StatusCode  int    `json:"statusCode,omitempty"`
+	Error       string `json:"error,omitempty"`
+	Success     bool   `json:"success"`
+	AttemptedAt string `json:"attemptedAt"`
+}
+
+// DeliveryListResponse - Reponse model of
+//						  GET /v1/alerts/{id}/deliveries
+type DeliveryListResponse struct {
+	Deliveries []DeliveryResponse `json:"deliveries"`
+}
+
+// KeyUsageResponse - Usage of a single API key in a month
+type KeyUsageResponse struct {
+	KeyID        string `json:"keyId"`
+	Name         string `json:"name"`
+	Month        string `json:"month"`
+	Requests     int64  `json:"requests"`
+	RateLimited  int64  `json:"rateLimited"`
+	MonthlyQuota int64  `json:"monthlyQuota"`
+}
+
+// UsageResponse - Reponse model of /v1/admin/usage
+type UsageResponse struct {
+	Keys []KeyUsageResponse `json:"keys"`
+}
+
+// ChaosFault - A fault injected into calls of the provider.
+//				Duration is such as 250ms and Start and End are
+//				RFC 3339 times.
+type ChaosFault struct {
+	Kind        string  `json:"kind"`
+	Call        string  `json:"call,omitempty"`
+	Probability float64 `json:"probability,omitempty"`
+	Every       int     `json:"every,omitempty"`
+	Start       string  `json:"start,omitempty"`
+	End         string  `json:"end,omitempty"`
+	Duration    string  `json:"duration,omitempty"`
+	Status      int     `json:"status,omitempty"`
+}
+
+// ChaosRequest - Request model of PUT /v1/admin/chaos
+type ChaosRequest struct {
+	Faults []ChaosFault `json:"faults"`
+}
+
+// ChaosResponse - Reponse model of /v1/admin/chaos. Injected
+//				   is the number of faults injected of each kind.
+type ChaosResponse struct {
+	Faults   []ChaosFault   `json:"faults"`
+	Injected map[string]int `json:"injected"`
+}
+
+// RouteResponse - A route served by the server
+type RouteResponse struct {
+	Method      string `json:"method"`
+	Path        string `json:"path"`
+	Version     string `json:"version,omitempty"`
+	OperationID string `json:"operationId,omitempty"`
+	Summary     string `json:"summary,omitempty"`
+}
+
+// RouteListResponse - Reponse model of /routes
+type RouteListResponse struct {
+	Routes []RouteResponse `json:"routes"`
 }
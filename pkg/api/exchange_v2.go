@@ -0,0 +1,33 @@
+package api
+
+import "encoding/xml"
+
+// Strategies that decide shouldExchange in /v2/exchange
+const (
+	StrategyWeek  = "week"
+	StrategyMonth = "month"
+	StrategyNone  = "none"
+)
+
+// ExchangeV2Quote - The conversion to one target currency. Code
+//					 and Reason are set when this target could not
+//					 be quoted, the other targets are unaffected.
+type ExchangeV2Quote struct {
+	To             string  `json:"to" xml:"to"`
+	SingleUnit     float32 `json:"singleUnit,omitempty" xml:"singleUnit,omitempty"`
+	Converted      float64 `json:"converted,omitempty" xml:"converted,omitempty"`
+	ShouldExchange *bool   `json:"shouldExchange,omitempty" xml:"shouldExchange,omitempty"`
+	DataDateTime   string  `json:"dataDateTime,omitempty" xml:"dataDateTime,omitempty"`
+	Code           string  `json:"code,omitempty" xml:"code,omitempty"`
+	Reason         string  `json:"reason,omitempty" xml:"reason,omitempty"`
+}
+
+// ExchangeV2Response - Response model of /v2/exchange
+type ExchangeV2Response struct {
+	XMLName  xml.Name          `json:"-" xml:"exchange"`
+	From     string            `json:"from" xml:"from"`
+	Amount   float64           `json:"amount" xml:"amount"`
+	Date     string            `json:"date,omitempty" xml:"date,omitempty"`
+	Strategy string            `json:"strategy" xml:"strategy"`
+	Quotes   []ExchangeV2Quote `json:"quotes" xml:"quotes>quote"`
+}
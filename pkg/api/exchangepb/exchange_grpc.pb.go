@@ -0,0 +1,265 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: exchange.proto
+
+package exchangepb
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ExchangeService_GetRate_FullMethodName     = "/exchange.v1.ExchangeService/GetRate"
+	ExchangeService_Convert_FullMethodName     = "/exchange.v1.ExchangeService/Convert"
+	ExchangeService_GetHistory_FullMethodName  = "/exchange.v1.ExchangeService/GetHistory"
+	ExchangeService_StreamRates_FullMethodName = "/exchange.v1.ExchangeService/StreamRates"
+)
+
+// ExchangeServiceClient is the client API for ExchangeService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ExchangeService - gRPC equivalent of the /v1 HTTP endpoints.
+// Invalid currencies are returned as INVALID_ARGUMENT and any
+// other failure as INTERNAL. Both carry a google.rpc.ErrorInfo
+// detail whose reason is the same code as the HTTP error
+// envelope (e.g. invalid_query, internal_error).
+type ExchangeServiceClient interface {
+	// GetRate - Get the exchange rate between two currencies and
+	// whether it's a good time to exchange.
+	GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*Rate, error)
+	// Convert - Convert an amount from one currency to another.
+	Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error)
+	// GetHistory - Get the exchange rate for each of the previous
+	// days, most recent first.
+	GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error)
+	// StreamRates - Stream the rate of every pair whenever a new
+	// rate is retrieved. The current rates are sent first.
+	StreamRates(ctx context.Context, in *StreamRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Rate], error)
+}
+
+type exchangeServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewExchangeServiceClient(cc grpc.ClientConnInterface) ExchangeServiceClient {
+	return &exchangeServiceClient{cc}
+}
+
+func (c *exchangeServiceClient) GetRate(ctx context.Context, in *GetRateRequest, opts ...grpc.CallOption) (*Rate, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Rate)
+	err := c.cc.Invoke(ctx, ExchangeService_GetRate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeServiceClient) Convert(ctx context.Context, in *ConvertRequest, opts ...grpc.CallOption) (*ConvertResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ConvertResponse)
+	err := c.cc.Invoke(ctx, ExchangeService_Convert_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeServiceClient) GetHistory(ctx context.Context, in *GetHistoryRequest, opts ...grpc.CallOption) (*GetHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetHistoryResponse)
+	err := c.cc.Invoke(ctx, ExchangeService_GetHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *exchangeServiceClient) StreamRates(ctx context.Context, in *StreamRatesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[Rate], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ExchangeService_ServiceDesc.Streams[0], ExchangeService_StreamRates_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamRatesRequest, Rate]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExchangeService_StreamRatesClient = grpc.ServerStreamingClient[Rate]
+
+// ExchangeServiceServer is the server API for ExchangeService service.
+// All implementations must embed UnimplementedExchangeServiceServer
+// for forward compatibility.
+//
+// ExchangeService - gRPC equivalent of the /v1 HTTP endpoints.
+// Invalid currencies are returned as INVALID_ARGUMENT and any
+// other failure as INTERNAL. Both carry a google.rpc.ErrorInfo
+// detail whose reason is the same code as the HTTP error
+// envelope (e.g. invalid_query, internal_error).
+type ExchangeServiceServer interface {
+	// GetRate - Get the exchange rate between two currencies and
+	// whether it's a good time to exchange.
+	GetRate(context.Context, *GetRateRequest) (*Rate, error)
+	// Convert - Convert an amount from one currency to another.
+	Convert(context.Context, *ConvertRequest) (*ConvertResponse, error)
+	// GetHistory - Get the exchange rate for each of the previous
+	// days, most recent first.
+	GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error)
+	// StreamRates - Stream the rate of every pair whenever a new
+	// rate is retrieved. The current rates are sent first.
+	StreamRates(*StreamRatesRequest, grpc.ServerStreamingServer[Rate]) error
+	mustEmbedUnimplementedExchangeServiceServer()
+}
+
+// UnimplementedExchangeServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedExchangeServiceServer struct{}
+
+func (UnimplementedExchangeServiceServer) GetRate(context.Context, *GetRateRequest) (*Rate, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRate not implemented")
+}
+func (UnimplementedExchangeServiceServer) Convert(context.Context, *ConvertRequest) (*ConvertResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Convert not implemented")
+}
+func (UnimplementedExchangeServiceServer) GetHistory(context.Context, *GetHistoryRequest) (*GetHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHistory not implemented")
+}
+func (UnimplementedExchangeServiceServer) StreamRates(*StreamRatesRequest, grpc.ServerStreamingServer[Rate]) error {
+	return status.Error(codes.Unimplemented, "method StreamRates not implemented")
+}
+func (UnimplementedExchangeServiceServer) mustEmbedUnimplementedExchangeServiceServer() {}
+func (UnimplementedExchangeServiceServer) testEmbeddedByValue()                         {}
+
+// UnsafeExchangeServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ExchangeServiceServer will
+// result in compilation errors.
+type UnsafeExchangeServiceServer interface {
+	mustEmbedUnimplementedExchangeServiceServer()
+}
+
+func RegisterExchangeServiceServer(s grpc.ServiceRegistrar, srv ExchangeServiceServer) {
+	// If the following call panics, it indicates UnimplementedExchangeServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ExchangeService_ServiceDesc, srv)
+}
+
+func _ExchangeService_GetRate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServiceServer).GetRate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExchangeService_GetRate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServiceServer).GetRate(ctx, req.(*GetRateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExchangeService_Convert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ConvertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServiceServer).Convert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExchangeService_Convert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServiceServer).Convert(ctx, req.(*ConvertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExchangeService_GetHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ExchangeServiceServer).GetHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ExchangeService_GetHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ExchangeServiceServer).GetHistory(ctx, req.(*GetHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ExchangeService_StreamRates_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamRatesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ExchangeServiceServer).StreamRates(m, &grpc.GenericServerStream[StreamRatesRequest, Rate]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ExchangeService_StreamRatesServer = grpc.ServerStreamingServer[Rate]
+
+// ExchangeService_ServiceDesc is the grpc.ServiceDesc for ExchangeService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ExchangeService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "exchange.v1.ExchangeService",
+	HandlerType: (*ExchangeServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetRate",
+			Handler:    _ExchangeService_GetRate_Handler,
+		},
+		{
+			MethodName: "Convert",
+			Handler:    _ExchangeService_Convert_Handler,
+		},
+		{
+			MethodName: "GetHistory",
+			Handler:    _ExchangeService_GetHistory_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamRates",
+			Handler:       _ExchangeService_StreamRates_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "exchange.proto",
+}
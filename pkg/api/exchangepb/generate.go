@@ -0,0 +1,6 @@
+// Package exchangepb - Protobuf messages and gRPC service of
+// the exchange server. Regenerate with:
+//
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative exchange.proto
+package exchangepb